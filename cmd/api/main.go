@@ -6,15 +6,23 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
+	_ "time/tzdata" // IANA time zones for participants, independent of the host
 
 	"github.com/gorilla/mux"
 	"github.com/tusharsingune/meeting-scheduler/internal/api"
+	"github.com/tusharsingune/meeting-scheduler/internal/auth"
+	"github.com/tusharsingune/meeting-scheduler/internal/cache"
 	"github.com/tusharsingune/meeting-scheduler/internal/config"
+	"github.com/tusharsingune/meeting-scheduler/internal/jobs"
 	"github.com/tusharsingune/meeting-scheduler/internal/logger"
 	"github.com/tusharsingune/meeting-scheduler/internal/middleware"
+	"github.com/tusharsingune/meeting-scheduler/internal/migrations"
+	"github.com/tusharsingune/meeting-scheduler/internal/notify"
 	"github.com/tusharsingune/meeting-scheduler/internal/repository"
+	"github.com/tusharsingune/meeting-scheduler/internal/webhook"
 	"go.uber.org/zap"
 )
 
@@ -27,6 +35,12 @@ func main() {
 	}
 	defer log.Sync()
 
+	// "main migrate ..." manages the schema instead of serving, so images
+	// that only ship this binary can run migrations before a rollout
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(log, os.Args[2:]))
+	}
+
 	// Log startup information
 	log.Info("Starting Meeting Scheduler API")
 	log.Info("Environment variables",
@@ -48,7 +62,15 @@ func main() {
 		zap.String("database.host", cfg.Database.Host),
 		zap.String("database.port", cfg.Database.Port),
 		zap.String("database.user", cfg.Database.User),
-		zap.String("database.dbname", cfg.Database.DBName))
+		zap.String("database.dbname", cfg.Database.DBName),
+		zap.String("server.environment", cfg.Server.Environment))
+
+	// Tokens signed with a random key stop working when the server restarts
+	// and are not accepted by its other replicas
+	if cfg.Server.Environment != "development" && len(cfg.Auth.SigningKey) < auth.MinKeyLength {
+		log.Fatal("auth.signingkey must be set to at least 16 bytes unless server.environment is development",
+			zap.String("server.environment", cfg.Server.Environment))
+	}
 
 	// Initialize router
 	router := mux.NewRouter()
@@ -65,41 +87,66 @@ func main() {
 	router.Use(middleware.CORS)
 	router.Use(middleware.Recovery)
 
-	// Initialize database with retry logic
+	// Initialize the repository
 	var db repository.Repository
-	maxRetries := 5
-	retryDelay := 5 * time.Second
-
-	for i := 0; i < maxRetries; i++ {
-		log.Info("Attempting to connect to database",
-			zap.Int("attempt", i+1),
-			zap.Int("max_attempts", maxRetries))
-
-		db, err = repository.NewPostgresDB(cfg.Database)
-		if err == nil {
-			log.Info("Successfully connected to database")
-			break
-		}
-
-		log.Error("Failed to connect to database",
-			zap.Error(err),
-			zap.Int("attempt", i+1),
-			zap.Duration("retry_delay", retryDelay))
+	switch cfg.Database.Driver {
+	case "memory":
+		log.Warn("Keeping data in memory; it is lost when the server stops")
+		db = repository.NewMemoryRepository()
+	case "postgres":
+		db = connectPostgres(log, cfg.Database)
+	default:
+		log.Fatal("Unknown database driver, expected postgres or memory",
+			zap.String("database.driver", cfg.Database.Driver))
+	}
 
-		if i < maxRetries-1 {
-			log.Info("Retrying database connection", zap.Duration("delay", retryDelay))
-			time.Sleep(retryDelay)
+	// Cache recommendations in Redis when a server is configured
+	if cfg.Redis.Addr != "" {
+		redisCtx, cancelRedis := context.WithTimeout(context.Background(), 5*time.Second)
+		recommendations, err := cache.NewRedis(redisCtx, cfg.Redis)
+		cancelRedis()
+		if err != nil {
+			log.Error("Failed to connect to Redis, recommendations will not be cached",
+				zap.String("redis.addr", cfg.Redis.Addr), zap.Error(err))
 		} else {
-			log.Fatal("Failed to connect to database after multiple attempts", zap.Error(err))
+			defer recommendations.Close()
+			ttl := time.Duration(cfg.Redis.RecommendationTTLSeconds) * time.Second
+			db = repository.NewCachedRepository(db, recommendations, ttl)
+			log.Info("Caching recommendations in Redis",
+				zap.String("redis.addr", cfg.Redis.Addr), zap.Duration("ttl", ttl))
 		}
 	}
 
 	// Initialize API handlers
-	api.RegisterHandlers(router, db)
+	api.RegisterHandlers(router, db, cfg)
 
 	// Register Swagger UI
 	api.RegisterSwagger(router)
 
+	// Deliver webhooks and notifications and run background jobs until shutdown
+	background, stopBackground := context.WithCancel(context.Background())
+	notifications := notify.NewServiceFromConfig(cfg.Notify, db, log)
+	var workers sync.WaitGroup
+	workers.Add(2)
+	go func() {
+		defer workers.Done()
+		webhook.NewDispatcher(db, log).Run(background)
+	}()
+	go func() {
+		defer workers.Done()
+		notify.NewSender(notifications, db, log).Run(background)
+	}()
+	if cfg.Jobs.Enabled {
+		runner := jobs.NewRunner(db, log)
+		runner.Register(jobs.NewTasks(db, notifications, cfg.Jobs, log).Jobs()...)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			log.Info("Starting background jobs", zap.String("owner", runner.Owner))
+			runner.Run(background)
+		}()
+	}
+
 	// Create HTTP server
 	srv := &http.Server{
 		Addr:         "0.0.0.0:" + cfg.Server.Port,
@@ -128,6 +175,57 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatal("Server forced to shutdown", zap.Error(err))
 	}
+	stopBackground()
+	workers.Wait()
 
 	log.Info("Server exited properly")
 }
+
+// runMigrate runs the migrate command and returns the exit code
+func runMigrate(log *zap.Logger, args []string) int {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Error("Failed to load configuration", zap.Error(err))
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := migrations.Run(ctx, cfg.Database, args, os.Stdout, log); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// connectPostgres connects to PostgreSQL, retrying while the database starts
+func connectPostgres(log *zap.Logger, cfg config.DatabaseConfig) repository.Repository {
+	maxRetries := 5
+	retryDelay := 5 * time.Second
+
+	for i := 0; i < maxRetries; i++ {
+		log.Info("Attempting to connect to database",
+			zap.Int("attempt", i+1),
+			zap.Int("max_attempts", maxRetries))
+
+		db, err := repository.NewPostgresDB(cfg)
+		if err == nil {
+			log.Info("Successfully connected to database")
+			return db
+		}
+
+		log.Error("Failed to connect to database",
+			zap.Error(err),
+			zap.Int("attempt", i+1),
+			zap.Duration("retry_delay", retryDelay))
+
+		if i < maxRetries-1 {
+			log.Info("Retrying database connection", zap.Duration("delay", retryDelay))
+			time.Sleep(retryDelay)
+		} else {
+			log.Fatal("Failed to connect to database after multiple attempts", zap.Error(err))
+		}
+	}
+	return nil
+}
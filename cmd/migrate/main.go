@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/tusharsingune/meeting-scheduler/internal/config"
+	"github.com/tusharsingune/meeting-scheduler/internal/logger"
+	"github.com/tusharsingune/meeting-scheduler/internal/migrations"
+	"go.uber.org/zap"
+)
+
+func main() {
+	log, err := logger.Initialize("development")
+	if err != nil {
+		fmt.Printf("Failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer log.Sync()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Failed to load configuration", zap.Error(err))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := migrations.Run(ctx, cfg.Database, os.Args[1:], os.Stdout, log); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
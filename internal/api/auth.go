@@ -0,0 +1,158 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/tusharsingune/meeting-scheduler/internal/auth"
+	"github.com/tusharsingune/meeting-scheduler/internal/middleware"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"go.uber.org/zap"
+)
+
+// defaultTokenTTL is how long bearer tokens are valid when not configured
+const defaultTokenTTL = time.Hour
+
+// randomSigner returns a token signer with a random key
+func randomSigner() *auth.Signer {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	signer, _ := auth.NewSigner(key)
+	return signer
+}
+
+// currentParticipant returns the authenticated participant making the
+// request. It responds with 401 and returns nil when there is none.
+func currentParticipant(w http.ResponseWriter, r *http.Request) *models.Participant {
+	participant, ok := middleware.ParticipantFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Authentication required")
+		return nil
+	}
+	return participant
+}
+
+// createAPIKey generates and stores a new API key for a participant. The key
+// itself is only set on the returned APIKey.
+func (h *Handler) createAPIKey(ctx context.Context, participantID uint, name string, expiresAt *time.Time) (*models.APIKey, error) {
+	key, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	apiKey := &models.APIKey{
+		ParticipantID: participantID,
+		Name:          name,
+		Prefix:        auth.APIKeyDisplayPrefix(key),
+		Hash:          auth.HashAPIKey(key),
+		ExpiresAt:     expiresAt,
+	}
+	if err := h.Repo.CreateAPIKey(ctx, apiKey); err != nil {
+		return nil, err
+	}
+	apiKey.Key = key
+	return apiKey, nil
+}
+
+// IssueToken handles exchanging the caller's credentials for a short-lived bearer token
+func (h *Handler) IssueToken(w http.ResponseWriter, r *http.Request) {
+	participant := currentParticipant(w, r)
+	if participant == nil {
+		return
+	}
+
+	token, claims := h.Tokens.Issue(participant.ID, time.Now(), h.TokenTTL)
+
+	h.log(r.Context()).Info("Token issued successfully", zap.Uint("participant_id", participant.ID))
+	respondWithJSON(w, http.StatusOK, models.AccessToken{
+		Token:     token,
+		TokenType: "Bearer",
+		ExpiresAt: claims.ExpiresAt.UTC(),
+	})
+}
+
+// CreateAPIKey handles creating another API key for the caller
+func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	participant := currentParticipant(w, r)
+	if participant == nil {
+		return
+	}
+
+	var request models.APIKey
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&request); err != nil {
+		h.log(r.Context()).Error("Failed to decode request body", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	errs := middleware.ValidateStruct(request)
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		errs = append(errs, middleware.ValidationError{Field: "expires_at", Message: "Expiry must be in the future"})
+	}
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+
+	apiKey, err := h.createAPIKey(r.Context(), participant.ID, request.Name, request.ExpiresAt)
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to create API key")
+		return
+	}
+
+	h.log(r.Context()).Info("API key created successfully",
+		zap.Uint("participant_id", participant.ID),
+		zap.Uint("api_key_id", apiKey.ID))
+	respondWithJSON(w, http.StatusCreated, apiKey)
+}
+
+// GetAPIKeys handles listing the caller's API keys
+func (h *Handler) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	participant := currentParticipant(w, r)
+	if participant == nil {
+		return
+	}
+
+	keys, err := h.Repo.GetParticipantAPIKeys(r.Context(), participant.ID)
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to get API keys")
+		return
+	}
+
+	h.log(r.Context()).Info("API keys retrieved successfully", zap.Uint("participant_id", participant.ID))
+	respondWithJSON(w, http.StatusOK, keys)
+}
+
+// DeleteAPIKey handles revoking one of the caller's API keys
+func (h *Handler) DeleteAPIKey(w http.ResponseWriter, r *http.Request) {
+	participant := currentParticipant(w, r)
+	if participant == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		h.log(r.Context()).Error("Invalid API key ID", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid API key ID")
+		return
+	}
+
+	if err := h.Repo.DeleteAPIKey(r.Context(), participant.ID, uint(id)); err != nil {
+		h.respondWithFailure(w, r, err, "Failed to delete API key")
+		return
+	}
+
+	h.log(r.Context()).Info("API key deleted successfully",
+		zap.Uint("participant_id", participant.ID),
+		zap.Uint64("api_key_id", id))
+	w.WriteHeader(http.StatusNoContent)
+}
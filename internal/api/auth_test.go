@@ -0,0 +1,220 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tusharsingune/meeting-scheduler/internal/auth"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"github.com/tusharsingune/meeting-scheduler/internal/repository"
+)
+
+// testSigningKey signs the bearer tokens of routed test requests
+const testSigningKey = "test-signing-key-0123456789"
+
+// authorize signs a request with a bearer token for the participant and
+// expects the participant to be looked up
+func authorize(t *testing.T, mockRepo *MockRepository, req *http.Request, participantID uint) {
+	t.Helper()
+	signer, err := auth.NewSigner([]byte(testSigningKey))
+	require.NoError(t, err)
+	token, _ := signer.Issue(participantID, time.Now(), time.Hour)
+	req.Header.Set("Authorization", "Bearer "+token)
+	mockRepo.On("GetParticipant", participantID).
+		Return(&models.Participant{ID: participantID, Name: "Ada", Email: "ada@example.com"}, nil)
+}
+
+func TestRoutesRequireAuthentication(t *testing.T) {
+	signer, err := auth.NewSigner([]byte("some-other-signing-key"))
+	require.NoError(t, err)
+	foreign, _ := signer.Issue(1, time.Now(), time.Hour)
+	expired, _ := signer.Issue(1, time.Now().Add(-2*time.Hour), time.Hour)
+
+	tests := []struct {
+		name          string
+		authorization string
+	}{
+		{"no credentials", ""},
+		{"other scheme", "Basic YWRhOnNlY3JldA=="},
+		{"malformed token", "Bearer not-a-token"},
+		{"token signed with another key", "Bearer " + foreign},
+		{"expired token", "Bearer " + expired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			router := mux.NewRouter()
+			RegisterHandlers(router, mockRepo, testConfig())
+
+			req := httptest.NewRequest("DELETE", "/api/v1/events/1", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+			mockRepo.AssertNotCalled(t, "DeleteEvent", mock.Anything)
+		})
+	}
+}
+
+func TestRoutesRejectTokenOfDeletedParticipant(t *testing.T) {
+	mockRepo := new(MockRepository)
+	router := mux.NewRouter()
+	RegisterHandlers(router, mockRepo, testConfig())
+
+	signer, err := auth.NewSigner([]byte(testSigningKey))
+	require.NoError(t, err)
+	token, _ := signer.Issue(7, time.Now(), time.Hour)
+	mockRepo.On("GetParticipant", uint(7)).Return(nil, repository.ErrNotFound)
+
+	req := httptest.NewRequest("GET", "/api/v1/events/1", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSignUpReturnsAPIKey(t *testing.T) {
+	mockRepo := new(MockRepository)
+	router := mux.NewRouter()
+	RegisterHandlers(router, mockRepo, testConfig())
+
+	mockRepo.On("CreateParticipant", mock.AnythingOfType("*models.Participant")).
+		Run(func(args mock.Arguments) { args.Get(0).(*models.Participant).ID = 3 }).
+		Return(nil)
+	var stored *models.APIKey
+	mockRepo.On("CreateAPIKey", mock.AnythingOfType("*models.APIKey")).
+		Run(func(args mock.Arguments) { stored = args.Get(0).(*models.APIKey) }).
+		Return(nil)
+
+	body := `{"name":"Ada","email":" Ada@Example.com","guest":true}`
+	req := httptest.NewRequest("POST", "/api/v1/participants", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	var participant models.Participant
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &participant))
+	assert.True(t, strings.HasPrefix(participant.APIKey, auth.APIKeyPrefix))
+	assert.Equal(t, "ada@example.com", participant.Email, "emails are stored in lower case")
+	assert.False(t, participant.Guest, "only invitation links create guests")
+
+	// Only the hash of the key is stored
+	require.NotNil(t, stored)
+	assert.Equal(t, uint(3), stored.ParticipantID)
+	assert.Equal(t, auth.HashAPIKey(participant.APIKey), stored.Hash)
+	assert.NotContains(t, stored.Hash, participant.APIKey)
+}
+
+func TestIssueTokenWithAPIKey(t *testing.T) {
+	const key = auth.APIKeyPrefix + "secret"
+	participant := &models.Participant{ID: 3, Name: "Ada", Email: "ada@example.com"}
+	expiredAt := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name       string
+		header     string
+		apiKey     *models.APIKey
+		lookupErr  error
+		wantStatus int
+	}{
+		{"X-API-Key header", "X-API-Key", &models.APIKey{ID: 1, ParticipantID: 3, Participant: participant}, nil, http.StatusOK},
+		{"bearer API key", "Authorization", &models.APIKey{ID: 1, ParticipantID: 3, Participant: participant}, nil, http.StatusOK},
+		{"expired key", "X-API-Key", &models.APIKey{ID: 1, ParticipantID: 3, Participant: participant, ExpiresAt: &expiredAt}, nil, http.StatusUnauthorized},
+		{"unknown key", "X-API-Key", nil, repository.ErrNotFound, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			router := mux.NewRouter()
+			RegisterHandlers(router, mockRepo, testConfig())
+
+			mockRepo.On("GetAPIKeyByHash", auth.HashAPIKey(key)).Return(tt.apiKey, tt.lookupErr)
+
+			req := httptest.NewRequest("POST", "/api/v1/auth/token", nil)
+			if tt.header == "Authorization" {
+				req.Header.Set("Authorization", "Bearer "+key)
+			} else {
+				req.Header.Set(tt.header, key)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var token models.AccessToken
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &token))
+			assert.Equal(t, "Bearer", token.TokenType)
+
+			signer, err := auth.NewSigner([]byte(testSigningKey))
+			require.NoError(t, err)
+			claims, err := signer.Verify(token.Token, time.Now())
+			require.NoError(t, err)
+			assert.Equal(t, uint(3), claims.ParticipantID)
+			assert.WithinDuration(t, time.Now().Add(time.Hour), claims.ExpiresAt, time.Minute)
+		})
+	}
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	mockRepo := new(MockRepository)
+	router := mux.NewRouter()
+	RegisterHandlers(router, mockRepo, testConfig())
+
+	mockRepo.On("CreateAPIKey", mock.MatchedBy(func(key *models.APIKey) bool {
+		return key.ParticipantID == 3 && key.Name == "laptop" && key.Hash != ""
+	})).Return(nil)
+
+	body, _ := json.Marshal(map[string]interface{}{"name": "laptop", "participant_id": 9})
+	req := httptest.NewRequest("POST", "/api/v1/api-keys", bytes.NewBuffer(body))
+	authorize(t, mockRepo, req, 3)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	var key models.APIKey
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &key))
+	assert.True(t, strings.HasPrefix(key.Key, key.Prefix))
+	assert.NotContains(t, w.Body.String(), `"hash"`)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeleteAPIKeyIsScopedToCaller(t *testing.T) {
+	mockRepo := new(MockRepository)
+	router := mux.NewRouter()
+	RegisterHandlers(router, mockRepo, testConfig())
+
+	mockRepo.On("DeleteAPIKey", uint(3), uint(5)).Return(nil)
+
+	req := httptest.NewRequest("DELETE", "/api/v1/api-keys/5", nil)
+	authorize(t, mockRepo, req, 3)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockRepo.AssertExpectations(t)
+}
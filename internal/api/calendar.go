@@ -0,0 +1,410 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/tusharsingune/meeting-scheduler/internal/calendar"
+	"github.com/tusharsingune/meeting-scheduler/internal/middleware"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"github.com/tusharsingune/meeting-scheduler/internal/repository"
+	"github.com/tusharsingune/meeting-scheduler/internal/scheduling"
+	"github.com/tusharsingune/meeting-scheduler/internal/webhook"
+	"go.uber.org/zap"
+)
+
+// ExportEventCalendar handles exporting a confirmed event as an iCalendar file
+func (h *Handler) ExportEventCalendar(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		h.log(r.Context()).Error("Invalid event ID", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid event ID")
+		return
+	}
+
+	event, _ := h.authorizeEvent(w, r, uint(id), actionView)
+	if event == nil {
+		return
+	}
+	if event.ConfirmedTimeSlot == nil {
+		respondWithError(w, http.StatusConflict, "Event has no confirmed time slot")
+		return
+	}
+
+	vevents, err := h.calendarEvents(r.Context(), event)
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to build calendar event")
+		return
+	}
+
+	h.log(r.Context()).Info("Event calendar exported successfully", zap.Uint("event_id", event.ID))
+	respondWithCalendar(w, calendar.Calendar{Events: vevents})
+}
+
+// ExportParticipantCalendar handles the subscribable calendar feed of a
+// participant, listing every scheduled event they are on
+func (h *Handler) ExportParticipantCalendar(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		h.log(r.Context()).Error("Invalid participant ID", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid participant ID")
+		return
+	}
+
+	caller := currentParticipant(w, r)
+	if caller == nil {
+		return
+	}
+	if caller.ID != uint(id) {
+		respondWithError(w, http.StatusForbidden, "Participants can only export their own calendar")
+		return
+	}
+
+	participant, err := h.Repo.GetParticipant(r.Context(), uint(id))
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to get participant")
+		return
+	}
+
+	events, err := h.Repo.GetParticipantEvents(r.Context(), participant.ID)
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to get participant events")
+		return
+	}
+
+	cal := calendar.Calendar{Name: "Meetings for " + participant.Name}
+	for i := range events {
+		// Only events with a confirmed time slot have a time to put in a calendar
+		if events[i].ConfirmedTimeSlot == nil {
+			continue
+		}
+		vevents, err := h.calendarEvents(r.Context(), &events[i])
+		if err != nil {
+			h.respondWithFailure(w, r, err, "Failed to build calendar event")
+			return
+		}
+		cal.Events = append(cal.Events, vevents...)
+	}
+
+	h.log(r.Context()).Info("Participant calendar exported successfully",
+		zap.Uint("participant_id", participant.ID),
+		zap.Int("events", len(cal.Events)))
+	respondWithCalendar(w, cal)
+}
+
+// maxCalendarUpload is the largest calendar file accepted for import
+const maxCalendarUpload = 5 << 20
+
+// ImportAvailability handles answering an event's time slots from a
+// participant's calendar. The calendar is uploaded as the "file" field of a
+// multipart form or as a text/calendar body; each time slot overlapping a
+// busy time is answered "no" and every other slot "yes". With dry_run=true
+// the answers are returned for review without being saved.
+func (h *Handler) ImportAvailability(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	eventID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		h.log(r.Context()).Error("Invalid event ID", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid event ID")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxCalendarUpload)
+	defer r.Body.Close()
+
+	file, err := calendarUpload(r)
+	if err != nil {
+		h.log(r.Context()).Error("Failed to read calendar upload", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid calendar upload: "+err.Error())
+		return
+	}
+
+	// Participants import their own calendar unless they say otherwise
+	participantField := r.FormValue("participant_id")
+	if caller, ok := middleware.ParticipantFromContext(r.Context()); ok && participantField == "" {
+		participantField = strconv.FormatUint(uint64(caller.ID), 10)
+	}
+	participantID, err := strconv.ParseUint(participantField, 10, 32)
+	if err != nil || participantID == 0 {
+		respondWithValidationErrors(w, middleware.ValidationErrors{{
+			Field:   "participant_id",
+			Message: "This field is required",
+		}})
+		return
+	}
+	dryRun, _ := strconv.ParseBool(r.FormValue("dry_run"))
+
+	participant, err := h.Repo.GetParticipant(r.Context(), uint(participantID))
+	if err != nil {
+		h.log(r.Context()).Error("Failed to get participant", zap.Error(err))
+		respondWithValidationErrors(w, middleware.ValidationErrors{{
+			Field:   "participant_id",
+			Message: "Participant not found",
+		}})
+		return
+	}
+
+	event := h.authorizeAnswer(w, r, uint(eventID), uint(participantID))
+	if event == nil {
+		return
+	}
+	if !event.AcceptsAvailability() {
+		respondWithError(w, http.StatusConflict, "Event is "+string(event.Status)+" and no longer accepts availability")
+		return
+	}
+
+	// Floating times in the file are read in the participant's own time zone
+	loc, err := scheduling.LoadLocation(participant.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	from, to := slotBounds(event.TimeSlots)
+	busy, err := calendar.BusyTimes(file, from, to, loc)
+	if err != nil {
+		h.log(r.Context()).Error("Failed to parse calendar", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid calendar file: "+err.Error())
+		return
+	}
+
+	result := models.AvailabilityImport{
+		Availabilities: make([]models.Availability, 0, len(event.TimeSlots)),
+		BusyPeriods:    make([]models.BusyPeriod, 0, len(busy)),
+		Saved:          !dryRun,
+	}
+	for _, period := range busy {
+		result.BusyPeriods = append(result.BusyPeriods, models.BusyPeriod{StartTime: period.Start, EndTime: period.End})
+	}
+	for _, slot := range event.TimeSlots {
+		availability := models.Availability{
+			ParticipantID: participant.ID,
+			TimeSlotID:    slot.ID,
+			Status:        models.AvailabilityYes,
+		}
+		for _, period := range busy {
+			if period.Overlaps(slot.StartTime, slot.EndTime) {
+				availability.Status = models.AvailabilityNo
+				break
+			}
+		}
+		availability.Normalize()
+		result.Availabilities = append(result.Availabilities, availability)
+	}
+
+	if dryRun {
+		respondWithJSON(w, http.StatusOK, result)
+		return
+	}
+
+	err = h.Repo.Transaction(r.Context(), func(tx repository.Repository) error {
+		if err := tx.UpsertAvailabilities(r.Context(), event.ID, result.Availabilities); err != nil {
+			return err
+		}
+		return publish(r.Context(), tx, webhook.AvailabilitySubmitted, event, webhook.Availability{
+			EventID:        event.ID,
+			ParticipantID:  participant.ID,
+			Availabilities: result.Availabilities,
+		})
+	})
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to save availabilities")
+		return
+	}
+
+	h.log(r.Context()).Info("Availability imported successfully",
+		zap.Uint64("event_id", eventID),
+		zap.Uint("participant_id", participant.ID),
+		zap.Int("busy_periods", len(busy)))
+	respondWithJSON(w, http.StatusCreated, result)
+}
+
+// calendarUpload returns the uploaded calendar file, taken from the "file"
+// field of a multipart form or else from the request body
+func calendarUpload(r *http.Request) (io.Reader, error) {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, uploadError(err)
+		}
+		return bytes.NewReader(body), nil
+	}
+
+	if err := r.ParseMultipartForm(maxCalendarUpload); err != nil {
+		return nil, uploadError(err)
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		return nil, errors.New("missing file field")
+	}
+	return file, nil
+}
+
+func uploadError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return fmt.Errorf("file exceeds %d bytes", tooLarge.Limit)
+	}
+	return err
+}
+
+// slotBounds returns the period spanned by a set of time slots
+func slotBounds(slots []models.TimeSlot) (time.Time, time.Time) {
+	var from, to time.Time
+	for i, slot := range slots {
+		if i == 0 || slot.StartTime.Before(from) {
+			from = slot.StartTime
+		}
+		if i == 0 || slot.EndTime.After(to) {
+			to = slot.EndTime
+		}
+	}
+	return from, to
+}
+
+// calendarEvents builds the VEVENTs of a confirmed event: the event itself
+// and, for a recurring event, one for each moved occurrence
+func (h *Handler) calendarEvents(ctx context.Context, event *models.Event) ([]calendar.Event, error) {
+	vevent, err := h.calendarEvent(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	if !event.IsRecurring() {
+		return []calendar.Event{vevent}, nil
+	}
+
+	loc, err := scheduling.SeriesLocation(*event)
+	if err != nil {
+		return nil, err
+	}
+	vevent.Location = loc
+	vevent.Recurrence = event.Recurrence
+
+	vevents := []calendar.Event{vevent}
+	for _, exception := range event.Exceptions {
+		if exception.Cancelled {
+			vevents[0].ExDates = append(vevents[0].ExDates, exception.OccurrenceStart)
+			continue
+		}
+		if exception.StartTime == nil || exception.EndTime == nil {
+			continue
+		}
+		moved := vevent
+		moved.Recurrence = ""
+		moved.ExDates = nil
+		moved.RecurrenceID = exception.OccurrenceStart
+		moved.Start = *exception.StartTime
+		moved.End = *exception.EndTime
+		moved.LastModified = exception.UpdatedAt
+		vevents = append(vevents, moved)
+	}
+	return vevents, nil
+}
+
+// calendarEvent builds the VEVENT of a confirmed event. Invitees and everyone
+// who answered the confirmed slot become attendees, with their answer as
+// participation status.
+func (h *Handler) calendarEvent(ctx context.Context, event *models.Event) (calendar.Event, error) {
+	slot := event.ConfirmedTimeSlot
+
+	vevent := calendar.Event{
+		UID:          fmt.Sprintf("event-%d@meeting-scheduler", event.ID),
+		Summary:      event.Title,
+		Description:  event.Description,
+		Start:        slot.StartTime,
+		End:          slot.EndTime,
+		Status:       calendar.StatusConfirmed,
+		Created:      event.CreatedAt,
+		LastModified: event.UpdatedAt,
+		Stamp:        event.UpdatedAt,
+	}
+	if event.Status == models.EventCancelled {
+		vevent.Status = calendar.StatusCancelled
+	}
+
+	if organizer, err := h.Repo.GetParticipant(ctx, event.OrganizerId); err == nil {
+		vevent.Organizer = &calendar.Attendee{Name: organizer.Name, Email: organizer.Email}
+	}
+
+	answers, err := h.Repo.GetEventAvailabilities(ctx, event.ID)
+	if err != nil {
+		return calendar.Event{}, err
+	}
+	statuses := make(map[uint]models.AvailabilityStatus)
+	for _, answer := range answers {
+		if answer.TimeSlotID == slot.ID {
+			answer.Normalize()
+			statuses[answer.ParticipantID] = answer.Status
+		}
+	}
+
+	listed := make(map[uint]bool)
+	for _, invitation := range event.Invitations {
+		if invitation.Participant == nil {
+			continue
+		}
+		role := calendar.RoleRequired
+		if invitation.Role == models.AttendeeOptional {
+			role = calendar.RoleOptional
+		}
+		vevent.Attendees = append(vevent.Attendees, calendar.Attendee{
+			Name:     invitation.Participant.Name,
+			Email:    invitation.Participant.Email,
+			Role:     role,
+			PartStat: partStat(statuses[invitation.ParticipantID]),
+		})
+		listed[invitation.ParticipantID] = true
+	}
+
+	for _, answer := range answers {
+		if answer.TimeSlotID != slot.ID || listed[answer.ParticipantID] {
+			continue
+		}
+		participant, err := h.Repo.GetParticipant(ctx, answer.ParticipantID)
+		if err != nil {
+			return calendar.Event{}, err
+		}
+		vevent.Attendees = append(vevent.Attendees, calendar.Attendee{
+			Name:     participant.Name,
+			Email:    participant.Email,
+			Role:     calendar.RoleOptional,
+			PartStat: partStat(statuses[answer.ParticipantID]),
+		})
+		listed[answer.ParticipantID] = true
+	}
+
+	return vevent, nil
+}
+
+// partStat maps an availability answer to an iCalendar participation status
+func partStat(status models.AvailabilityStatus) string {
+	switch status {
+	case models.AvailabilityYes:
+		return calendar.PartStatAccepted
+	case models.AvailabilityIfNeedBe:
+		return calendar.PartStatTentative
+	case models.AvailabilityNo:
+		return calendar.PartStatDeclined
+	default:
+		return calendar.PartStatNeedsAction
+	}
+}
+
+func respondWithCalendar(w http.ResponseWriter, cal calendar.Calendar) {
+	var buf bytes.Buffer
+	if err := cal.Encode(&buf); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "")
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
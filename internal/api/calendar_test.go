@@ -0,0 +1,294 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+)
+
+func confirmedEvent() *models.Event {
+	start := time.Date(2030, 6, 3, 10, 0, 0, 0, time.UTC)
+	slot := models.TimeSlot{ID: 4, EventID: 1, StartTime: start, EndTime: start.Add(time.Hour)}
+	event := &models.Event{
+		ID:          1,
+		Title:       "Team Meeting",
+		Description: "Weekly sync, all hands",
+		OrganizerId: 1,
+		Duration:    60,
+		Status:      models.EventConfirmed,
+		TimeSlots:   []models.TimeSlot{slot},
+		Invitations: []models.EventInvitation{
+			{EventID: 1, ParticipantID: 2, Role: models.AttendeeRequired, Participant: &models.Participant{ID: 2, Name: "Grace", Email: "grace@example.com"}},
+			{EventID: 1, ParticipantID: 3, Role: models.AttendeeOptional, Participant: &models.Participant{ID: 3, Name: "Linus", Email: "linus@example.com"}},
+		},
+	}
+	event.Confirm(slot, start.Add(-48*time.Hour))
+	return event
+}
+
+func TestExportEventCalendar(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	mockRepo.On("GetEvent", uint(1)).Return(confirmedEvent(), nil)
+	mockRepo.On("GetParticipant", uint(1)).Return(&models.Participant{ID: 1, Name: "Ada", Email: "ada@example.com"}, nil)
+	mockRepo.On("GetParticipant", uint(5)).Return(&models.Participant{ID: 5, Name: "Ken", Email: "ken@example.com"}, nil)
+	mockRepo.On("GetEventAvailabilities", uint(1)).Return([]models.Availability{
+		{ParticipantID: 2, TimeSlotID: 4, Status: models.AvailabilityYes},
+		{ParticipantID: 5, TimeSlotID: 4, Status: models.AvailabilityIfNeedBe},
+		{ParticipantID: 3, TimeSlotID: 9, Status: models.AvailabilityNo},
+	}, nil)
+
+	req := httptest.NewRequest("GET", "/events/1.ics", nil)
+	w := httptest.NewRecorder()
+
+	vars := map[string]string{"id": "1"}
+	req = asParticipant(mux.SetURLVars(req, vars), 1)
+
+	handler.ExportEventCalendar(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/calendar; charset=utf-8", w.Header().Get("Content-Type"))
+
+	body := w.Body.String()
+	assert.Contains(t, body, "UID:event-1@meeting-scheduler\r\n")
+	assert.Contains(t, body, "DTSTART:20300603T100000Z\r\n")
+	assert.Contains(t, body, "DTEND:20300603T110000Z\r\n")
+	assert.Contains(t, body, "SUMMARY:Team Meeting\r\n")
+	assert.Contains(t, body, "DESCRIPTION:Weekly sync\\, all hands\r\n")
+	assert.Contains(t, body, "ORGANIZER;CN=Ada:mailto:ada@example.com\r\n")
+	assert.Contains(t, body, "ATTENDEE;CN=Grace;ROLE=REQ-PARTICIPANT;PARTSTAT=ACCEPTED:mailto:grace@exam")
+	assert.Contains(t, body, "ATTENDEE;CN=Linus;ROLE=OPT-PARTICIPANT;PARTSTAT=NEEDS-ACTION:mailto:linu")
+	assert.Contains(t, body, "ATTENDEE;CN=Ken;ROLE=OPT-PARTICIPANT;PARTSTAT=TENTATIVE:mailto:ken@exampl")
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExportEventCalendarRequiresConfirmation(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	mockRepo.On("GetEvent", uint(1)).Return(&models.Event{ID: 1, OrganizerId: 1, Status: models.EventPolling}, nil)
+
+	req := httptest.NewRequest("GET", "/events/1.ics", nil)
+	w := httptest.NewRecorder()
+
+	vars := map[string]string{"id": "1"}
+	req = asParticipant(mux.SetURLVars(req, vars), 1)
+
+	handler.ExportEventCalendar(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestExportParticipantCalendar(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	mockRepo.On("GetParticipant", uint(2)).Return(&models.Participant{ID: 2, Name: "Grace", Email: "grace@example.com"}, nil)
+	mockRepo.On("GetParticipant", uint(1)).Return(&models.Participant{ID: 1, Name: "Ada", Email: "ada@example.com"}, nil)
+	mockRepo.On("GetParticipantEvents", uint(2)).Return([]models.Event{
+		*confirmedEvent(),
+		{ID: 2, Title: "Still polling", Status: models.EventPolling},
+	}, nil)
+	mockRepo.On("GetEventAvailabilities", uint(1)).Return([]models.Availability{}, nil)
+
+	req := httptest.NewRequest("GET", "/participants/2/calendar.ics", nil)
+	w := httptest.NewRecorder()
+
+	vars := map[string]string{"id": "2"}
+	req = asParticipant(mux.SetURLVars(req, vars), 2)
+
+	handler.ExportParticipantCalendar(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	body := w.Body.String()
+	assert.Contains(t, body, "X-WR-CALNAME:Meetings for Grace\r\n")
+	assert.Contains(t, body, "UID:event-1@meeting-scheduler\r\n")
+	assert.NotContains(t, body, "Still polling")
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEventCalendarRoute(t *testing.T) {
+	mockRepo := new(MockRepository)
+	router := mux.NewRouter()
+	RegisterHandlers(router, mockRepo, testConfig())
+
+	mockRepo.On("GetEvent", uint(1)).Return(&models.Event{ID: 1, OrganizerId: 1, Status: models.EventPolling}, nil)
+
+	req := httptest.NewRequest("GET", "/api/v1/events/1.ics", nil)
+	authorize(t, mockRepo, req, 1)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	// Routed to the calendar export rather than GetEvent
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+// busyCalendar has a floating daily stand-up from 09:30 to 10:00, read in
+// the participant's time zone
+const busyCalendar = `BEGIN:VCALENDAR
+VERSION:2.0
+BEGIN:VEVENT
+UID:standup
+DTSTART:20300603T093000
+DURATION:PT30M
+RRULE:FREQ=DAILY;COUNT=3
+END:VEVENT
+END:VCALENDAR
+`
+
+func importEvent() *models.Event {
+	return &models.Event{
+		ID:          1,
+		Duration:    60,
+		Status:      models.EventPolling,
+		Invitations: []models.EventInvitation{{EventID: 1, ParticipantID: 2}},
+		TimeSlots: []models.TimeSlot{
+			// 09:00 to 10:00 in Berlin, during the stand-up
+			{ID: 1, EventID: 1, StartTime: time.Date(2030, 6, 3, 7, 0, 0, 0, time.UTC), EndTime: time.Date(2030, 6, 3, 8, 0, 0, 0, time.UTC)},
+			{ID: 2, EventID: 1, StartTime: time.Date(2030, 6, 3, 12, 0, 0, 0, time.UTC), EndTime: time.Date(2030, 6, 3, 13, 0, 0, 0, time.UTC)},
+			{ID: 3, EventID: 1, StartTime: time.Date(2030, 6, 4, 9, 0, 0, 0, time.UTC), EndTime: time.Date(2030, 6, 4, 10, 0, 0, 0, time.UTC)},
+		},
+	}
+}
+
+func TestImportAvailability(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	mockRepo.On("GetParticipant", uint(2)).Return(&models.Participant{ID: 2, Name: "Grace", TimeZone: "Europe/Berlin"}, nil)
+	mockRepo.On("GetEvent", uint(1)).Return(importEvent(), nil)
+	mockRepo.On("UpsertAvailabilities", uint(1), mock.MatchedBy(func(answers []models.Availability) bool {
+		return len(answers) == 3 &&
+			answers[0].Status == models.AvailabilityNo && !answers[0].IsAvailable &&
+			answers[1].Status == models.AvailabilityYes &&
+			answers[2].Status == models.AvailabilityYes
+	})).Return(nil)
+
+	req := httptest.NewRequest("POST", "/events/1/availability/import?participant_id=2", strings.NewReader(busyCalendar))
+	req.Header.Set("Content-Type", "text/calendar")
+	w := httptest.NewRecorder()
+
+	vars := map[string]string{"id": "1"}
+	req = asParticipant(mux.SetURLVars(req, vars), 2)
+
+	handler.ImportAvailability(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var result models.AvailabilityImport
+	err := json.Unmarshal(w.Body.Bytes(), &result)
+	assert.NoError(t, err)
+	assert.True(t, result.Saved)
+	assert.Len(t, result.Availabilities, 3)
+	// The stand-ups on 3 and 4 June fall within the span of the time slots
+	assert.Len(t, result.BusyPeriods, 2)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestImportAvailabilityDryRunFromForm(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	mockRepo.On("GetParticipant", uint(2)).Return(&models.Participant{ID: 2, Name: "Grace", TimeZone: "UTC"}, nil)
+	mockRepo.On("GetEvent", uint(1)).Return(importEvent(), nil)
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("participant_id", "2")
+	form.WriteField("dry_run", "true")
+	file, _ := form.CreateFormFile("file", "calendar.ics")
+	file.Write([]byte(busyCalendar))
+	form.Close()
+
+	req := httptest.NewRequest("POST", "/events/1/availability/import", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+
+	vars := map[string]string{"id": "1"}
+	req = asParticipant(mux.SetURLVars(req, vars), 2)
+
+	handler.ImportAvailability(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var result models.AvailabilityImport
+	err := json.Unmarshal(w.Body.Bytes(), &result)
+	assert.NoError(t, err)
+	assert.False(t, result.Saved)
+	// Read in UTC the stand-up clashes with the last slot instead of the first
+	assert.Equal(t, models.AvailabilityYes, result.Availabilities[0].Status)
+	assert.Equal(t, models.AvailabilityNo, result.Availabilities[2].Status)
+
+	mockRepo.AssertNotCalled(t, "UpsertAvailabilities", mock.Anything, mock.Anything)
+}
+
+func TestImportAvailabilityErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		body         string
+		event        *models.Event
+		expectedCode int
+	}{
+		{
+			name:         "invalid participant",
+			query:        "?participant_id=grace",
+			body:         busyCalendar,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "malformed calendar",
+			query:        "?participant_id=2",
+			body:         "BEGIN:VCALENDAR\nBEGIN:VEVENT\n",
+			event:        importEvent(),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "confirmed event",
+			query:        "?participant_id=2",
+			body:         busyCalendar,
+			event:        &models.Event{ID: 1, Status: models.EventConfirmed, Invitations: []models.EventInvitation{{EventID: 1, ParticipantID: 2}}},
+			expectedCode: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			handler := setupTestHandler(mockRepo)
+
+			mockRepo.On("GetParticipant", uint(2)).Return(&models.Participant{ID: 2, Name: "Grace"}, nil)
+			if tt.event != nil {
+				mockRepo.On("GetEvent", uint(1)).Return(tt.event, nil)
+			}
+
+			req := httptest.NewRequest("POST", "/events/1/availability/import"+tt.query, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "text/calendar")
+			w := httptest.NewRecorder()
+
+			vars := map[string]string{"id": "1"}
+			req = asParticipant(mux.SetURLVars(req, vars), 2)
+
+			handler.ImportAvailability(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			mockRepo.AssertNotCalled(t, "UpsertAvailabilities", mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -1,34 +1,89 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/tusharsingune/meeting-scheduler/internal/auth"
+	"github.com/tusharsingune/meeting-scheduler/internal/config"
 	"github.com/tusharsingune/meeting-scheduler/internal/logger"
+	"github.com/tusharsingune/meeting-scheduler/internal/middleware"
 	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"github.com/tusharsingune/meeting-scheduler/internal/notify"
 	"github.com/tusharsingune/meeting-scheduler/internal/repository"
+	"github.com/tusharsingune/meeting-scheduler/internal/scheduling"
+	"github.com/tusharsingune/meeting-scheduler/internal/webhook"
 	"go.uber.org/zap"
 )
 
 // Handler handles HTTP requests
 type Handler struct {
-	Repo repository.Repository
-	Log  *zap.Logger
+	Repo          repository.Repository
+	Log           *zap.Logger
+	WorkingHours  scheduling.WorkingHours
+	Horizon       time.Duration // how far ahead slots may be scheduled, 0 for no limit
+	Tokens        *auth.Signer
+	TokenTTL      time.Duration
+	InvitationTTL time.Duration // how long invitation links are valid by default
+	Notifications *notify.Service
+
+	ReminderCooldown time.Duration // how long after a reminder an invitee is not reminded again on request
 }
 
 // NewHandler creates a new Handler instance
-func NewHandler(repo repository.Repository) *Handler {
+func NewHandler(repo repository.Repository, cfg *config.Config) *Handler {
+	log := logger.GetLogger()
+
+	workingHours, err := scheduling.ParseWorkingHours(cfg.Scheduling.WorkdayStart, cfg.Scheduling.WorkdayEnd)
+	if err != nil {
+		log.Warn("Invalid working hours configuration, using defaults", zap.Error(err))
+		workingHours = scheduling.DefaultWorkingHours
+	}
+
+	tokens, err := auth.NewSigner([]byte(cfg.Auth.SigningKey))
+	if err != nil {
+		log.Warn("Invalid auth signing key, using a random key; issued tokens will not survive a restart", zap.Error(err))
+		tokens = randomSigner()
+	}
+
+	tokenTTL := time.Duration(cfg.Auth.TokenTTLMinutes) * time.Minute
+	if tokenTTL <= 0 {
+		tokenTTL = defaultTokenTTL
+	}
+
+	invitationTTL := time.Duration(cfg.Auth.InvitationTTLHours) * time.Hour
+	if invitationTTL <= 0 {
+		invitationTTL = defaultInvitationTTL
+	}
+
+	reminderCooldown := time.Duration(cfg.Notify.ReminderCooldownHours) * time.Hour
+	if reminderCooldown <= 0 {
+		reminderCooldown = defaultReminderCooldown
+	}
+
 	return &Handler{
-		Repo: repo,
-		Log:  logger.GetLogger(),
+		Repo:             repo,
+		Log:              log,
+		WorkingHours:     workingHours,
+		Horizon:          time.Duration(cfg.Scheduling.HorizonDays) * 24 * time.Hour,
+		Tokens:           tokens,
+		TokenTTL:         tokenTTL,
+		InvitationTTL:    invitationTTL,
+		Notifications:    notify.NewServiceFromConfig(cfg.Notify, repo, log),
+		ReminderCooldown: reminderCooldown,
 	}
 }
 
 // RegisterHandlers registers all API routes
-func RegisterHandlers(r *mux.Router, repo repository.Repository) {
-	h := NewHandler(repo)
+func RegisterHandlers(r *mux.Router, repo repository.Repository, cfg *config.Config) {
+	h := NewHandler(repo, cfg)
 
 	// Health check endpoint
 	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -39,14 +94,14 @@ func RegisterHandlers(r *mux.Router, repo repository.Repository) {
 	// Debug endpoint to check database connectivity
 	r.HandleFunc("/debug/db", func(w http.ResponseWriter, r *http.Request) {
 		// Try to get a participant to test DB connection
-		_, err := repo.GetParticipant(1)
+		_, err := repo.GetParticipant(r.Context(), 1)
 		if err != nil {
 			// Create a test participant if not found
 			testParticipant := &models.Participant{
 				Name:  "Test User",
 				Email: "test@example.com",
 			}
-			err = repo.CreateParticipant(testParticipant)
+			err = repo.CreateParticipant(r.Context(), testParticipant)
 			if err != nil {
 				respondWithJSON(w, http.StatusInternalServerError, map[string]string{
 					"status":  "error",
@@ -62,28 +117,71 @@ func RegisterHandlers(r *mux.Router, repo repository.Repository) {
 		})
 	})
 
+	// Signing up needs no credentials, and guests answer with their invitation token instead
+	r.HandleFunc("/api/v1/participants", h.CreateParticipant).Methods(http.MethodPost)
+	r.HandleFunc("/api/v1/guest/{token}", h.GetGuestInvitation).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/guest/{token}/availability", h.SubmitGuestAvailability).Methods(http.MethodPost)
+
 	// API v1 subrouter
 	v1 := r.PathPrefix("/api/v1").Subrouter()
+	v1.Use(mux.MiddlewareFunc(middleware.Authenticate(&middleware.CredentialAuthenticator{Tokens: h.Tokens, Repo: repo})))
+
+	// Credentials
+	v1.HandleFunc("/auth/token", h.IssueToken).Methods(http.MethodPost)
+	v1.HandleFunc("/api-keys", h.CreateAPIKey).Methods(http.MethodPost)
+	v1.HandleFunc("/api-keys", h.GetAPIKeys).Methods(http.MethodGet)
+	v1.HandleFunc("/api-keys/{id}", h.DeleteAPIKey).Methods(http.MethodDelete)
+
+	// Webhooks
+	v1.HandleFunc("/webhooks", h.CreateWebhook).Methods(http.MethodPost)
+	v1.HandleFunc("/webhooks", h.GetWebhooks).Methods(http.MethodGet)
+	v1.HandleFunc("/webhooks/{id}", h.DeleteWebhook).Methods(http.MethodDelete)
+	v1.HandleFunc("/webhooks/{id}/deliveries", h.GetWebhookDeliveries).Methods(http.MethodGet)
 
 	// Event endpoints
 	events := v1.PathPrefix("/events").Subrouter()
 	events.HandleFunc("", h.CreateEvent).Methods(http.MethodPost)
+	events.HandleFunc("", h.ListEvents).Methods(http.MethodGet)
+	events.HandleFunc("/{id:[0-9]+}.ics", h.ExportEventCalendar).Methods(http.MethodGet)
 	events.HandleFunc("/{id}", h.GetEvent).Methods(http.MethodGet)
 	events.HandleFunc("/{id}", h.UpdateEvent).Methods(http.MethodPut)
 	events.HandleFunc("/{id}", h.DeleteEvent).Methods(http.MethodDelete)
+	events.HandleFunc("/{id}/confirm", h.ConfirmEvent).Methods(http.MethodPost)
+	events.HandleFunc("/{id}/cancel", h.CancelEvent).Methods(http.MethodPost)
 
 	// Time slots
 	events.HandleFunc("/{id}/timeslots", h.AddTimeSlot).Methods(http.MethodPost)
 	events.HandleFunc("/{id}/timeslots", h.GetTimeSlots).Methods(http.MethodGet)
+	events.HandleFunc("/{id}/timeslots:generate", h.GenerateTimeSlots).Methods(http.MethodPost)
+
+	// Occurrence exceptions
+	events.HandleFunc("/{id}/exceptions", h.CreateOccurrenceException).Methods(http.MethodPost)
+	events.HandleFunc("/{id}/exceptions/{eid}", h.DeleteOccurrenceException).Methods(http.MethodDelete)
+
+	// Invitations
+	events.HandleFunc("/{id}/invitations", h.InviteParticipant).Methods(http.MethodPost)
+	events.HandleFunc("/{id}/invitations", h.GetInvitations).Methods(http.MethodGet)
+	events.HandleFunc("/{id}/invitations/{pid}", h.RemoveInvitation).Methods(http.MethodDelete)
+	events.HandleFunc("/{id}/invitation-links", h.CreateInvitationLink).Methods(http.MethodPost)
+	events.HandleFunc("/{id}/invitation-links", h.GetInvitationLinks).Methods(http.MethodGet)
+	events.HandleFunc("/{id}/invitation-links/{lid}", h.RevokeInvitationLink).Methods(http.MethodDelete)
 
 	// Availability
 	events.HandleFunc("/{id}/availability", h.SubmitAvailability).Methods(http.MethodPost)
+	events.HandleFunc("/{id}/availability/bulk", h.SubmitBulkAvailability).Methods(http.MethodPost)
+	events.HandleFunc("/{id}/availability/import", h.ImportAvailability).Methods(http.MethodPost)
+	events.HandleFunc("/{id}/participants/{pid}/availability/history", h.GetAvailabilityHistory).Methods(http.MethodGet)
 	events.HandleFunc("/{id}/recommendations", h.GetRecommendations).Methods(http.MethodGet)
 
+	// Notifications
+	events.HandleFunc("/{id}/reminders", h.SendReminders).Methods(http.MethodPost)
+	events.HandleFunc("/{id}/notifications", h.GetNotifications).Methods(http.MethodGet)
+
 	// Participant endpoints
 	participants := v1.PathPrefix("/participants").Subrouter()
-	participants.HandleFunc("", h.CreateParticipant).Methods(http.MethodPost)
+	participants.HandleFunc("", h.ListParticipants).Methods(http.MethodGet)
 	participants.HandleFunc("/{id}", h.GetParticipant).Methods(http.MethodGet)
+	participants.HandleFunc("/{id}/calendar.ics", h.ExportParticipantCalendar).Methods(http.MethodGet)
 }
 
 func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
@@ -98,28 +196,137 @@ func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	w.Write(response)
 }
 
+// log returns the handler's logger with the fields of the request ctx
+// belongs to, such as its ID
+func (h *Handler) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, h.Log)
+}
+
+// respondWithError responds with a problem whose detail is the message
 func respondWithError(w http.ResponseWriter, code int, message string) {
-	respondWithJSON(w, code, map[string]string{"error": message})
+	middleware.WriteProblem(w, middleware.NewProblem(code, message))
+}
+
+// respondWithFailure responds to an operation that failed with err. Errors
+// the repository returns for missing or conflicting records, or values it
+// rejects, are the client's to fix and reported with their messages. Other
+// errors are logged and reported without details, which could leak internals.
+func (h *Handler) respondWithFailure(w http.ResponseWriter, r *http.Request, err error, message string) {
+	var code int
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, repository.ErrConflict):
+		code = http.StatusConflict
+	case errors.Is(err, repository.ErrValidation):
+		code = http.StatusUnprocessableEntity
+	default:
+		h.log(r.Context()).Error(message, zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "")
+		return
+	}
+
+	h.log(r.Context()).Warn(message, zap.Error(err))
+	respondWithError(w, code, err.Error())
+}
+
+// viewerLocation returns the time zone the caller wants times shown in, taken
+// from the "tz" query parameter or the X-Time-Zone header. It returns nil when
+// neither is set.
+func viewerLocation(r *http.Request) (*time.Location, error) {
+	name := r.URL.Query().Get("tz")
+	if name == "" {
+		name = r.Header.Get("X-Time-Zone")
+	}
+	if name == "" {
+		return nil, nil
+	}
+	return time.LoadLocation(name)
+}
+
+// respondWithValidationErrors responds with a problem listing the invalid fields
+func respondWithValidationErrors(w http.ResponseWriter, errors middleware.ValidationErrors) {
+	problem := middleware.NewProblem(http.StatusBadRequest, "The request has invalid fields")
+	problem.Errors = errors
+	middleware.WriteProblem(w, problem)
 }
 
 // CreateEvent handles the creation of a new event
 func (h *Handler) CreateEvent(w http.ResponseWriter, r *http.Request) {
+	caller := currentParticipant(w, r)
+	if caller == nil {
+		return
+	}
+
 	var event models.Event
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&event); err != nil {
-		h.Log.Error("Failed to decode request body", zap.Error(err))
+		h.log(r.Context()).Error("Failed to decode request body", zap.Error(err))
 		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 	defer r.Body.Close()
 
-	if err := h.Repo.CreateEvent(&event); err != nil {
-		h.Log.Error("Failed to create event", zap.Error(err))
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+	// Participants create events as their own organizer
+	if event.OrganizerId == 0 {
+		event.OrganizerId = caller.ID
+	}
+	if event.OrganizerId != caller.ID {
+		h.forbid(w, r, caller.ID, 0, "Events can only be created with yourself as the organizer")
+		return
+	}
+
+	now := time.Now()
+	errs := validateEvent(event)
+	if event.PollDeadline != nil && !event.PollDeadline.After(now) {
+		errs = append(errs, middleware.ValidationError{Field: "poll_deadline", Message: "Poll deadline must be in the future"})
+	}
+	errs = append(errs, h.validateTimeSlots(&event, now)...)
+	// Invitees are added through InviteParticipant, which checks who may be
+	// invited and tells them
+	if len(event.Invitations) > 0 {
+		errs = append(errs, middleware.ValidationError{Field: "invitations", Message: "Invite participants after creating the event"})
+	}
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	h.defaultSeriesTimeZone(r.Context(), &event)
+
+	// Time slots are always new; an ID would make the database move an
+	// existing slot, possibly another event's, to this one
+	event.ID = 0
+	for i := range event.TimeSlots {
+		event.TimeSlots[i].ID = 0
+		event.TimeSlots[i].EventID = 0
+	}
+	event.Status = models.EventDraft
+	if len(event.TimeSlots) > 0 {
+		event.Status = models.EventPolling
+	}
+	event.ConfirmedTimeSlotID = nil
+	event.ConfirmedAt = nil
+	event.Exceptions = nil
+	event.RemindedAt = nil
+	if event.DecisionPolicy == "" {
+		event.DecisionPolicy = models.DecisionManual
+	}
+	event.DecisionOutcome = ""
+	event.DecisionReason = ""
+	event.DecidedAt = nil
+
+	err := h.Repo.Transaction(r.Context(), func(tx repository.Repository) error {
+		if err := tx.CreateEvent(r.Context(), &event); err != nil {
+			return err
+		}
+		return publish(r.Context(), tx, webhook.EventCreated, &event, event)
+	})
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to create event")
 		return
 	}
 
-	h.Log.Info("Event created successfully", zap.Uint("event_id", event.ID))
+	h.log(r.Context()).Info("Event created successfully", zap.Uint("event_id", event.ID))
 	respondWithJSON(w, http.StatusCreated, event)
 }
 
@@ -128,49 +335,151 @@ func (h *Handler) GetEvent(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.ParseUint(vars["id"], 10, 32)
 	if err != nil {
-		h.Log.Error("Invalid event ID", zap.Error(err))
+		h.log(r.Context()).Error("Invalid event ID", zap.Error(err))
 		respondWithError(w, http.StatusBadRequest, "Invalid event ID")
 		return
 	}
 
-	event, err := h.Repo.GetEvent(uint(id))
+	loc, err := viewerLocation(r)
 	if err != nil {
-		h.Log.Error("Failed to get event", zap.Error(err))
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithError(w, http.StatusBadRequest, "Invalid time zone")
 		return
 	}
 
-	h.Log.Info("Event retrieved successfully", zap.Uint("event_id", event.ID))
+	event, _ := h.authorizeEvent(w, r, uint(id), actionView)
+	if event == nil {
+		return
+	}
+	if loc != nil {
+		scheduling.Localize(event.TimeSlots, loc)
+	}
+	h.attachOccurrences(r.Context(), event, time.Now(), loc)
+
+	h.log(r.Context()).Info("Event retrieved successfully", zap.Uint("event_id", event.ID))
 	respondWithJSON(w, http.StatusOK, event)
 }
 
+// ListEvents handles listing the events the caller organizes or is invited
+// to, a page at a time
+func (h *Handler) ListEvents(w http.ResponseWriter, r *http.Request) {
+	caller := currentParticipant(w, r)
+	if caller == nil {
+		return
+	}
+
+	filter, errs := eventFilter(r, caller)
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	loc, err := viewerLocation(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid time zone")
+		return
+	}
+
+	events, next, err := h.Repo.ListEvents(r.Context(), filter)
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to list events")
+		return
+	}
+	if events == nil {
+		events = []models.Event{}
+	}
+	now := time.Now()
+	for i := range events {
+		if loc != nil {
+			scheduling.Localize(events[i].TimeSlots, loc)
+		}
+		h.attachOccurrences(r.Context(), &events[i], now, loc)
+	}
+
+	h.log(r.Context()).Info("Events listed successfully", zap.Int("count", len(events)))
+	respondWithJSON(w, http.StatusOK, listResponse{Data: events, NextCursor: next})
+}
+
 // UpdateEvent handles updating an existing event
 func (h *Handler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.ParseUint(vars["id"], 10, 32)
 	if err != nil {
-		h.Log.Error("Invalid event ID", zap.Error(err))
+		h.log(r.Context()).Error("Invalid event ID", zap.Error(err))
 		respondWithError(w, http.StatusBadRequest, "Invalid event ID")
 		return
 	}
 
-	var event models.Event
+	var update models.Event
 	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&event); err != nil {
-		h.Log.Error("Failed to decode request body", zap.Error(err))
+	if err := decoder.Decode(&update); err != nil {
+		h.log(r.Context()).Error("Failed to decode request body", zap.Error(err))
 		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 	defer r.Body.Close()
 
-	event.ID = uint(id)
-	if err := h.Repo.UpdateEvent(&event); err != nil {
-		h.Log.Error("Failed to update event", zap.Error(err))
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+	event, caller := h.authorizeEvent(w, r, uint(id), actionManage)
+	if event == nil {
+		return
+	}
+
+	errs := validateEvent(update)
+	deadlineChanged := !equalTimes(update.PollDeadline, event.PollDeadline)
+	if deadlineChanged && update.PollDeadline != nil && !update.PollDeadline.After(time.Now()) {
+		errs = append(errs, middleware.ValidationError{Field: "poll_deadline", Message: "Poll deadline must be in the future"})
+	}
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+	seriesChanged := update.Recurrence != event.Recurrence ||
+		(update.TimeZone != "" && update.TimeZone != event.TimeZone)
+	if seriesChanged && event.Status == models.EventConfirmed {
+		respondWithError(w, http.StatusConflict, "The recurrence of a confirmed event cannot be changed")
 		return
 	}
 
-	h.Log.Info("Event updated successfully", zap.Uint("event_id", event.ID))
+	if update.OrganizerId != 0 && update.OrganizerId != event.OrganizerId {
+		if !can(event, caller.ID, actionManageOrganizers) {
+			h.forbid(w, r, caller.ID, event.ID, eventPolicy[actionManageOrganizers].denied)
+			return
+		}
+		event.OrganizerId = update.OrganizerId
+	}
+
+	// The lifecycle is changed through its own endpoints, not by updates
+	event.Title = update.Title
+	event.Description = update.Description
+	event.Duration = update.Duration
+	event.Recurrence = update.Recurrence
+	event.PollDeadline = update.PollDeadline
+	if update.DecisionPolicy != "" {
+		event.DecisionPolicy = update.DecisionPolicy
+	}
+	if deadlineChanged {
+		// A new deadline earns a new reminder
+		event.RemindedAt = nil
+	}
+	if update.TimeZone != "" {
+		event.TimeZone = update.TimeZone
+	}
+	h.defaultSeriesTimeZone(r.Context(), event)
+	err = h.Repo.Transaction(r.Context(), func(tx repository.Repository) error {
+		if err := tx.UpdateEvent(r.Context(), event); err != nil {
+			return err
+		}
+		if deadlineChanged {
+			if err := reopenPoll(r.Context(), tx, event); err != nil {
+				return err
+			}
+		}
+		return publish(r.Context(), tx, webhook.EventUpdated, event, event)
+	})
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to update event")
+		return
+	}
+
+	h.log(r.Context()).Info("Event updated successfully", zap.Uint("event_id", event.ID))
 	respondWithJSON(w, http.StatusOK, event)
 }
 
@@ -179,27 +488,199 @@ func (h *Handler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.ParseUint(vars["id"], 10, 32)
 	if err != nil {
-		h.Log.Error("Invalid event ID", zap.Error(err))
+		h.log(r.Context()).Error("Invalid event ID", zap.Error(err))
 		respondWithError(w, http.StatusBadRequest, "Invalid event ID")
 		return
 	}
 
-	if err := h.Repo.DeleteEvent(uint(id)); err != nil {
-		h.Log.Error("Failed to delete event", zap.Error(err))
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+	event, _ := h.authorizeEvent(w, r, uint(id), actionDelete)
+	if event == nil {
 		return
 	}
 
-	h.Log.Info("Event deleted successfully", zap.Uint64("event_id", id))
+	err = h.Repo.Transaction(r.Context(), func(tx repository.Repository) error {
+		if err := tx.DeleteEvent(r.Context(), event.ID); err != nil {
+			return err
+		}
+		return publish(r.Context(), tx, webhook.EventDeleted, event, event)
+	})
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to delete event")
+		return
+	}
+
+	h.log(r.Context()).Info("Event deleted successfully", zap.Uint64("event_id", id))
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// ConfirmEvent handles locking in one of an event's time slots. The event
+// stops accepting availability once confirmed.
+func (h *Handler) ConfirmEvent(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		h.log(r.Context()).Error("Invalid event ID", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid event ID")
+		return
+	}
+
+	var req models.ConfirmEventRequest
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&req); err != nil {
+		h.log(r.Context()).Error("Failed to decode request body", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if errs := middleware.ValidateStruct(req); len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+
+	event, _ := h.authorizeEvent(w, r, uint(id), actionManage)
+	if event == nil {
+		return
+	}
+	if event.Decided() {
+		respondWithError(w, http.StatusConflict, "Event is already "+string(event.Status))
+		return
+	}
+
+	var slot *models.TimeSlot
+	for i := range event.TimeSlots {
+		if event.TimeSlots[i].ID == req.TimeSlotID {
+			slot = &event.TimeSlots[i]
+		}
+	}
+	if slot == nil {
+		respondWithValidationErrors(w, middleware.ValidationErrors{{
+			Field:   "time_slot_id",
+			Message: "Time slot does not belong to this event",
+		}})
+		return
+	}
+
+	event.Confirm(*slot, time.Now().UTC())
+	err = h.Repo.Transaction(r.Context(), func(tx repository.Repository) error {
+		if err := transition(r.Context(), tx, event, undecidedStatuses...); err != nil {
+			return err
+		}
+		if err := h.Notifications.QueueConfirmed(r.Context(), tx, event); err != nil {
+			return err
+		}
+		return publish(r.Context(), tx, webhook.EventConfirmed, event, event)
+	})
+	if errors.Is(err, errStatusChanged) {
+		respondWithError(w, http.StatusConflict, "Event was confirmed or cancelled by another request")
+		return
+	}
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to confirm event")
+		return
+	}
+
+	h.log(r.Context()).Info("Event confirmed successfully",
+		zap.Uint("event_id", event.ID),
+		zap.Uint("timeslot_id", slot.ID))
+	respondWithJSON(w, http.StatusOK, event)
+}
+
+// CancelEvent handles cancelling an event
+func (h *Handler) CancelEvent(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		h.log(r.Context()).Error("Invalid event ID", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid event ID")
+		return
+	}
+
+	event, _ := h.authorizeEvent(w, r, uint(id), actionManage)
+	if event == nil {
+		return
+	}
+	if event.Status == models.EventCancelled {
+		respondWithError(w, http.StatusConflict, "Event is already cancelled")
+		return
+	}
+
+	event.Status = models.EventCancelled
+	err = h.Repo.Transaction(r.Context(), func(tx repository.Repository) error {
+		if err := transition(r.Context(), tx, event, cancellableStatuses...); err != nil {
+			return err
+		}
+		return publish(r.Context(), tx, webhook.EventCancelled, event, event)
+	})
+	if errors.Is(err, errStatusChanged) {
+		respondWithError(w, http.StatusConflict, "Event is already cancelled")
+		return
+	}
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to cancel event")
+		return
+	}
+
+	h.log(r.Context()).Info("Event cancelled successfully", zap.Uint("event_id", event.ID))
+	respondWithJSON(w, http.StatusOK, event)
+}
+
+// Statuses an event can be confirmed and cancelled from
+var (
+	undecidedStatuses   = []models.EventStatus{models.EventDraft, models.EventPolling, models.EventClosed}
+	cancellableStatuses = []models.EventStatus{models.EventDraft, models.EventPolling, models.EventClosed, models.EventConfirmed}
+)
+
+// errStatusChanged aborts a change to an event's lifecycle when another
+// request changed its status after it was loaded
+var errStatusChanged = errors.New("event status changed")
+
+// transition stores the lifecycle of an event if its status is still one of
+// from, and returns errStatusChanged if it is not
+func transition(ctx context.Context, tx repository.Repository, event *models.Event, from ...models.EventStatus) error {
+	changed, err := tx.TransitionEvent(ctx, event, from...)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return errStatusChanged
+	}
+	return nil
+}
+
+// reopenPoll puts a closed event back into polling, to be decided again at
+// its new deadline. The event may have been closed after it was loaded, so
+// the status is checked when it is stored, not beforehand.
+func reopenPoll(ctx context.Context, tx repository.Repository, event *models.Event) error {
+	reopened := *event
+	reopened.Status = models.EventPolling
+	reopened.DecisionOutcome = ""
+	reopened.DecisionReason = ""
+	reopened.DecidedAt = nil
+	changed, err := tx.TransitionEvent(ctx, &reopened, models.EventClosed)
+	if changed {
+		*event = reopened
+	}
+	return err
+}
+
+// openPolling moves a draft event into polling once it has time slots
+func (h *Handler) openPolling(ctx context.Context, event *models.Event) {
+	if event.Status != models.EventDraft {
+		return
+	}
+	event.Status = models.EventPolling
+	if _, err := h.Repo.TransitionEvent(ctx, event, models.EventDraft); err != nil {
+		h.log(ctx).Error("Failed to open polling", zap.Error(err), zap.Uint("event_id", event.ID))
+	}
+}
+
 // AddTimeSlot handles adding a time slot to an event
 func (h *Handler) AddTimeSlot(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	eventID, err := strconv.ParseUint(vars["id"], 10, 32)
 	if err != nil {
-		h.Log.Error("Invalid event ID", zap.Error(err))
+		h.log(r.Context()).Error("Invalid event ID", zap.Error(err))
 		respondWithError(w, http.StatusBadRequest, "Invalid event ID")
 		return
 	}
@@ -207,44 +688,287 @@ func (h *Handler) AddTimeSlot(w http.ResponseWriter, r *http.Request) {
 	var timeSlot models.TimeSlot
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&timeSlot); err != nil {
-		h.Log.Error("Failed to decode request body", zap.Error(err))
+		h.log(r.Context()).Error("Failed to decode request body", zap.Error(err))
 		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 	defer r.Body.Close()
 
-	timeSlot.EventID = uint(eventID)
-	if err := h.Repo.CreateTimeSlot(&timeSlot); err != nil {
-		h.Log.Error("Failed to create time slot", zap.Error(err))
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+	event, _ := h.authorizeEvent(w, r, uint(eventID), actionManage)
+	if event == nil {
+		return
+	}
+	if !event.AcceptsAvailability() {
+		respondWithError(w, http.StatusConflict, "Event is "+string(event.Status))
+		return
+	}
+
+	if errs := h.validateTimeSlot(timeSlot, event, nil, time.Now()); len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
 		return
 	}
 
-	h.Log.Info("Time slot created successfully",
+	timeSlot.ID = 0
+	timeSlot.EventID = event.ID
+	if err := h.Repo.CreateTimeSlot(r.Context(), &timeSlot); err != nil {
+		h.respondWithFailure(w, r, err, "Failed to create time slot")
+		return
+	}
+	h.openPolling(r.Context(), event)
+
+	h.log(r.Context()).Info("Time slot created successfully",
 		zap.Uint("event_id", timeSlot.EventID),
 		zap.Uint("timeslot_id", timeSlot.ID))
 	respondWithJSON(w, http.StatusCreated, timeSlot)
 }
 
+// GenerateTimeSlots handles creating candidate time slots for an event from a
+// date range and working-hours windows. Candidates that overlap an existing
+// slot of the event or lie outside the scheduling window are skipped.
+func (h *Handler) GenerateTimeSlots(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	eventID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		h.log(r.Context()).Error("Invalid event ID", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid event ID")
+		return
+	}
+
+	var req models.TimeSlotGenerationRequest
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&req); err != nil {
+		h.log(r.Context()).Error("Failed to decode request body", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if errs := middleware.ValidateStruct(req); len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+
+	event, _ := h.authorizeEvent(w, r, uint(eventID), actionManage)
+	if event == nil {
+		return
+	}
+
+	if !event.AcceptsAvailability() {
+		respondWithError(w, http.StatusConflict, "Event is "+string(event.Status))
+		return
+	}
+
+	generated, err := scheduling.GenerateSlots(req, time.Duration(event.Duration)*time.Minute)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	now := time.Now()
+	candidates := make([]models.TimeSlot, 0, len(generated))
+	for _, slot := range generated {
+		if h.checkSchedulingWindow(slot.StartTime, now) == "" {
+			candidates = append(candidates, slot)
+		}
+	}
+
+	created, err := h.Repo.CreateTimeSlots(r.Context(), event.ID, candidates)
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to create time slots")
+		return
+	}
+	if len(created) > 0 {
+		h.openPolling(r.Context(), event)
+	}
+
+	result := models.TimeSlotGenerationResult{
+		Created: created,
+		Skipped: len(generated) - len(created),
+	}
+	if result.Created == nil {
+		result.Created = []models.TimeSlot{}
+	}
+
+	h.log(r.Context()).Info("Time slots generated successfully",
+		zap.Uint("event_id", event.ID),
+		zap.Int("created", len(result.Created)),
+		zap.Int("skipped", result.Skipped))
+	respondWithJSON(w, http.StatusCreated, result)
+}
+
 // GetTimeSlots handles retrieving all time slots for an event
 func (h *Handler) GetTimeSlots(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	eventID, err := strconv.ParseUint(vars["id"], 10, 32)
 	if err != nil {
-		h.Log.Error("Invalid event ID", zap.Error(err))
+		h.log(r.Context()).Error("Invalid event ID", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid event ID")
+		return
+	}
+
+	if event, _ := h.authorizeEvent(w, r, uint(eventID), actionView); event == nil {
+		return
+	}
+
+	loc, err := viewerLocation(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid time zone")
+		return
+	}
+
+	opts, errs := listOptions(r)
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+
+	timeSlots, next, err := h.Repo.ListTimeSlots(r.Context(), uint(eventID), opts)
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to get time slots")
+		return
+	}
+	if timeSlots == nil {
+		timeSlots = []models.TimeSlot{}
+	}
+	if loc != nil {
+		scheduling.Localize(timeSlots, loc)
+	}
+
+	h.log(r.Context()).Info("Time slots retrieved successfully", zap.Uint64("event_id", eventID))
+	respondWithJSON(w, http.StatusOK, listResponse{Data: timeSlots, NextCursor: next})
+}
+
+// InviteParticipant handles inviting a participant to an event as a required
+// or optional attendee
+func (h *Handler) InviteParticipant(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	eventID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		h.log(r.Context()).Error("Invalid event ID", zap.Error(err))
 		respondWithError(w, http.StatusBadRequest, "Invalid event ID")
 		return
 	}
 
-	timeSlots, err := h.Repo.GetTimeSlots(uint(eventID))
+	var invitation models.EventInvitation
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&invitation); err != nil {
+		h.log(r.Context()).Error("Failed to decode request body", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if errs := middleware.ValidateStruct(invitation); len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+
+	event, caller := h.authorizeEvent(w, r, uint(eventID), actionManage)
+	if event == nil {
+		return
+	}
+	// Only the organizer appoints or demotes co-organizers
+	if (invitation.CoOrganizer || eventRelation(event, invitation.ParticipantID) == relationCoOrganizer) &&
+		!can(event, caller.ID, actionManageOrganizers) {
+		h.forbid(w, r, caller.ID, event.ID, eventPolicy[actionManageOrganizers].denied)
+		return
+	}
+
+	participant, err := h.Repo.GetParticipant(r.Context(), invitation.ParticipantID)
+	if err != nil {
+		h.log(r.Context()).Error("Failed to get participant", zap.Error(err))
+		respondWithValidationErrors(w, middleware.ValidationErrors{{
+			Field:   "participant_id",
+			Message: "Participant not found",
+		}})
+		return
+	}
+
+	invitation.ID = 0
+	invitation.EventID = event.ID
+	alreadyInvited := eventRelation(event, invitation.ParticipantID) != relationNone
+	err = h.Repo.Transaction(r.Context(), func(tx repository.Repository) error {
+		if err := tx.InviteParticipant(r.Context(), &invitation); err != nil {
+			return err
+		}
+		// Changing an invitation's role does not invite anyone again
+		if alreadyInvited {
+			return nil
+		}
+		return h.Notifications.QueueInvited(r.Context(), tx, event, participant)
+	})
 	if err != nil {
-		h.Log.Error("Failed to get time slots", zap.Error(err))
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		h.respondWithFailure(w, r, err, "Failed to invite participant")
 		return
 	}
+	invitation.Participant = participant
 
-	h.Log.Info("Time slots retrieved successfully", zap.Uint64("event_id", eventID))
-	respondWithJSON(w, http.StatusOK, timeSlots)
+	h.log(r.Context()).Info("Participant invited successfully",
+		zap.Uint("event_id", invitation.EventID),
+		zap.Uint("participant_id", invitation.ParticipantID),
+		zap.String("role", string(invitation.Role)))
+	respondWithJSON(w, http.StatusCreated, invitation)
+}
+
+// GetInvitations handles retrieving the invitees of an event
+func (h *Handler) GetInvitations(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	eventID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		h.log(r.Context()).Error("Invalid event ID", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid event ID")
+		return
+	}
+
+	if event, _ := h.authorizeEvent(w, r, uint(eventID), actionView); event == nil {
+		return
+	}
+
+	invitations, err := h.Repo.GetInvitations(r.Context(), uint(eventID))
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to get invitations")
+		return
+	}
+
+	h.log(r.Context()).Info("Invitations retrieved successfully", zap.Uint64("event_id", eventID))
+	respondWithJSON(w, http.StatusOK, invitations)
+}
+
+// RemoveInvitation handles withdrawing a participant's invitation to an event
+func (h *Handler) RemoveInvitation(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	eventID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		h.log(r.Context()).Error("Invalid event ID", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid event ID")
+		return
+	}
+
+	participantID, err := strconv.ParseUint(vars["pid"], 10, 32)
+	if err != nil {
+		h.log(r.Context()).Error("Invalid participant ID", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid participant ID")
+		return
+	}
+
+	event, caller := h.authorizeEvent(w, r, uint(eventID), actionManage)
+	if event == nil {
+		return
+	}
+	if eventRelation(event, uint(participantID)) == relationCoOrganizer && !can(event, caller.ID, actionManageOrganizers) {
+		h.forbid(w, r, caller.ID, event.ID, eventPolicy[actionManageOrganizers].denied)
+		return
+	}
+
+	if err := h.Repo.RemoveInvitation(r.Context(), uint(eventID), uint(participantID)); err != nil {
+		h.respondWithFailure(w, r, err, "Failed to remove invitation")
+		return
+	}
+
+	h.log(r.Context()).Info("Invitation removed successfully",
+		zap.Uint64("event_id", eventID),
+		zap.Uint64("participant_id", participantID))
+	w.WriteHeader(http.StatusNoContent)
 }
 
 // SubmitAvailability handles submitting availability for a participant
@@ -252,7 +976,7 @@ func (h *Handler) SubmitAvailability(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	eventID, err := strconv.ParseUint(vars["id"], 10, 32)
 	if err != nil {
-		h.Log.Error("Invalid event ID", zap.Error(err))
+		h.log(r.Context()).Error("Invalid event ID", zap.Error(err))
 		respondWithError(w, http.StatusBadRequest, "Invalid event ID")
 		return
 	}
@@ -260,64 +984,318 @@ func (h *Handler) SubmitAvailability(w http.ResponseWriter, r *http.Request) {
 	var availability models.Availability
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&availability); err != nil {
-		h.Log.Error("Failed to decode request body", zap.Error(err))
+		h.log(r.Context()).Error("Failed to decode request body", zap.Error(err))
 		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 	defer r.Body.Close()
 
-	availability.ID = uint(eventID)
-	if err := h.Repo.CreateAvailability(&availability); err != nil {
-		h.Log.Error("Failed to create availability", zap.Error(err))
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+	// Participants answer for themselves unless they say otherwise
+	if availability.ParticipantID == 0 {
+		if caller, ok := middleware.ParticipantFromContext(r.Context()); ok {
+			availability.ParticipantID = caller.ID
+		}
+	}
+
+	if errs := middleware.ValidateStruct(availability); len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
 		return
 	}
 
-	h.Log.Info("Availability submitted successfully",
-		zap.Uint("event_id", availability.ID),
+	event := h.authorizeAnswer(w, r, uint(eventID), availability.ParticipantID)
+	if event == nil {
+		return
+	}
+	if !event.AcceptsAvailability() {
+		respondWithError(w, http.StatusConflict, "Event is "+string(event.Status)+" and no longer accepts availability")
+		return
+	}
+	if !containsTimeSlot(event.TimeSlots, availability.TimeSlotID) {
+		respondWithValidationErrors(w, middleware.ValidationErrors{{
+			Field:   "time_slot_id",
+			Message: "Time slot does not belong to this event",
+		}})
+		return
+	}
+
+	availability.ID = 0
+	availability.Normalize()
+	err = h.Repo.Transaction(r.Context(), func(tx repository.Repository) error {
+		if err := tx.UpsertAvailability(r.Context(), event.ID, &availability); err != nil {
+			return err
+		}
+		return publish(r.Context(), tx, webhook.AvailabilitySubmitted, event, webhook.Availability{
+			EventID:        event.ID,
+			ParticipantID:  availability.ParticipantID,
+			Availabilities: []models.Availability{availability},
+		})
+	})
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to save availability")
+		return
+	}
+
+	h.log(r.Context()).Info("Availability submitted successfully",
+		zap.Uint64("event_id", eventID),
 		zap.Uint("participant_id", availability.ParticipantID))
 	respondWithJSON(w, http.StatusCreated, availability)
 }
 
-// GetRecommendations handles retrieving time slot recommendations for an event
+// containsTimeSlot reports whether a time slot with the given ID is in the list
+func containsTimeSlot(timeSlots []models.TimeSlot, id uint) bool {
+	for _, slot := range timeSlots {
+		if slot.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// SubmitBulkAvailability handles submitting a participant's availability for
+// several time slots of an event at once. Either all answers are stored or none.
+func (h *Handler) SubmitBulkAvailability(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	eventID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		h.log(r.Context()).Error("Invalid event ID", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid event ID")
+		return
+	}
+
+	var req models.BulkAvailabilityRequest
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&req); err != nil {
+		h.log(r.Context()).Error("Failed to decode request body", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	// Participants answer for themselves unless they say otherwise
+	if req.ParticipantID == 0 {
+		if caller, ok := middleware.ParticipantFromContext(r.Context()); ok {
+			req.ParticipantID = caller.ID
+		}
+	}
+
+	if errs := middleware.ValidateStruct(req); len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+
+	event := h.authorizeAnswer(w, r, uint(eventID), req.ParticipantID)
+	if event == nil {
+		return
+	}
+
+	if _, err := h.Repo.GetParticipant(r.Context(), req.ParticipantID); err != nil {
+		h.log(r.Context()).Error("Failed to get participant", zap.Error(err))
+		respondWithValidationErrors(w, middleware.ValidationErrors{{
+			Field:   "participant_id",
+			Message: "Participant not found",
+		}})
+		return
+	}
+
+	availabilities, ok := h.saveAnswers(w, r, event, req.ParticipantID, req.Availabilities)
+	if !ok {
+		return
+	}
+
+	h.log(r.Context()).Info("Bulk availability submitted successfully",
+		zap.Uint64("event_id", eventID),
+		zap.Uint("participant_id", req.ParticipantID),
+		zap.Int("count", len(availabilities)))
+	respondWithJSON(w, http.StatusCreated, availabilities)
+}
+
+// saveAnswers checks and stores a participant's answers for several time slots
+// of an event at once. It responds with an error and returns false when the
+// answers cannot be stored.
+func (h *Handler) saveAnswers(w http.ResponseWriter, r *http.Request, event *models.Event, participantID uint, answers []models.SlotAvailability) ([]models.Availability, bool) {
+	if !event.AcceptsAvailability() {
+		respondWithError(w, http.StatusConflict, "Event is "+string(event.Status)+" and no longer accepts availability")
+		return nil, false
+	}
+
+	eventSlots := make(map[uint]bool, len(event.TimeSlots))
+	for _, slot := range event.TimeSlots {
+		eventSlots[slot.ID] = true
+	}
+
+	var errs middleware.ValidationErrors
+	seen := make(map[uint]bool, len(answers))
+	availabilities := make([]models.Availability, 0, len(answers))
+	for i, answer := range answers {
+		field := fmt.Sprintf("availabilities[%d].time_slot_id", i)
+		switch {
+		case !eventSlots[answer.TimeSlotID]:
+			errs = append(errs, middleware.ValidationError{Field: field, Message: "Time slot does not belong to this event"})
+		case seen[answer.TimeSlotID]:
+			errs = append(errs, middleware.ValidationError{Field: field, Message: "Duplicate time slot"})
+		}
+		seen[answer.TimeSlotID] = true
+
+		availability := models.Availability{
+			ParticipantID: participantID,
+			TimeSlotID:    answer.TimeSlotID,
+			Status:        answer.Status,
+			Weight:        answer.Weight,
+			IsAvailable:   answer.IsAvailable,
+		}
+		availability.Normalize()
+		availabilities = append(availabilities, availability)
+	}
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return nil, false
+	}
+
+	err := h.Repo.Transaction(r.Context(), func(tx repository.Repository) error {
+		if err := tx.UpsertAvailabilities(r.Context(), event.ID, availabilities); err != nil {
+			return err
+		}
+		return publish(r.Context(), tx, webhook.AvailabilitySubmitted, event, webhook.Availability{
+			EventID:        event.ID,
+			ParticipantID:  participantID,
+			Availabilities: availabilities,
+		})
+	})
+	if errors.Is(err, repository.ErrValidation) {
+		// A time slot was removed from the event since it was loaded
+		h.log(r.Context()).Warn("Failed to save availabilities", zap.Error(err))
+		respondWithValidationErrors(w, middleware.ValidationErrors{{Field: "availabilities", Message: err.Error()}})
+		return nil, false
+	}
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to save availabilities")
+		return nil, false
+	}
+	return availabilities, true
+}
+
+// GetAvailabilityHistory handles retrieving a participant's superseded answers for an event
+func (h *Handler) GetAvailabilityHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	eventID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		h.log(r.Context()).Error("Invalid event ID", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid event ID")
+		return
+	}
+
+	participantID, err := strconv.ParseUint(vars["pid"], 10, 32)
+	if err != nil {
+		h.log(r.Context()).Error("Invalid participant ID", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid participant ID")
+		return
+	}
+
+	if event, _ := h.authorizeEvent(w, r, uint(eventID), actionView); event == nil {
+		return
+	}
+
+	history, err := h.Repo.GetAvailabilityHistory(r.Context(), uint(eventID), uint(participantID))
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to get availability history")
+		return
+	}
+
+	h.log(r.Context()).Info("Availability history retrieved successfully",
+		zap.Uint64("event_id", eventID),
+		zap.Uint64("participant_id", participantID))
+	respondWithJSON(w, http.StatusOK, history)
+}
+
+// GetRecommendations handles retrieving time slot recommendations for an event,
+// best scoring slot first
 func (h *Handler) GetRecommendations(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	eventID, err := strconv.ParseUint(vars["id"], 10, 32)
 	if err != nil {
-		h.Log.Error("Invalid event ID", zap.Error(err))
+		h.log(r.Context()).Error("Invalid event ID", zap.Error(err))
 		respondWithError(w, http.StatusBadRequest, "Invalid event ID")
 		return
 	}
 
-	recommendations, err := h.Repo.GetTimeSlotRecommendations(uint(eventID))
+	if event, _ := h.authorizeEvent(w, r, uint(eventID), actionView); event == nil {
+		return
+	}
+
+	loc, err := viewerLocation(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid time zone")
+		return
+	}
+
+	opts, errs := listOptions(r)
+	if opts.Sort != "" {
+		errs = append(errs, middleware.ValidationError{Field: "sort", Message: "Recommendations are always ranked by score"})
+	}
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+
+	ranked, err := h.Repo.GetTimeSlotRecommendations(r.Context(), uint(eventID))
 	if err != nil {
-		h.Log.Error("Failed to get recommendations", zap.Error(err))
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		h.respondWithFailure(w, r, err, "Failed to get recommendations")
 		return
 	}
 
-	h.Log.Info("Recommendations retrieved successfully", zap.Uint64("event_id", eventID))
-	respondWithJSON(w, http.StatusOK, recommendations)
+	// Recommendations are ranked across all time slots, so the page is cut
+	// from the full ranking
+	recommendations, next, ok := offsetPage(ranked, opts)
+	if !ok {
+		respondWithValidationErrors(w, middleware.ValidationErrors{{Field: "cursor", Message: "The cursor is invalid"}})
+		return
+	}
+	scheduling.FlagOutsideWorkingHours(recommendations, h.WorkingHours)
+	if loc != nil {
+		for i := range recommendations {
+			scheduling.LocalizeSlot(&recommendations[i].TimeSlot, loc)
+		}
+	}
+
+	h.log(r.Context()).Info("Recommendations retrieved successfully", zap.Uint64("event_id", eventID))
+	respondWithJSON(w, http.StatusOK, listResponse{Data: recommendations, NextCursor: next})
 }
 
-// CreateParticipant handles creating a new participant
+// CreateParticipant handles creating a new participant. The response carries
+// the participant's first API key, which is not shown again.
 func (h *Handler) CreateParticipant(w http.ResponseWriter, r *http.Request) {
 	var participant models.Participant
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&participant); err != nil {
-		h.Log.Error("Failed to decode request body", zap.Error(err))
+		h.log(r.Context()).Error("Failed to decode request body", zap.Error(err))
 		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 	defer r.Body.Close()
 
-	if err := h.Repo.CreateParticipant(&participant); err != nil {
-		h.Log.Error("Failed to create participant", zap.Error(err))
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+	// Emails identify accounts whatever their case; guests are only created
+	// through invitation links
+	participant.Email = strings.ToLower(strings.TrimSpace(participant.Email))
+	participant.Guest = false
+	if errs := middleware.ValidateStruct(participant); len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+
+	if err := h.Repo.CreateParticipant(r.Context(), &participant); err != nil {
+		h.respondWithFailure(w, r, err, "Failed to create participant")
+		return
+	}
+
+	// The first API key lets the new participant authenticate
+	key, err := h.createAPIKey(r.Context(), participant.ID, "default", nil)
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to create API key")
 		return
 	}
+	participant.APIKey = key.Key
 
-	h.Log.Info("Participant created successfully", zap.Uint("participant_id", participant.ID))
+	h.log(r.Context()).Info("Participant created successfully", zap.Uint("participant_id", participant.ID))
 	respondWithJSON(w, http.StatusCreated, participant)
 }
 
@@ -326,18 +1304,61 @@ func (h *Handler) GetParticipant(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.ParseUint(vars["id"], 10, 32)
 	if err != nil {
-		h.Log.Error("Invalid participant ID", zap.Error(err))
+		h.log(r.Context()).Error("Invalid participant ID", zap.Error(err))
 		respondWithError(w, http.StatusBadRequest, "Invalid participant ID")
 		return
 	}
 
-	participant, err := h.Repo.GetParticipant(uint(id))
+	caller := currentParticipant(w, r)
+	if caller == nil {
+		return
+	}
+
+	// Callers see the participants they could list, so IDs cannot be
+	// counted up to read the whole directory
+	participants, _, err := h.Repo.ListParticipants(r.Context(), repository.ParticipantFilter{
+		ListOptions: repository.ListOptions{Limit: 1},
+		VisibleTo:   caller.ID,
+		ID:          uint(id),
+	})
 	if err != nil {
-		h.Log.Error("Failed to get participant", zap.Error(err))
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		h.respondWithFailure(w, r, err, "Failed to get participant")
+		return
+	}
+	if len(participants) == 0 {
+		respondWithError(w, http.StatusNotFound, "Participant not found")
 		return
 	}
+	participant := participants[0]
 
-	h.Log.Info("Participant retrieved successfully", zap.Uint("participant_id", participant.ID))
+	h.log(r.Context()).Info("Participant retrieved successfully", zap.Uint("participant_id", participant.ID))
 	respondWithJSON(w, http.StatusOK, participant)
 }
+
+// ListParticipants handles listing participants a page at a time, so
+// organizers can find the people to invite. Callers see the participants
+// they share an event with, and others only by their exact email.
+func (h *Handler) ListParticipants(w http.ResponseWriter, r *http.Request) {
+	caller := currentParticipant(w, r)
+	if caller == nil {
+		return
+	}
+
+	filter, errs := participantFilter(r, caller)
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+
+	participants, next, err := h.Repo.ListParticipants(r.Context(), filter)
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to list participants")
+		return
+	}
+	if participants == nil {
+		participants = []models.Participant{}
+	}
+
+	h.log(r.Context()).Info("Participants listed successfully", zap.Int("count", len(participants)))
+	respondWithJSON(w, http.StatusOK, listResponse{Data: participants, NextCursor: next})
+}
@@ -2,17 +2,27 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tusharsingune/meeting-scheduler/internal/config"
+	"github.com/tusharsingune/meeting-scheduler/internal/middleware"
 	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"github.com/tusharsingune/meeting-scheduler/internal/notify"
+	"github.com/tusharsingune/meeting-scheduler/internal/repository"
+	"github.com/tusharsingune/meeting-scheduler/internal/scheduling"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 // MockRepository is a mock implementation of the Repository interface
@@ -20,12 +30,18 @@ type MockRepository struct {
 	mock.Mock
 }
 
-func (m *MockRepository) CreateEvent(event *models.Event) error {
+// Transaction runs fn against the mock itself, so calls made inside it are
+// recorded like any other
+func (m *MockRepository) Transaction(ctx context.Context, fn func(repository.Repository) error) error {
+	return fn(m)
+}
+
+func (m *MockRepository) CreateEvent(ctx context.Context, event *models.Event) error {
 	args := m.Called(event)
 	return args.Error(0)
 }
 
-func (m *MockRepository) GetEvent(id uint) (*models.Event, error) {
+func (m *MockRepository) GetEvent(ctx context.Context, id uint) (*models.Event, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -33,22 +49,61 @@ func (m *MockRepository) GetEvent(id uint) (*models.Event, error) {
 	return args.Get(0).(*models.Event), args.Error(1)
 }
 
-func (m *MockRepository) UpdateEvent(event *models.Event) error {
+func (m *MockRepository) UpdateEvent(ctx context.Context, event *models.Event) error {
 	args := m.Called(event)
 	return args.Error(0)
 }
 
-func (m *MockRepository) DeleteEvent(id uint) error {
+func (m *MockRepository) TransitionEvent(ctx context.Context, event *models.Event, from ...models.EventStatus) (bool, error) {
+	args := m.Called(event, from)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) DeleteEvent(ctx context.Context, id uint) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
-func (m *MockRepository) CreateTimeSlot(slot *models.TimeSlot) error {
+func (m *MockRepository) GetParticipantEvents(ctx context.Context, participantID uint) ([]models.Event, error) {
+	args := m.Called(participantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Event), args.Error(1)
+}
+
+func (m *MockRepository) ListEvents(ctx context.Context, filter repository.EventFilter) ([]models.Event, string, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).([]models.Event), args.String(1), args.Error(2)
+}
+
+func (m *MockRepository) UpsertOccurrenceException(ctx context.Context, exception *models.OccurrenceException) error {
+	args := m.Called(exception)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeleteOccurrenceException(ctx context.Context, eventID, exceptionID uint) error {
+	args := m.Called(eventID, exceptionID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CreateTimeSlot(ctx context.Context, slot *models.TimeSlot) error {
 	args := m.Called(slot)
 	return args.Error(0)
 }
 
-func (m *MockRepository) GetTimeSlots(eventID uint) ([]models.TimeSlot, error) {
+func (m *MockRepository) CreateTimeSlots(ctx context.Context, eventID uint, slots []models.TimeSlot) ([]models.TimeSlot, error) {
+	args := m.Called(eventID, slots)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.TimeSlot), args.Error(1)
+}
+
+func (m *MockRepository) GetTimeSlots(ctx context.Context, eventID uint) ([]models.TimeSlot, error) {
 	args := m.Called(eventID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -56,12 +111,93 @@ func (m *MockRepository) GetTimeSlots(eventID uint) ([]models.TimeSlot, error) {
 	return args.Get(0).([]models.TimeSlot), args.Error(1)
 }
 
-func (m *MockRepository) CreateAvailability(availability *models.Availability) error {
+func (m *MockRepository) ListTimeSlots(ctx context.Context, eventID uint, opts repository.ListOptions) ([]models.TimeSlot, string, error) {
+	args := m.Called(eventID, opts)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).([]models.TimeSlot), args.String(1), args.Error(2)
+}
+
+func (m *MockRepository) InviteParticipant(ctx context.Context, invitation *models.EventInvitation) error {
+	args := m.Called(invitation)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetInvitations(ctx context.Context, eventID uint) ([]models.EventInvitation, error) {
+	args := m.Called(eventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.EventInvitation), args.Error(1)
+}
+
+func (m *MockRepository) RemoveInvitation(ctx context.Context, eventID, participantID uint) error {
+	args := m.Called(eventID, participantID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CreateInvitationLink(ctx context.Context, link *models.InvitationLink) error {
+	args := m.Called(link)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetInvitationLinkByHash(ctx context.Context, hash string) (*models.InvitationLink, error) {
+	args := m.Called(hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.InvitationLink), args.Error(1)
+}
+
+func (m *MockRepository) GetInvitationLinks(ctx context.Context, eventID uint) ([]models.InvitationLink, error) {
+	args := m.Called(eventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.InvitationLink), args.Error(1)
+}
+
+func (m *MockRepository) RevokeInvitationLink(ctx context.Context, eventID, linkID uint) error {
+	args := m.Called(eventID, linkID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ClaimInvitationLink(ctx context.Context, link *models.InvitationLink) (*models.Participant, error) {
+	args := m.Called(link)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Participant), args.Error(1)
+}
+
+func (m *MockRepository) UpsertAvailability(ctx context.Context, eventID uint, availability *models.Availability) error {
 	args := m.Called(availability)
 	return args.Error(0)
 }
 
-func (m *MockRepository) GetTimeSlotRecommendations(eventID uint) ([]models.TimeSlotRecommendation, error) {
+func (m *MockRepository) UpsertAvailabilities(ctx context.Context, eventID uint, availabilities []models.Availability) error {
+	args := m.Called(eventID, availabilities)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetEventAvailabilities(ctx context.Context, eventID uint) ([]models.Availability, error) {
+	args := m.Called(eventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Availability), args.Error(1)
+}
+
+func (m *MockRepository) GetAvailabilityHistory(ctx context.Context, eventID, participantID uint) ([]models.AvailabilityHistory, error) {
+	args := m.Called(eventID, participantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AvailabilityHistory), args.Error(1)
+}
+
+func (m *MockRepository) GetTimeSlotRecommendations(ctx context.Context, eventID uint) ([]models.TimeSlotRecommendation, error) {
 	args := m.Called(eventID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -69,12 +205,12 @@ func (m *MockRepository) GetTimeSlotRecommendations(eventID uint) ([]models.Time
 	return args.Get(0).([]models.TimeSlotRecommendation), args.Error(1)
 }
 
-func (m *MockRepository) CreateParticipant(participant *models.Participant) error {
+func (m *MockRepository) CreateParticipant(ctx context.Context, participant *models.Participant) error {
 	args := m.Called(participant)
 	return args.Error(0)
 }
 
-func (m *MockRepository) GetParticipant(id uint) (*models.Participant, error) {
+func (m *MockRepository) GetParticipant(ctx context.Context, id uint) (*models.Participant, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -82,12 +218,183 @@ func (m *MockRepository) GetParticipant(id uint) (*models.Participant, error) {
 	return args.Get(0).(*models.Participant), args.Error(1)
 }
 
-// setupTestHandler creates a handler with a mock repository for testing
+func (m *MockRepository) ListParticipants(ctx context.Context, filter repository.ParticipantFilter) ([]models.Participant, string, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).([]models.Participant), args.String(1), args.Error(2)
+}
+
+func (m *MockRepository) CreateNotification(ctx context.Context, notification *models.Notification) error {
+	args := m.Called(notification)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetEventNotifications(ctx context.Context, eventID uint) ([]models.Notification, error) {
+	args := m.Called(eventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Notification), args.Error(1)
+}
+
+func (m *MockRepository) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	args := m.Called(hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *MockRepository) GetParticipantAPIKeys(ctx context.Context, participantID uint) ([]models.APIKey, error) {
+	args := m.Called(participantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.APIKey), args.Error(1)
+}
+
+func (m *MockRepository) DeleteAPIKey(ctx context.Context, participantID, keyID uint) error {
+	args := m.Called(participantID, keyID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CreateWebhook(ctx context.Context, hook *models.Webhook) error {
+	args := m.Called(hook)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetParticipantWebhooks(ctx context.Context, participantID uint) ([]models.Webhook, error) {
+	args := m.Called(participantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Webhook), args.Error(1)
+}
+
+func (m *MockRepository) DeleteWebhook(ctx context.Context, participantID, webhookID uint) error {
+	args := m.Called(participantID, webhookID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetWebhookDeliveries(ctx context.Context, participantID, webhookID uint) ([]models.WebhookDelivery, error) {
+	args := m.Called(participantID, webhookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockRepository) CreateOutboxEvent(ctx context.Context, event *models.OutboxEvent) error {
+	args := m.Called(event)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DispatchOutboxEvents(ctx context.Context, limit int) (int, error) {
+	args := m.Called(limit)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) ClaimNotifications(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.Notification, error) {
+	args := m.Called(now, lease, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Notification), args.Error(1)
+}
+
+func (m *MockRepository) UpdateNotification(ctx context.Context, notification *models.Notification) error {
+	args := m.Called(notification)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	args := m.Called(now, lease, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockRepository) UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	args := m.Called(delivery)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ClaimJob(ctx context.Context, name, owner string, now time.Time, lease time.Duration) (*models.Job, error) {
+	args := m.Called(name, owner, now, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Job), args.Error(1)
+}
+
+func (m *MockRepository) FinishJob(ctx context.Context, job *models.Job) error {
+	args := m.Called(job)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetExpiredPolls(ctx context.Context, now time.Time, limit int) ([]models.Event, error) {
+	args := m.Called(now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Event), args.Error(1)
+}
+
+func (m *MockRepository) ClosePoll(ctx context.Context, event *models.Event, now time.Time) (bool, error) {
+	args := m.Called(event, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) GetPollsToRemind(ctx context.Context, now, deadlineBefore time.Time, limit int) ([]models.Event, error) {
+	args := m.Called(now, deadlineBefore, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Event), args.Error(1)
+}
+
+func (m *MockRepository) MarkReminded(ctx context.Context, eventID uint, at time.Time) (bool, error) {
+	args := m.Called(eventID, at)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// testConfig returns the configuration used when registering routes in tests
+func testConfig() *config.Config {
+	return &config.Config{
+		Scheduling: config.SchedulingConfig{WorkdayStart: "08:00", WorkdayEnd: "18:00"},
+		Auth:       config.AuthConfig{SigningKey: testSigningKey, TokenTTLMinutes: 60},
+	}
+}
+
+// asParticipant authenticates a request as the given participant
+func asParticipant(req *http.Request, participantID uint) *http.Request {
+	return req.WithContext(middleware.WithParticipant(req.Context(), &models.Participant{ID: participantID}))
+}
+
+// setupTestHandler creates a handler with a mock repository for testing.
+// Notifications are recorded, not sent, and outbox events are accepted.
 func setupTestHandler(mockRepo *MockRepository) *Handler {
 	logger, _ := zap.NewDevelopment()
+	mockRepo.On("CreateNotification", mock.Anything).Return(nil).Maybe()
+	mockRepo.On("CreateOutboxEvent", mock.Anything).Return(nil).Maybe()
 	return &Handler{
-		Repo: mockRepo,
-		Log:  logger,
+		Repo:          mockRepo,
+		Log:           logger,
+		WorkingHours:  scheduling.DefaultWorkingHours,
+		Notifications: notify.NewService(notify.NewRecorder(), notify.DefaultTemplates(), mockRepo, logger),
 	}
 }
 
@@ -110,6 +417,7 @@ func TestCreateEvent(t *testing.T) {
 	// Create request
 	body, _ := json.Marshal(event)
 	req := httptest.NewRequest("POST", "/events", bytes.NewBuffer(body))
+	req = asParticipant(req, 1)
 	w := httptest.NewRecorder()
 
 	// Execute request
@@ -149,7 +457,7 @@ func TestGetEvent(t *testing.T) {
 	vars := map[string]string{
 		"id": "1",
 	}
-	req = mux.SetURLVars(req, vars)
+	req = asParticipant(mux.SetURLVars(req, vars), 1)
 
 	// Execute request
 	handler.GetEvent(w, req)
@@ -182,7 +490,10 @@ func TestUpdateEvent(t *testing.T) {
 	}
 
 	// Setup expectations
-	mockRepo.On("UpdateEvent", mock.AnythingOfType("*models.Event")).Return(nil)
+	mockRepo.On("GetEvent", uint(1)).Return(&models.Event{ID: 1, Title: "Test Event", OrganizerId: 1, Status: models.EventPolling}, nil)
+	mockRepo.On("UpdateEvent", mock.MatchedBy(func(e *models.Event) bool {
+		return e.Title == "Updated Event" && e.Duration == 90 && e.Status == models.EventPolling
+	})).Return(nil)
 
 	// Create request
 	body, _ := json.Marshal(event)
@@ -193,7 +504,7 @@ func TestUpdateEvent(t *testing.T) {
 	vars := map[string]string{
 		"id": "1",
 	}
-	req = mux.SetURLVars(req, vars)
+	req = asParticipant(mux.SetURLVars(req, vars), 1)
 
 	// Execute request
 	handler.UpdateEvent(w, req)
@@ -211,6 +522,7 @@ func TestDeleteEvent(t *testing.T) {
 	handler := setupTestHandler(mockRepo)
 
 	// Setup expectations
+	mockRepo.On("GetEvent", uint(1)).Return(&models.Event{ID: 1, OrganizerId: 1}, nil)
 	mockRepo.On("DeleteEvent", uint(1)).Return(nil)
 
 	// Create request
@@ -221,7 +533,7 @@ func TestDeleteEvent(t *testing.T) {
 	vars := map[string]string{
 		"id": "1",
 	}
-	req = mux.SetURLVars(req, vars)
+	req = asParticipant(mux.SetURLVars(req, vars), 1)
 
 	// Execute request
 	handler.DeleteEvent(w, req)
@@ -238,12 +550,14 @@ func TestAddTimeSlot(t *testing.T) {
 	mockRepo := new(MockRepository)
 	handler := setupTestHandler(mockRepo)
 
+	start := time.Now().Add(24 * time.Hour)
 	timeSlot := &models.TimeSlot{
 		EventID:   1,
-		StartTime: time.Now(),
-		EndTime:   time.Now().Add(time.Hour),
+		StartTime: start,
+		EndTime:   start.Add(time.Hour),
 	}
 
+	mockRepo.On("GetEvent", uint(1)).Return(&models.Event{ID: 1, OrganizerId: 1, Duration: 60}, nil)
 	mockRepo.On("CreateTimeSlot", mock.AnythingOfType("*models.TimeSlot")).Return(nil)
 
 	body, _ := json.Marshal(timeSlot)
@@ -251,7 +565,7 @@ func TestAddTimeSlot(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	vars := map[string]string{"id": "1"}
-	req = mux.SetURLVars(req, vars)
+	req = asParticipant(mux.SetURLVars(req, vars), 1)
 
 	handler.AddTimeSlot(w, req)
 
@@ -259,6 +573,29 @@ func TestAddTimeSlot(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestAddTimeSlotOpensPolling(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	start := time.Now().Add(24 * time.Hour)
+	mockRepo.On("GetEvent", uint(1)).Return(&models.Event{ID: 1, OrganizerId: 1, Duration: 60, Status: models.EventDraft}, nil)
+	mockRepo.On("CreateTimeSlot", mock.AnythingOfType("*models.TimeSlot")).Return(nil)
+	// Only a draft is opened, so a poll closed meanwhile stays closed
+	mockRepo.On("TransitionEvent", mock.MatchedBy(func(e *models.Event) bool {
+		return e.Status == models.EventPolling
+	}), []models.EventStatus{models.EventDraft}).Return(false, nil)
+
+	body, _ := json.Marshal(models.TimeSlot{StartTime: start, EndTime: start.Add(time.Hour)})
+	req := httptest.NewRequest("POST", "/events/1/timeslots", bytes.NewBuffer(body))
+	req = asParticipant(mux.SetURLVars(req, map[string]string{"id": "1"}), 1)
+	w := httptest.NewRecorder()
+
+	handler.AddTimeSlot(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	mockRepo.AssertExpectations(t)
+}
+
 func TestGetTimeSlots(t *testing.T) {
 	mockRepo := new(MockRepository)
 	handler := setupTestHandler(mockRepo)
@@ -272,17 +609,26 @@ func TestGetTimeSlots(t *testing.T) {
 		},
 	}
 
-	mockRepo.On("GetTimeSlots", uint(1)).Return(timeSlots, nil)
+	mockRepo.On("GetEvent", uint(1)).Return(&models.Event{ID: 1, OrganizerId: 1}, nil)
+	mockRepo.On("ListTimeSlots", uint(1), repository.ListOptions{Limit: 1, Sort: "-start_time"}).Return(timeSlots, "next-page", nil)
 
-	req := httptest.NewRequest("GET", "/events/1/timeslots", nil)
+	req := httptest.NewRequest("GET", "/events/1/timeslots?limit=1&sort=-start_time", nil)
 	w := httptest.NewRecorder()
 
 	vars := map[string]string{"id": "1"}
-	req = mux.SetURLVars(req, vars)
+	req = asParticipant(mux.SetURLVars(req, vars), 1)
 
 	handler.GetTimeSlots(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data       []models.TimeSlot `json:"data"`
+		NextCursor string            `json:"next_cursor"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Len(t, response.Data, 1)
+	assert.Equal(t, "next-page", response.NextCursor)
 	mockRepo.AssertExpectations(t)
 }
 
@@ -303,16 +649,615 @@ func TestGetRecommendations(t *testing.T) {
 		},
 	}
 
+	mockRepo.On("GetEvent", uint(1)).Return(&models.Event{ID: 1, OrganizerId: 1}, nil)
 	mockRepo.On("GetTimeSlotRecommendations", uint(1)).Return(recommendations, nil)
 
 	req := httptest.NewRequest("GET", "/events/1/recommendations", nil)
 	w := httptest.NewRecorder()
 
 	vars := map[string]string{"id": "1"}
-	req = mux.SetURLVars(req, vars)
+	req = asParticipant(mux.SetURLVars(req, vars), 1)
 
 	handler.GetRecommendations(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestSubmitBulkAvailability(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	timeSlots := []models.TimeSlot{
+		{ID: 1, EventID: 1, StartTime: time.Now(), EndTime: time.Now().Add(time.Hour)},
+		{ID: 2, EventID: 1, StartTime: time.Now().Add(2 * time.Hour), EndTime: time.Now().Add(3 * time.Hour)},
+	}
+
+	mockRepo.On("GetParticipant", uint(7)).Return(&models.Participant{ID: 7}, nil)
+	mockRepo.On("GetEvent", uint(1)).Return(&models.Event{ID: 1, Status: models.EventPolling, TimeSlots: timeSlots, Invitations: []models.EventInvitation{{EventID: 1, ParticipantID: 7}}}, nil)
+	mockRepo.On("UpsertAvailabilities", uint(1), mock.AnythingOfType("[]models.Availability")).Return(nil)
+
+	body, _ := json.Marshal(models.BulkAvailabilityRequest{
+		ParticipantID: 7,
+		Availabilities: []models.SlotAvailability{
+			{TimeSlotID: 1, IsAvailable: true},
+			{TimeSlotID: 2, IsAvailable: false},
+		},
+	})
+	req := httptest.NewRequest("POST", "/events/1/availability/bulk", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	vars := map[string]string{"id": "1"}
+	req = asParticipant(mux.SetURLVars(req, vars), 7)
+
+	handler.SubmitBulkAvailability(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response []models.Availability
+	err := json.NewDecoder(w.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Len(t, response, 2)
+	assert.Equal(t, uint(7), response[1].ParticipantID)
+	assert.Equal(t, models.AvailabilityNo, response[1].Status)
+	assert.False(t, response[1].IsAvailable)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSubmitBulkAvailabilityRejectsForeignSlot(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	timeSlots := []models.TimeSlot{
+		{ID: 1, EventID: 1, StartTime: time.Now(), EndTime: time.Now().Add(time.Hour)},
+	}
+
+	mockRepo.On("GetParticipant", uint(7)).Return(&models.Participant{ID: 7}, nil)
+	mockRepo.On("GetEvent", uint(1)).Return(&models.Event{ID: 1, Status: models.EventPolling, TimeSlots: timeSlots, Invitations: []models.EventInvitation{{EventID: 1, ParticipantID: 7}}}, nil)
+
+	body, _ := json.Marshal(models.BulkAvailabilityRequest{
+		ParticipantID: 7,
+		Availabilities: []models.SlotAvailability{
+			{TimeSlotID: 1, IsAvailable: true},
+			{TimeSlotID: 99, IsAvailable: true},
+		},
+	})
+	req := httptest.NewRequest("POST", "/events/1/availability/bulk", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	vars := map[string]string{"id": "1"}
+	req = asParticipant(mux.SetURLVars(req, vars), 7)
+
+	handler.SubmitBulkAvailability(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "availabilities[1].time_slot_id")
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "UpsertAvailabilities", mock.Anything, mock.Anything)
+}
+
+func TestSubmitBulkAvailabilityForRemovedSlot(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	timeSlots := []models.TimeSlot{
+		{ID: 1, EventID: 1, StartTime: time.Now(), EndTime: time.Now().Add(time.Hour)},
+	}
+
+	mockRepo.On("GetParticipant", uint(7)).Return(&models.Participant{ID: 7}, nil)
+	mockRepo.On("GetEvent", uint(1)).Return(&models.Event{ID: 1, Status: models.EventPolling, TimeSlots: timeSlots, Invitations: []models.EventInvitation{{EventID: 1, ParticipantID: 7}}}, nil)
+	mockRepo.On("UpsertAvailabilities", uint(1), mock.AnythingOfType("[]models.Availability")).
+		Return(&repository.Error{Kind: repository.ErrValidation, Message: "Time slot 1 does not belong to event 1"})
+
+	body, _ := json.Marshal(models.BulkAvailabilityRequest{
+		ParticipantID:  7,
+		Availabilities: []models.SlotAvailability{{TimeSlotID: 1, IsAvailable: true}},
+	})
+	req := httptest.NewRequest("POST", "/events/1/availability/bulk", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	vars := map[string]string{"id": "1"}
+	req = asParticipant(mux.SetURLVars(req, vars), 7)
+
+	handler.SubmitBulkAvailability(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Time slot 1 does not belong to event 1")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSubmitBulkAvailabilityAuthorizesBeforeLookup(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	mockRepo.On("GetEvent", uint(1)).Return(policyEvent(), nil)
+
+	body, _ := json.Marshal(models.BulkAvailabilityRequest{
+		ParticipantID: 999,
+		Availabilities: []models.SlotAvailability{
+			{TimeSlotID: 5, IsAvailable: true},
+		},
+	})
+	req := httptest.NewRequest("POST", "/events/1/availability/bulk", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	vars := map[string]string{"id": "1"}
+	req = asParticipant(mux.SetURLVars(req, vars), strangerID)
+
+	handler.SubmitBulkAvailability(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockRepo.AssertNotCalled(t, "GetParticipant", mock.Anything)
+}
+
+func TestSubmitAvailability(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	timeSlots := []models.TimeSlot{
+		{ID: 3, EventID: 1, StartTime: time.Now(), EndTime: time.Now().Add(time.Hour)},
+	}
+
+	mockRepo.On("GetEvent", uint(1)).Return(&models.Event{ID: 1, Status: models.EventPolling, TimeSlots: timeSlots, Invitations: []models.EventInvitation{{EventID: 1, ParticipantID: 7}}}, nil)
+	mockRepo.On("UpsertAvailability", mock.MatchedBy(func(a *models.Availability) bool {
+		return a.ID == 0 && a.ParticipantID == 7 && a.TimeSlotID == 3 &&
+			a.Status == models.AvailabilityIfNeedBe && a.IsAvailable
+	})).Return(nil)
+
+	body, _ := json.Marshal(models.Availability{ParticipantID: 7, TimeSlotID: 3, Status: models.AvailabilityIfNeedBe})
+	req := httptest.NewRequest("POST", "/events/1/availability", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	vars := map[string]string{"id": "1"}
+	req = asParticipant(mux.SetURLVars(req, vars), 7)
+
+	handler.SubmitAvailability(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetAvailabilityHistory(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	history := []models.AvailabilityHistory{
+		{ID: 1, AvailabilityID: 4, ParticipantID: 7, TimeSlotID: 3, IsAvailable: true},
+	}
+
+	mockRepo.On("GetEvent", uint(1)).Return(&models.Event{ID: 1, Invitations: []models.EventInvitation{{EventID: 1, ParticipantID: 7}}}, nil)
+	mockRepo.On("GetAvailabilityHistory", uint(1), uint(7)).Return(history, nil)
+
+	req := httptest.NewRequest("GET", "/events/1/participants/7/availability/history", nil)
+	w := httptest.NewRecorder()
+
+	vars := map[string]string{"id": "1", "pid": "7"}
+	req = asParticipant(mux.SetURLVars(req, vars), 7)
+
+	handler.GetAvailabilityHistory(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response []models.AvailabilityHistory
+	err := json.NewDecoder(w.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, history[0].AvailabilityID, response[0].AvailabilityID)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestInviteParticipant(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	mockRepo.On("GetEvent", uint(1)).Return(&models.Event{ID: 1, OrganizerId: 1}, nil)
+	mockRepo.On("GetParticipant", uint(7)).Return(&models.Participant{ID: 7, Name: "Ada"}, nil)
+	mockRepo.On("InviteParticipant", mock.MatchedBy(func(i *models.EventInvitation) bool {
+		return i.EventID == 1 && i.ParticipantID == 7 && i.Role == models.AttendeeOptional
+	})).Return(nil)
+
+	body, _ := json.Marshal(models.EventInvitation{ParticipantID: 7, Role: models.AttendeeOptional})
+	req := httptest.NewRequest("POST", "/events/1/invitations", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	vars := map[string]string{"id": "1"}
+	req = asParticipant(mux.SetURLVars(req, vars), 1)
+
+	handler.InviteParticipant(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestInviteParticipantRejectsUnknownRole(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	body := []byte(`{"participant_id": 7, "role": "vip"}`)
+	req := httptest.NewRequest("POST", "/events/1/invitations", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	vars := map[string]string{"id": "1"}
+	req = asParticipant(mux.SetURLVars(req, vars), 1)
+
+	handler.InviteParticipant(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "role")
+	mockRepo.AssertNotCalled(t, "InviteParticipant", mock.Anything)
+}
+
+func TestRemoveMissingInvitation(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	mockRepo.On("GetEvent", uint(1)).Return(&models.Event{ID: 1, OrganizerId: 1}, nil)
+	mockRepo.On("RemoveInvitation", uint(1), uint(7)).
+		Return(&repository.Error{Kind: repository.ErrNotFound, Message: "Invitation not found"})
+
+	req := httptest.NewRequest("DELETE", "/events/1/invitations/7", nil)
+	w := httptest.NewRecorder()
+
+	vars := map[string]string{"id": "1", "pid": "7"}
+	req = asParticipant(mux.SetURLVars(req, vars), 1)
+
+	handler.RemoveInvitation(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "Invitation not found")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetRecommendationsInViewerTimeZone(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	start := time.Date(2030, 1, 7, 16, 0, 0, 0, time.UTC)
+	recommendations := []models.TimeSlotRecommendation{
+		{
+			TimeSlot: models.TimeSlot{ID: 1, EventID: 1, StartTime: start, EndTime: start.Add(time.Hour)},
+			AvailableUsers: []models.Participant{
+				{ID: 1, Name: "Ada", TimeZone: "Europe/London"},
+				{ID: 2, Name: "Kenji", TimeZone: "Asia/Tokyo"},
+			},
+			AvailableCount: 2,
+		},
+	}
+
+	mockRepo.On("GetEvent", uint(1)).Return(&models.Event{ID: 1, OrganizerId: 1}, nil)
+	mockRepo.On("GetTimeSlotRecommendations", uint(1)).Return(recommendations, nil)
+
+	req := httptest.NewRequest("GET", "/events/1/recommendations?tz=America/New_York", nil)
+	w := httptest.NewRecorder()
+
+	vars := map[string]string{"id": "1"}
+	req = asParticipant(mux.SetURLVars(req, vars), 1)
+
+	handler.GetRecommendations(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data []models.TimeSlotRecommendation `json:"data"`
+	}
+	err := json.NewDecoder(w.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, "America/New_York", response.Data[0].TimeSlot.Local.TimeZone)
+	assert.Equal(t, 11, response.Data[0].TimeSlot.Local.StartTime.Hour())
+	assert.Len(t, response.Data[0].OutsideWorkingHours, 1)
+	assert.Equal(t, "Asia/Tokyo", response.Data[0].OutsideWorkingHours[0].TimeZone)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetTimeSlotsRejectsUnknownTimeZone(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	mockRepo.On("GetEvent", uint(1)).Return(&models.Event{ID: 1, OrganizerId: 1}, nil)
+
+	req := httptest.NewRequest("GET", "/events/1/timeslots", nil)
+	req.Header.Set("X-Time-Zone", "Mars/Olympus_Mons")
+	w := httptest.NewRecorder()
+
+	vars := map[string]string{"id": "1"}
+	req = asParticipant(mux.SetURLVars(req, vars), 1)
+
+	handler.GetTimeSlots(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockRepo.AssertNotCalled(t, "ListTimeSlots", mock.Anything, mock.Anything)
+}
+
+func TestGenerateTimeSlots(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	mockRepo.On("GetEvent", uint(1)).Return(&models.Event{ID: 1, OrganizerId: 1, Duration: 60}, nil)
+	mockRepo.On("CreateTimeSlots", uint(1), mock.MatchedBy(func(slots []models.TimeSlot) bool {
+		// Two days of 09:00-12:00 Berlin in hourly steps
+		return len(slots) == 6
+	})).Return(make([]models.TimeSlot, 5), nil)
+
+	body, _ := json.Marshal(models.TimeSlotGenerationRequest{
+		StartDate:   "2030-01-04",
+		EndDate:     "2030-01-07",
+		Windows:     []models.WorkingWindow{{TimeZone: "Europe/Berlin", Start: "09:00", End: "12:00"}},
+		ExcludeDays: []string{"saturday", "sun"},
+	})
+	req := httptest.NewRequest("POST", "/events/1/timeslots:generate", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	vars := map[string]string{"id": "1"}
+	req = asParticipant(mux.SetURLVars(req, vars), 1)
+
+	handler.GenerateTimeSlots(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response models.TimeSlotGenerationResult
+	err := json.NewDecoder(w.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Len(t, response.Created, 5)
+	assert.Equal(t, 1, response.Skipped)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAddTimeSlotValidation(t *testing.T) {
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+
+	tests := []struct {
+		name    string
+		slot    models.TimeSlot
+		field   string
+		message string
+	}{
+		{"end before start", models.TimeSlot{StartTime: start, EndTime: start.Add(-time.Hour)}, "end_time", "End time must be after start time"},
+		{"shorter than duration", models.TimeSlot{StartTime: start, EndTime: start.Add(30 * time.Minute)}, "end_time", "shorter than the event duration"},
+		{"in the past", models.TimeSlot{StartTime: time.Now().Add(-2 * time.Hour), EndTime: time.Now().Add(-time.Hour)}, "start_time", "in the past"},
+		{"beyond horizon", models.TimeSlot{StartTime: start.AddDate(0, 0, 60), EndTime: start.AddDate(0, 0, 60).Add(time.Hour)}, "start_time", "scheduling horizon of 30 days"},
+		{"missing end", models.TimeSlot{StartTime: start}, "end_time", "This field is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			handler := setupTestHandler(mockRepo)
+			handler.Horizon = 30 * 24 * time.Hour
+
+			mockRepo.On("GetEvent", uint(1)).Return(&models.Event{ID: 1, OrganizerId: 1, Duration: 60}, nil)
+
+			body, _ := json.Marshal(tt.slot)
+			req := httptest.NewRequest("POST", "/events/1/timeslots", bytes.NewBuffer(body))
+			w := httptest.NewRecorder()
+
+			vars := map[string]string{"id": "1"}
+			req = asParticipant(mux.SetURLVars(req, vars), 1)
+
+			handler.AddTimeSlot(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var response struct {
+				Errors middleware.ValidationErrors `json:"errors"`
+			}
+			err := json.NewDecoder(w.Body).Decode(&response)
+			assert.NoError(t, err)
+			if assert.NotEmpty(t, response.Errors) {
+				assert.Equal(t, tt.field, response.Errors[0].Field)
+				assert.Contains(t, response.Errors[0].Message, tt.message)
+			}
+			mockRepo.AssertNotCalled(t, "CreateTimeSlot", mock.Anything)
+		})
+	}
+}
+
+func TestAddTimeSlotRejectsOverlap(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	start := time.Now().Add(24 * time.Hour)
+	mockRepo.On("GetEvent", uint(1)).Return(&models.Event{ID: 1, OrganizerId: 1, Duration: 60}, nil)
+	mockRepo.On("CreateTimeSlot", mock.AnythingOfType("*models.TimeSlot")).Return(&repository.Error{
+		Kind:    repository.ErrConflict,
+		Message: "Time slot overlaps time slot 5",
+	})
+
+	body, _ := json.Marshal(models.TimeSlot{StartTime: start, EndTime: start.Add(time.Hour)})
+	req := httptest.NewRequest("POST", "/events/1/timeslots", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	vars := map[string]string{"id": "1"}
+	req = asParticipant(mux.SetURLVars(req, vars), 1)
+
+	handler.AddTimeSlot(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "Time slot overlaps time slot 5", decodeProblem(t, w).Detail)
+}
+
+func TestCreateEventValidatesTimeSlots(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	event := models.Event{
+		Title:    "Planning",
+		Duration: 60,
+		TimeSlots: []models.TimeSlot{
+			{StartTime: start, EndTime: start.Add(time.Hour)},
+			{StartTime: start.Add(30 * time.Minute), EndTime: start.Add(90 * time.Minute)},
+			{StartTime: start.Add(2 * time.Hour), EndTime: start.Add(150 * time.Minute)},
+		},
+	}
+
+	body, _ := json.Marshal(event)
+	req := httptest.NewRequest("POST", "/events", bytes.NewBuffer(body))
+	req = asParticipant(req, 1)
+	w := httptest.NewRecorder()
+
+	handler.CreateEvent(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response struct {
+		Errors middleware.ValidationErrors `json:"errors"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.Len(t, response.Errors, 2)
+	assert.Equal(t, "time_slots[1].start_time", response.Errors[0].Field)
+	assert.Contains(t, response.Errors[0].Message, "Overlaps time slot")
+	assert.Equal(t, "time_slots[2].end_time", response.Errors[1].Field)
+	mockRepo.AssertNotCalled(t, "CreateEvent", mock.Anything)
+}
+
+func TestConfirmEvent(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	start := time.Now().Add(24 * time.Hour)
+	event := &models.Event{
+		ID:          1,
+		OrganizerId: 1,
+		Status:      models.EventPolling,
+		TimeSlots: []models.TimeSlot{
+			{ID: 1, EventID: 1, StartTime: start, EndTime: start.Add(time.Hour)},
+			{ID: 2, EventID: 1, StartTime: start.Add(2 * time.Hour), EndTime: start.Add(3 * time.Hour)},
+		},
+	}
+
+	mockRepo.On("GetEvent", uint(1)).Return(event, nil)
+	mockRepo.On("TransitionEvent", mock.MatchedBy(func(e *models.Event) bool {
+		return e.Status == models.EventConfirmed && *e.ConfirmedTimeSlotID == 2
+	}), []models.EventStatus{models.EventDraft, models.EventPolling, models.EventClosed}).Return(true, nil)
+
+	body, _ := json.Marshal(models.ConfirmEventRequest{TimeSlotID: 2})
+	req := httptest.NewRequest("POST", "/events/1/confirm", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	vars := map[string]string{"id": "1"}
+	req = asParticipant(mux.SetURLVars(req, vars), 1)
+
+	handler.ConfirmEvent(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.Event
+	err := json.NewDecoder(w.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, models.EventConfirmed, response.Status)
+	assert.Equal(t, uint(2), response.ConfirmedTimeSlot.ID)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestConfirmEventRejectsForeignSlot(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	mockRepo.On("GetEvent", uint(1)).Return(&models.Event{ID: 1, OrganizerId: 1, Status: models.EventPolling}, nil)
+
+	body, _ := json.Marshal(models.ConfirmEventRequest{TimeSlotID: 9})
+	req := httptest.NewRequest("POST", "/events/1/confirm", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	vars := map[string]string{"id": "1"}
+	req = asParticipant(mux.SetURLVars(req, vars), 1)
+
+	handler.ConfirmEvent(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockRepo.AssertNotCalled(t, "TransitionEvent", mock.Anything, mock.Anything)
+}
+
+func TestConcurrentDecisionConflicts(t *testing.T) {
+	start := time.Now().Add(24 * time.Hour)
+	event := func() *models.Event {
+		return &models.Event{
+			ID:          1,
+			OrganizerId: 1,
+			Status:      models.EventPolling,
+			TimeSlots:   []models.TimeSlot{{ID: 2, EventID: 1, StartTime: start, EndTime: start.Add(time.Hour)}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		handle func(*Handler, http.ResponseWriter, *http.Request)
+	}{
+		{"confirm", `{"time_slot_id":2}`, (*Handler).ConfirmEvent},
+		{"cancel", ``, (*Handler).CancelEvent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			handler := setupTestHandler(mockRepo)
+
+			// Another request decided the event after this one loaded it
+			mockRepo.On("GetEvent", uint(1)).Return(event(), nil)
+			mockRepo.On("TransitionEvent", mock.Anything, mock.Anything).Return(false, nil)
+
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			req = asParticipant(mux.SetURLVars(req, map[string]string{"id": "1"}), 1)
+			w := httptest.NewRecorder()
+
+			tt.handle(handler, w, req)
+
+			assert.Equal(t, http.StatusConflict, w.Code)
+			mockRepo.AssertNotCalled(t, "CreateOutboxEvent", mock.Anything)
+			mockRepo.AssertNotCalled(t, "CreateNotification", mock.Anything)
+		})
+	}
+}
+
+func TestSubmitAvailabilityRejectedAfterConfirmation(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	slotID := uint(3)
+	mockRepo.On("GetEvent", uint(1)).Return(&models.Event{
+		ID:                  1,
+		Status:              models.EventConfirmed,
+		ConfirmedTimeSlotID: &slotID,
+		TimeSlots:           []models.TimeSlot{{ID: slotID, EventID: 1}},
+		Invitations:         []models.EventInvitation{{EventID: 1, ParticipantID: 7}},
+	}, nil)
+
+	body, _ := json.Marshal(models.Availability{ParticipantID: 7, TimeSlotID: slotID, Status: models.AvailabilityYes})
+	req := httptest.NewRequest("POST", "/events/1/availability", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	vars := map[string]string{"id": "1"}
+	req = asParticipant(mux.SetURLVars(req, vars), 7)
+
+	handler.SubmitAvailability(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockRepo.AssertNotCalled(t, "UpsertAvailability", mock.Anything)
+}
+
+func TestRequestLogsCarryRequestID(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+	core, logs := observer.New(zap.InfoLevel)
+	handler.Log = zap.New(core)
+	mockRepo.On("GetEvent", uint(1)).Return(&models.Event{ID: 1, OrganizerId: 1}, nil)
+	mockRepo.On("GetEvent", uint(2)).Return(nil, errors.New("connection reset"))
+
+	for _, id := range []string{"1", "2"} {
+		req := httptest.NewRequest(http.MethodGet, "/events/"+id, nil)
+		req.Header.Set("X-Request-ID", "req-"+id)
+		req = asParticipant(mux.SetURLVars(req, map[string]string{"id": id}), 1)
+		middleware.RequestID(http.HandlerFunc(handler.GetEvent)).ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	entries := logs.All()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "Event retrieved successfully", entries[0].Message)
+	assert.Equal(t, "req-1", entries[0].ContextMap()["request_id"])
+	assert.Equal(t, "Failed to get event", entries[1].Message)
+	assert.Equal(t, "req-2", entries[1].ContextMap()["request_id"])
+}
@@ -0,0 +1,278 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/tusharsingune/meeting-scheduler/internal/auth"
+	"github.com/tusharsingune/meeting-scheduler/internal/middleware"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"github.com/tusharsingune/meeting-scheduler/internal/repository"
+	"github.com/tusharsingune/meeting-scheduler/internal/scheduling"
+	"go.uber.org/zap"
+)
+
+// defaultInvitationTTL is how long invitation links are valid when not configured
+const defaultInvitationTTL = 14 * 24 * time.Hour
+
+// CreateInvitationLink handles creating an invitation link that lets a guest
+// answer an event without an account. The response carries the link's token,
+// which is not shown again.
+func (h *Handler) CreateInvitationLink(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	eventID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		h.log(r.Context()).Error("Invalid event ID", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid event ID")
+		return
+	}
+
+	var link models.InvitationLink
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&link); err != nil {
+		h.log(r.Context()).Error("Failed to decode request body", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	now := time.Now()
+	link.Email = strings.ToLower(strings.TrimSpace(link.Email))
+	errs := middleware.ValidateStruct(link)
+	if !link.ExpiresAt.IsZero() && !link.ExpiresAt.After(now) {
+		errs = append(errs, middleware.ValidationError{Field: "expires_at", Message: "Expiry must be in the future"})
+	}
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+
+	event, _ := h.authorizeEvent(w, r, uint(eventID), actionManage)
+	if event == nil {
+		return
+	}
+
+	link.ID = 0
+	link.EventID = event.ID
+	link.ParticipantID, link.UsedAt, link.RevokedAt = nil, nil, nil
+	if link.Name == "" {
+		link.Name = link.Email[:strings.Index(link.Email, "@")]
+	}
+	if link.Role == "" {
+		link.Role = models.AttendeeRequired
+	}
+	if link.ExpiresAt.IsZero() {
+		link.ExpiresAt = now.Add(h.InvitationTTL)
+	}
+	link.ExpiresAt = link.ExpiresAt.UTC().Truncate(time.Second)
+
+	token, err := auth.GenerateInvitationToken()
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to create invitation link")
+		return
+	}
+	link.TokenHash = auth.HashInvitationToken(token)
+	if err := h.Repo.CreateInvitationLink(r.Context(), &link); err != nil {
+		h.respondWithFailure(w, r, err, "Failed to create invitation link")
+		return
+	}
+	link.Token = token
+
+	h.log(r.Context()).Info("Invitation link created successfully",
+		zap.Uint("event_id", event.ID),
+		zap.Uint("invitation_link_id", link.ID))
+	respondWithJSON(w, http.StatusCreated, link)
+}
+
+// GetInvitationLinks handles listing the invitation links of an event
+func (h *Handler) GetInvitationLinks(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	eventID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		h.log(r.Context()).Error("Invalid event ID", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid event ID")
+		return
+	}
+
+	if event, _ := h.authorizeEvent(w, r, uint(eventID), actionManage); event == nil {
+		return
+	}
+
+	links, err := h.Repo.GetInvitationLinks(r.Context(), uint(eventID))
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to get invitation links")
+		return
+	}
+
+	h.log(r.Context()).Info("Invitation links retrieved successfully", zap.Uint64("event_id", eventID))
+	respondWithJSON(w, http.StatusOK, links)
+}
+
+// RevokeInvitationLink handles revoking an invitation link so it can no
+// longer be used. Answers already given through it are kept.
+func (h *Handler) RevokeInvitationLink(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	eventID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		h.log(r.Context()).Error("Invalid event ID", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid event ID")
+		return
+	}
+
+	linkID, err := strconv.ParseUint(vars["lid"], 10, 32)
+	if err != nil {
+		h.log(r.Context()).Error("Invalid invitation link ID", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid invitation link ID")
+		return
+	}
+
+	if event, _ := h.authorizeEvent(w, r, uint(eventID), actionManage); event == nil {
+		return
+	}
+
+	if err := h.Repo.RevokeInvitationLink(r.Context(), uint(eventID), uint(linkID)); err != nil {
+		h.respondWithFailure(w, r, err, "Failed to revoke invitation link")
+		return
+	}
+
+	h.log(r.Context()).Info("Invitation link revoked successfully",
+		zap.Uint64("event_id", eventID),
+		zap.Uint64("invitation_link_id", linkID))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// guestLink resolves the invitation link named by the token in the URL and
+// the participant using it, linking a participant by email on first use. It
+// responds with an error and returns nil when the token cannot be used.
+func (h *Handler) guestLink(w http.ResponseWriter, r *http.Request) (*models.InvitationLink, *models.Participant) {
+	now := time.Now()
+	token := mux.Vars(r)["token"]
+	if !strings.HasPrefix(token, auth.InvitationTokenPrefix) {
+		respondWithError(w, http.StatusUnauthorized, "Invalid invitation link")
+		return nil, nil
+	}
+
+	link, err := h.Repo.GetInvitationLinkByHash(r.Context(), auth.HashInvitationToken(token))
+	if errors.Is(err, repository.ErrNotFound) {
+		respondWithError(w, http.StatusUnauthorized, "Invalid invitation link")
+		return nil, nil
+	}
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to get invitation link")
+		return nil, nil
+	}
+	if !link.Usable(now) {
+		h.log(r.Context()).Warn("Unusable invitation link", zap.Uint("invitation_link_id", link.ID))
+		respondWithError(w, http.StatusUnauthorized, "Invitation link has been revoked or has expired")
+		return nil, nil
+	}
+
+	var participant *models.Participant
+	if link.ParticipantID == nil {
+		participant, err = h.Repo.ClaimInvitationLink(r.Context(), link)
+		if err == nil {
+			h.log(r.Context()).Info("Invitation link claimed",
+				zap.Uint("invitation_link_id", link.ID),
+				zap.Uint("participant_id", participant.ID))
+		}
+	} else {
+		participant, err = h.Repo.GetParticipant(r.Context(), *link.ParticipantID)
+	}
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to get participant for invitation link")
+		return nil, nil
+	}
+	return link, participant
+}
+
+// GetGuestInvitation handles showing a guest the event they were invited to
+// through an invitation link, with its time slots and their answers so far
+func (h *Handler) GetGuestInvitation(w http.ResponseWriter, r *http.Request) {
+	loc, err := viewerLocation(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid time zone")
+		return
+	}
+
+	link, participant := h.guestLink(w, r)
+	if link == nil {
+		return
+	}
+
+	event, err := h.Repo.GetEvent(r.Context(), link.EventID)
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to get event")
+		return
+	}
+	// Guests see the event, not who else is invited to it
+	event.Invitations = nil
+	if loc != nil {
+		scheduling.Localize(event.TimeSlots, loc)
+	}
+
+	availabilities, err := h.Repo.GetEventAvailabilities(r.Context(), event.ID)
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to get availabilities")
+		return
+	}
+	own := []models.Availability{}
+	for _, availability := range availabilities {
+		if availability.ParticipantID == participant.ID {
+			own = append(own, availability)
+		}
+	}
+
+	h.log(r.Context()).Info("Guest invitation retrieved successfully",
+		zap.Uint("event_id", event.ID),
+		zap.Uint("participant_id", participant.ID))
+	respondWithJSON(w, http.StatusOK, models.GuestInvitation{
+		Event:          event,
+		Participant:    participant,
+		Availabilities: own,
+	})
+}
+
+// SubmitGuestAvailability handles a guest answering the event they were
+// invited to through an invitation link. Either all answers are stored or none.
+func (h *Handler) SubmitGuestAvailability(w http.ResponseWriter, r *http.Request) {
+	var req models.GuestAvailabilityRequest
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&req); err != nil {
+		h.log(r.Context()).Error("Failed to decode request body", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if errs := middleware.ValidateStruct(req); len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+
+	link, participant := h.guestLink(w, r)
+	if link == nil {
+		return
+	}
+
+	event, err := h.Repo.GetEvent(r.Context(), link.EventID)
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to get event")
+		return
+	}
+
+	availabilities, ok := h.saveAnswers(w, r, event, participant.ID, req.Availabilities)
+	if !ok {
+		return
+	}
+
+	h.log(r.Context()).Info("Guest availability submitted successfully",
+		zap.Uint("event_id", event.ID),
+		zap.Uint("participant_id", participant.ID),
+		zap.Int("count", len(availabilities)))
+	respondWithJSON(w, http.StatusCreated, availabilities)
+}
@@ -0,0 +1,218 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tusharsingune/meeting-scheduler/internal/auth"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"github.com/tusharsingune/meeting-scheduler/internal/repository"
+)
+
+// guestToken is the token of the invitation link guests use in these tests
+const guestToken = auth.InvitationTokenPrefix + "c2VjcmV0LWd1ZXN0LXRva2Vu"
+
+func TestCreateInvitationLink(t *testing.T) {
+	mockRepo := new(MockRepository)
+	router := mux.NewRouter()
+	RegisterHandlers(router, mockRepo, testConfig())
+
+	mockRepo.On("GetEvent", uint(1)).Return(policyEvent(), nil)
+	var storedHash string
+	mockRepo.On("CreateInvitationLink", mock.MatchedBy(func(link *models.InvitationLink) bool {
+		return link.EventID == 1 && link.Email == "grace@example.com" && link.Name == "grace" &&
+			link.Role == models.AttendeeOptional && link.ParticipantID == nil
+	})).Run(func(args mock.Arguments) {
+		link := args.Get(0).(*models.InvitationLink)
+		link.ID = 7
+		storedHash = link.TokenHash
+	}).Return(nil)
+
+	body := `{"email":" Grace@Example.com ","role":"optional","participant_id":4}`
+	req := httptest.NewRequest("POST", "/api/v1/events/1/invitation-links", strings.NewReader(body))
+	authorize(t, mockRepo, req, organizerID)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var link models.InvitationLink
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &link))
+	assert.WithinDuration(t, time.Now().Add(defaultInvitationTTL), link.ExpiresAt, time.Minute)
+
+	// Only the hash of the token is stored
+	assert.True(t, strings.HasPrefix(link.Token, auth.InvitationTokenPrefix))
+	assert.Equal(t, auth.HashInvitationToken(link.Token), storedHash)
+	assert.NotContains(t, w.Body.String(), storedHash)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateInvitationLinkRequiresOrganizer(t *testing.T) {
+	mockRepo := new(MockRepository)
+	router := mux.NewRouter()
+	RegisterHandlers(router, mockRepo, testConfig())
+
+	mockRepo.On("GetEvent", uint(1)).Return(policyEvent(), nil)
+
+	req := httptest.NewRequest("POST", "/api/v1/events/1/invitation-links", strings.NewReader(`{"email":"grace@example.com"}`))
+	authorize(t, mockRepo, req, inviteeID)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockRepo.AssertNotCalled(t, "CreateInvitationLink", mock.Anything)
+}
+
+func TestRevokeInvitationLink(t *testing.T) {
+	mockRepo := new(MockRepository)
+	router := mux.NewRouter()
+	RegisterHandlers(router, mockRepo, testConfig())
+
+	mockRepo.On("GetEvent", uint(1)).Return(policyEvent(), nil)
+	mockRepo.On("RevokeInvitationLink", uint(1), uint(7)).Return(nil)
+
+	req := httptest.NewRequest("DELETE", "/api/v1/events/1/invitation-links/7", nil)
+	authorize(t, mockRepo, req, coOrganizerID)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRevokeMissingInvitationLink(t *testing.T) {
+	mockRepo := new(MockRepository)
+	router := mux.NewRouter()
+	RegisterHandlers(router, mockRepo, testConfig())
+
+	mockRepo.On("GetEvent", uint(1)).Return(policyEvent(), nil)
+	mockRepo.On("RevokeInvitationLink", uint(1), uint(8)).
+		Return(&repository.Error{Kind: repository.ErrNotFound, Message: "Invitation link not found"})
+
+	req := httptest.NewRequest("DELETE", "/api/v1/events/1/invitation-links/8", nil)
+	authorize(t, mockRepo, req, coOrganizerID)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGuestInvitationLinksParticipantOnFirstUse(t *testing.T) {
+	mockRepo := new(MockRepository)
+	router := mux.NewRouter()
+	RegisterHandlers(router, mockRepo, testConfig())
+
+	expiresAt := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	link := &models.InvitationLink{ID: 7, EventID: 1, Email: "grace@example.com", Name: "grace", ExpiresAt: expiresAt}
+	guest := &models.Participant{ID: 9, Name: "grace", Email: "grace@example.com"}
+	mockRepo.On("GetInvitationLinkByHash", auth.HashInvitationToken(guestToken)).Return(link, nil)
+	mockRepo.On("ClaimInvitationLink", link).Return(guest, nil)
+	mockRepo.On("GetEvent", uint(1)).Return(policyEvent(), nil)
+	mockRepo.On("GetEventAvailabilities", uint(1)).Return([]models.Availability{
+		{ParticipantID: inviteeID, TimeSlotID: 5, Status: models.AvailabilityNo},
+		{ParticipantID: 9, TimeSlotID: 5, Status: models.AvailabilityYes},
+	}, nil)
+
+	// No credentials, only the token
+	req := httptest.NewRequest("GET", "/api/v1/guest/"+guestToken, nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var invitation models.GuestInvitation
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &invitation))
+	assert.Equal(t, uint(9), invitation.Participant.ID)
+	assert.Len(t, invitation.Event.TimeSlots, 1)
+	assert.Empty(t, invitation.Event.Invitations, "guests must not see the other invitees")
+	require.Len(t, invitation.Availabilities, 1)
+	assert.Equal(t, uint(9), invitation.Availabilities[0].ParticipantID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSubmitGuestAvailability(t *testing.T) {
+	mockRepo := new(MockRepository)
+	router := mux.NewRouter()
+	RegisterHandlers(router, mockRepo, testConfig())
+
+	expiresAt := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	guestID := uint(9)
+	mockRepo.On("GetInvitationLinkByHash", auth.HashInvitationToken(guestToken)).
+		Return(&models.InvitationLink{ID: 7, EventID: 1, ParticipantID: &guestID, ExpiresAt: expiresAt}, nil)
+	mockRepo.On("GetParticipant", guestID).Return(&models.Participant{ID: guestID}, nil)
+	mockRepo.On("GetEvent", uint(1)).Return(policyEvent(), nil)
+	mockRepo.On("UpsertAvailabilities", uint(1), mock.MatchedBy(func(availabilities []models.Availability) bool {
+		return len(availabilities) == 1 && availabilities[0].ParticipantID == guestID &&
+			availabilities[0].Status == models.AvailabilityIfNeedBe
+	})).Return(nil)
+	mockRepo.On("CreateOutboxEvent", mock.MatchedBy(func(event *models.OutboxEvent) bool {
+		return event.Type == "availability.submitted" && event.OrganizerID == organizerID
+	})).Return(nil)
+
+	body := `{"availabilities":[{"time_slot_id":5,"status":"if_need_be"}]}`
+	req := httptest.NewRequest("POST", "/api/v1/guest/"+guestToken+"/availability", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	mockRepo.AssertNotCalled(t, "ClaimInvitationLink", mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGuestLinkRejected(t *testing.T) {
+	expiresAt := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	revokedAt := time.Now().Add(-time.Minute)
+
+	signer, err := auth.NewSigner([]byte(testSigningKey))
+	require.NoError(t, err)
+	bearer, _ := signer.Issue(7, time.Now(), time.Hour)
+
+	tests := []struct {
+		name  string
+		token string
+		link  *models.InvitationLink
+	}{
+		{"revoked link", guestToken, &models.InvitationLink{ID: 7, EventID: 1, ExpiresAt: expiresAt, RevokedAt: &revokedAt}},
+		{"expired link", guestToken, &models.InvitationLink{ID: 7, EventID: 1, ExpiresAt: time.Now().Add(-time.Minute)}},
+		{"unknown token", guestToken, nil},
+		{"bearer token", bearer, nil},
+		{"not a token", "abc", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			router := mux.NewRouter()
+			RegisterHandlers(router, mockRepo, testConfig())
+
+			if tt.link != nil {
+				mockRepo.On("GetInvitationLinkByHash", auth.HashInvitationToken(tt.token)).Return(tt.link, nil)
+			} else {
+				mockRepo.On("GetInvitationLinkByHash", mock.Anything).Return(nil, repository.ErrNotFound)
+			}
+
+			body := `{"availabilities":[{"time_slot_id":5,"status":"yes"}]}`
+			req := httptest.NewRequest("POST", "/api/v1/guest/"+tt.token+"/availability", strings.NewReader(body))
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			mockRepo.AssertNotCalled(t, "ClaimInvitationLink", mock.Anything)
+			mockRepo.AssertNotCalled(t, "UpsertAvailabilities", mock.Anything, mock.Anything)
+		})
+	}
+}
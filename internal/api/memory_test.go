@@ -0,0 +1,157 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"github.com/tusharsingune/meeting-scheduler/internal/repository"
+)
+
+// memoryAPI serves the API from an in-memory repository, so requests run
+// against stored data rather than mocked calls
+type memoryAPI struct {
+	t      *testing.T
+	router *mux.Router
+}
+
+func newMemoryAPI(t *testing.T) *memoryAPI {
+	router := mux.NewRouter()
+	RegisterHandlers(router, repository.NewMemoryRepository(), testConfig())
+	return &memoryAPI{t: t, router: router}
+}
+
+// do sends a request with the API key, if any, and decodes the response into out
+func (a *memoryAPI) do(method, path, apiKey, body string, wantStatus int, out interface{}) {
+	a.t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+	w := httptest.NewRecorder()
+	a.router.ServeHTTP(w, req)
+	require.Equal(a.t, wantStatus, w.Code, "%s %s: %s", method, path, w.Body.String())
+	if out != nil {
+		require.NoError(a.t, json.Unmarshal(w.Body.Bytes(), out))
+	}
+}
+
+// signUp creates a participant and returns it with its API key
+func (a *memoryAPI) signUp(name, email string) models.Participant {
+	a.t.Helper()
+	var participant models.Participant
+	a.do(http.MethodPost, "/api/v1/participants", "", `{"name":"`+name+`","email":"`+email+`"}`, http.StatusCreated, &participant)
+	return participant
+}
+
+func TestSchedulingAgainstMemoryRepository(t *testing.T) {
+	api := newMemoryAPI(t)
+	ada := api.signUp("Ada", "ada@example.com")
+	bob := api.signUp("Bob", "bob@example.com")
+	api.do(http.MethodPost, "/api/v1/participants", "", `{"name":"Ada","email":"ada@example.com"}`, http.StatusConflict, nil)
+
+	var event models.Event
+	api.do(http.MethodPost, "/api/v1/events", ada.APIKey, `{
+		"title": "Planning",
+		"duration": 60,
+		"time_slots": [
+			{"start_time": "2030-06-03T09:00:00Z", "end_time": "2030-06-03T10:00:00Z"},
+			{"start_time": "2030-06-04T09:00:00Z", "end_time": "2030-06-04T10:00:00Z"}
+		]
+	}`, http.StatusCreated, &event)
+	require.Len(t, event.TimeSlots, 2)
+	assert.Equal(t, models.EventPolling, event.Status)
+	monday, tuesday := event.TimeSlots[0].ID, event.TimeSlots[1].ID
+	eventPath := fmt.Sprintf("/api/v1/events/%d", event.ID)
+
+	// Bob may only answer once invited
+	answer := func(slotID uint, status string) string {
+		return fmt.Sprintf(`{"time_slot_id":%d,"status":%q}`, slotID, status)
+	}
+	api.do(http.MethodPost, eventPath+"/availability", bob.APIKey, answer(monday, "yes"), http.StatusForbidden, nil)
+	api.do(http.MethodPost, eventPath+"/invitations", ada.APIKey, fmt.Sprintf(`{"participant_id":%d}`, bob.ID), http.StatusCreated, nil)
+
+	api.do(http.MethodPost, eventPath+"/availability", bob.APIKey, answer(monday, "yes"), http.StatusCreated, nil)
+	api.do(http.MethodPost, eventPath+"/availability", bob.APIKey, answer(tuesday, "no"), http.StatusCreated, nil)
+	api.do(http.MethodPost, eventPath+"/availability", ada.APIKey, answer(tuesday, "yes"), http.StatusCreated, nil)
+	api.do(http.MethodPost, eventPath+"/availability", bob.APIKey, answer(tuesday, "if_need_be"), http.StatusCreated, nil)
+
+	var recommendations page[models.TimeSlotRecommendation]
+	api.do(http.MethodGet, eventPath+"/recommendations", ada.APIKey, "", http.StatusOK, &recommendations)
+	require.Len(t, recommendations.Data, 2)
+	bySlot := make(map[uint]models.TimeSlotRecommendation)
+	for _, recommendation := range recommendations.Data {
+		bySlot[recommendation.TimeSlot.ID] = recommendation
+	}
+	assert.Equal(t, 1, bySlot[monday].AvailableCount)
+	assert.Empty(t, bySlot[monday].PendingUsers, "the only invitee answered")
+	assert.Equal(t, 1, bySlot[tuesday].AvailableCount)
+	assert.Equal(t, 1, bySlot[tuesday].IfNeedBeCount, "Bob's later answer replaced his first")
+
+	var history []models.AvailabilityHistory
+	api.do(http.MethodGet, fmt.Sprintf("%s/participants/%d/availability/history", eventPath, bob.ID), ada.APIKey, "", http.StatusOK, &history)
+	require.Len(t, history, 1)
+	assert.Equal(t, models.AvailabilityNo, history[0].Status)
+
+	api.do(http.MethodDelete, eventPath, ada.APIKey, "", http.StatusNoContent, nil)
+	api.do(http.MethodGet, eventPath, ada.APIKey, "", http.StatusNotFound, nil)
+}
+
+func TestCreateEventIgnoresTimeSlotIDs(t *testing.T) {
+	api := newMemoryAPI(t)
+	ada := api.signUp("Ada", "ada@example.com")
+	mallory := api.signUp("Mallory", "mallory@example.com")
+
+	var planning models.Event
+	api.do(http.MethodPost, "/api/v1/events", ada.APIKey, `{"title":"Planning","duration":60,
+		"time_slots":[{"start_time":"2030-06-03T09:00:00Z","end_time":"2030-06-03T10:00:00Z"}]}`, http.StatusCreated, &planning)
+	slotID := planning.TimeSlots[0].ID
+
+	var taken models.Event
+	api.do(http.MethodPost, "/api/v1/events", mallory.APIKey, fmt.Sprintf(`{"title":"Mine","duration":60,
+		"time_slots":[{"id":%d,"event_id":%d,"start_time":"2030-06-05T09:00:00Z","end_time":"2030-06-05T10:00:00Z"}]}`,
+		slotID, planning.ID), http.StatusCreated, &taken)
+	require.Len(t, taken.TimeSlots, 1)
+	assert.NotEqual(t, slotID, taken.TimeSlots[0].ID, "a new time slot is created")
+
+	var slots page[models.TimeSlot]
+	api.do(http.MethodGet, fmt.Sprintf("/api/v1/events/%d/timeslots", planning.ID), ada.APIKey, "", http.StatusOK, &slots)
+	require.Len(t, slots.Data, 1, "the original event keeps its time slot")
+	assert.Equal(t, slotID, slots.Data[0].ID)
+	assert.Equal(t, "2030-06-03T09:00:00Z", slots.Data[0].StartTime.UTC().Format(time.RFC3339))
+
+	api.do(http.MethodPost, "/api/v1/events", mallory.APIKey, fmt.Sprintf(`{"title":"Mine","duration":60,
+		"invitations":[{"participant_id":%d}]}`, ada.ID), http.StatusBadRequest, nil)
+}
+
+func TestGetParticipantRequiresSharedEvent(t *testing.T) {
+	api := newMemoryAPI(t)
+	ada := api.signUp("Ada", "ada@example.com")
+	bob := api.signUp("Bob", "bob@example.com")
+	mallory := api.signUp("Mallory", "mallory@example.com")
+	bobPath := fmt.Sprintf("/api/v1/participants/%d", bob.ID)
+
+	var found models.Participant
+	api.do(http.MethodGet, bobPath, bob.APIKey, "", http.StatusOK, &found)
+	assert.Equal(t, "bob@example.com", found.Email)
+	api.do(http.MethodGet, bobPath, ada.APIKey, "", http.StatusNotFound, nil)
+
+	var event models.Event
+	api.do(http.MethodPost, "/api/v1/events", ada.APIKey, `{"title":"Planning","duration":60}`, http.StatusCreated, &event)
+	api.do(http.MethodPost, fmt.Sprintf("/api/v1/events/%d/invitations", event.ID), ada.APIKey,
+		fmt.Sprintf(`{"participant_id":%d}`, bob.ID), http.StatusCreated, nil)
+
+	api.do(http.MethodGet, bobPath, ada.APIKey, "", http.StatusOK, &found)
+	assert.Equal(t, bob.ID, found.ID)
+	api.do(http.MethodGet, fmt.Sprintf("/api/v1/participants/%d", ada.ID), bob.APIKey, "", http.StatusOK, nil)
+	api.do(http.MethodGet, bobPath, mallory.APIKey, "", http.StatusNotFound, nil)
+	api.do(http.MethodGet, "/api/v1/participants/999", mallory.APIKey, "", http.StatusNotFound, nil)
+}
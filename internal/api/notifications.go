@@ -0,0 +1,92 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"github.com/tusharsingune/meeting-scheduler/internal/notify"
+	"github.com/tusharsingune/meeting-scheduler/internal/repository"
+	"go.uber.org/zap"
+)
+
+// defaultReminderCooldown is how long after a reminder an invitee is not
+// reminded again on request when not configured
+const defaultReminderCooldown = 24 * time.Hour
+
+// SendReminders handles reminding the invitees of an event who have not
+// answered its poll yet, except those reminded within the cooldown. The
+// reminders are queued and the response lists them.
+func (h *Handler) SendReminders(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	eventID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		h.log(r.Context()).Error("Invalid event ID", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid event ID")
+		return
+	}
+
+	event, _ := h.authorizeEvent(w, r, uint(eventID), actionManage)
+	if event == nil {
+		return
+	}
+	if !event.AcceptsAvailability() {
+		respondWithError(w, http.StatusConflict, "Event is "+string(event.Status)+" and no longer accepts availability")
+		return
+	}
+
+	availabilities, err := h.Repo.GetEventAvailabilities(r.Context(), event.ID)
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to get availabilities")
+		return
+	}
+
+	unanswered := notify.Unanswered(event, availabilities)
+	var notifications []models.Notification
+	err = h.Repo.Transaction(r.Context(), func(tx repository.Repository) error {
+		sent, err := tx.GetEventNotifications(r.Context(), event.ID)
+		if err != nil {
+			return err
+		}
+		due := notify.NotRemindedSince(unanswered, sent, time.Now().Add(-h.ReminderCooldown))
+		notifications, err = h.Notifications.QueueReminders(r.Context(), tx, event, due)
+		return err
+	})
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to queue reminders")
+		return
+	}
+
+	h.log(r.Context()).Info("Reminders queued successfully",
+		zap.Uint("event_id", event.ID),
+		zap.Int("count", len(notifications)),
+		zap.Int("skipped", len(unanswered)-len(notifications)))
+	respondWithJSON(w, http.StatusAccepted, notifications)
+}
+
+// GetNotifications handles listing the notifications sent about an event
+// with their delivery status
+func (h *Handler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	eventID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		h.log(r.Context()).Error("Invalid event ID", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid event ID")
+		return
+	}
+
+	if event, _ := h.authorizeEvent(w, r, uint(eventID), actionManage); event == nil {
+		return
+	}
+
+	notifications, err := h.Repo.GetEventNotifications(r.Context(), uint(eventID))
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to get notifications")
+		return
+	}
+
+	h.log(r.Context()).Info("Notifications retrieved successfully", zap.Uint64("event_id", eventID))
+	respondWithJSON(w, http.StatusOK, notifications)
+}
@@ -0,0 +1,309 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"github.com/tusharsingune/meeting-scheduler/internal/notify"
+)
+
+// recordNotifications makes a handler record the messages it sends
+func recordNotifications(handler *Handler, mockRepo *MockRepository) *notify.Recorder {
+	recorder := notify.NewRecorder()
+	handler.Notifications = notify.NewService(recorder, notify.DefaultTemplates(), mockRepo, handler.Log)
+	return recorder
+}
+
+// notifiedEvent is policyEvent with its invitees' participants loaded
+func notifiedEvent() *models.Event {
+	event := policyEvent()
+	for i := range event.Invitations {
+		id := event.Invitations[i].ParticipantID
+		event.Invitations[i].Participant = &models.Participant{ID: id, Name: "Invitee", Email: fmt.Sprintf("invitee%d@example.com", id)}
+	}
+	return event
+}
+
+func TestInviteParticipantQueuesInvitation(t *testing.T) {
+	tests := []struct {
+		name          string
+		participantID uint
+		wantQueued    bool
+	}{
+		{"new invitee", strangerID, true},
+		{"changing an invitee's role", inviteeID, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			handler := setupTestHandler(mockRepo)
+			recorder := recordNotifications(handler, mockRepo)
+
+			mockRepo.On("GetEvent", uint(1)).Return(notifiedEvent(), nil)
+			mockRepo.On("GetParticipant", tt.participantID).
+				Return(&models.Participant{ID: tt.participantID, Name: "Grace", Email: "grace@example.com"}, nil)
+			mockRepo.On("InviteParticipant", mock.Anything).Return(nil)
+
+			body := fmt.Sprintf(`{"participant_id":%d,"role":"optional"}`, tt.participantID)
+			req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+			req = asParticipant(mux.SetURLVars(req, map[string]string{"id": "1"}), organizerID)
+			w := httptest.NewRecorder()
+
+			handler.InviteParticipant(w, req)
+
+			require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+			assert.Empty(t, recorder.Messages(), "the request does not wait for the mail server")
+			queued := mock.MatchedBy(func(n *models.Notification) bool {
+				return n.Kind == "invitation" && n.ParticipantID == tt.participantID &&
+					n.Recipient == "grace@example.com" && n.Status == models.NotificationPending
+			})
+			if tt.wantQueued {
+				mockRepo.AssertCalled(t, "CreateNotification", queued)
+			} else {
+				mockRepo.AssertNotCalled(t, "CreateNotification", mock.Anything)
+			}
+		})
+	}
+}
+
+func TestConfirmEventQueuesConfirmations(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+	recorder := recordNotifications(handler, mockRepo)
+
+	mockRepo.On("GetEvent", uint(1)).Return(notifiedEvent(), nil)
+	mockRepo.On("TransitionEvent", mock.Anything, mock.Anything).Return(true, nil)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"time_slot_id":5}`))
+	req = asParticipant(mux.SetURLVars(req, map[string]string{"id": "1"}), organizerID)
+	w := httptest.NewRecorder()
+
+	handler.ConfirmEvent(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Empty(t, recorder.Messages(), "the request does not wait for the mail server")
+	for _, invitation := range notifiedEvent().Invitations {
+		mockRepo.AssertCalled(t, "CreateNotification", mock.MatchedBy(func(n *models.Notification) bool {
+			return n.Kind == "confirmation" && n.ParticipantID == invitation.ParticipantID && n.Status == models.NotificationPending
+		}))
+	}
+}
+
+func TestSendRemindersToUnansweredInvitees(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+	recorder := recordNotifications(handler, mockRepo)
+
+	mockRepo.On("GetEvent", uint(1)).Return(notifiedEvent(), nil)
+	mockRepo.On("GetEventAvailabilities", uint(1)).Return([]models.Availability{
+		{ParticipantID: coOrganizerID, TimeSlotID: 5, Status: models.AvailabilityYes},
+	}, nil)
+	mockRepo.On("GetEventNotifications", uint(1)).Return([]models.Notification{}, nil)
+
+	req := httptest.NewRequest("POST", "/", nil)
+	req = asParticipant(mux.SetURLVars(req, map[string]string{"id": "1"}), coOrganizerID)
+	w := httptest.NewRecorder()
+
+	handler.SendReminders(w, req)
+
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	var notifications []models.Notification
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &notifications))
+	require.Len(t, notifications, 1)
+	assert.Equal(t, inviteeID, notifications[0].ParticipantID)
+	assert.Equal(t, "reminder", notifications[0].Kind)
+	assert.Equal(t, models.NotificationPending, notifications[0].Status)
+	assert.Empty(t, recorder.Messages(), "the request does not wait for the mail server")
+	mockRepo.AssertCalled(t, "CreateNotification", mock.MatchedBy(func(n *models.Notification) bool {
+		return n.Kind == "reminder" && n.ParticipantID == inviteeID &&
+			n.Recipient == "invitee3@example.com" && n.Status == models.NotificationPending
+	}))
+}
+
+func TestSendRemindersSkipsRecentlyReminded(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name       string
+		previous   models.Notification
+		wantQueued bool
+	}{
+		{"reminded within the cooldown", models.Notification{Kind: "reminder", Status: models.NotificationSent, CreatedAt: now.Add(-time.Hour)}, false},
+		{"reminder still queued", models.Notification{Kind: "reminder", Status: models.NotificationPending, CreatedAt: now.Add(-time.Minute)}, false},
+		{"reminded before the cooldown", models.Notification{Kind: "reminder", Status: models.NotificationSent, CreatedAt: now.Add(-48 * time.Hour)}, true},
+		{"reminder failed", models.Notification{Kind: "reminder", Status: models.NotificationFailed, CreatedAt: now.Add(-time.Hour)}, true},
+		{"only invited", models.Notification{Kind: "invitation", Status: models.NotificationSent, CreatedAt: now.Add(-time.Hour)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			handler := setupTestHandler(mockRepo)
+			handler.ReminderCooldown = 24 * time.Hour
+
+			previous := tt.previous
+			previous.EventID, previous.ParticipantID = 1, inviteeID
+			mockRepo.On("GetEvent", uint(1)).Return(notifiedEvent(), nil)
+			mockRepo.On("GetEventAvailabilities", uint(1)).Return([]models.Availability{
+				{ParticipantID: coOrganizerID, TimeSlotID: 5, Status: models.AvailabilityYes},
+			}, nil)
+			mockRepo.On("GetEventNotifications", uint(1)).Return([]models.Notification{previous}, nil)
+
+			req := httptest.NewRequest("POST", "/", nil)
+			req = asParticipant(mux.SetURLVars(req, map[string]string{"id": "1"}), organizerID)
+			w := httptest.NewRecorder()
+
+			handler.SendReminders(w, req)
+
+			require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+			var notifications []models.Notification
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &notifications))
+			if tt.wantQueued {
+				assert.Len(t, notifications, 1)
+				mockRepo.AssertCalled(t, "CreateNotification", mock.Anything)
+			} else {
+				assert.Empty(t, notifications)
+				mockRepo.AssertNotCalled(t, "CreateNotification", mock.Anything)
+			}
+		})
+	}
+}
+
+func TestSendRemindersRequiresOrganizer(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+	recorder := recordNotifications(handler, mockRepo)
+
+	mockRepo.On("GetEvent", uint(1)).Return(notifiedEvent(), nil)
+
+	req := httptest.NewRequest("POST", "/", nil)
+	req = asParticipant(mux.SetURLVars(req, map[string]string{"id": "1"}), inviteeID)
+	w := httptest.NewRecorder()
+
+	handler.SendReminders(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, recorder.Messages())
+}
+
+func TestCreateEventRejectsPastPollDeadline(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	deadline := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	req := httptest.NewRequest("POST", "/events", strings.NewReader(`{"title":"Planning","duration":60,"poll_deadline":"`+deadline+`"}`))
+	req = asParticipant(req, organizerID)
+	w := httptest.NewRecorder()
+
+	handler.CreateEvent(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "poll_deadline")
+	mockRepo.AssertNotCalled(t, "CreateEvent", mock.Anything)
+}
+
+func TestClosedPoll(t *testing.T) {
+	closedEvent := func() *models.Event {
+		event := policyEvent()
+		event.Status = models.EventClosed
+		return event
+	}
+
+	t.Run("rejects answers", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		handler := setupTestHandler(mockRepo)
+		mockRepo.On("GetEvent", uint(1)).Return(closedEvent(), nil)
+
+		req := httptest.NewRequest("POST", "/", strings.NewReader(`{"participant_id":3,"time_slot_id":5,"status":"yes"}`))
+		req = asParticipant(mux.SetURLVars(req, map[string]string{"id": "1"}), inviteeID)
+		w := httptest.NewRecorder()
+
+		handler.SubmitAvailability(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("can be confirmed", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		handler := setupTestHandler(mockRepo)
+		mockRepo.On("GetEvent", uint(1)).Return(closedEvent(), nil)
+		mockRepo.On("TransitionEvent", mock.Anything, mock.Anything).Return(true, nil)
+
+		req := httptest.NewRequest("POST", "/", strings.NewReader(`{"time_slot_id":5}`))
+		req = asParticipant(mux.SetURLVars(req, map[string]string{"id": "1"}), organizerID)
+		w := httptest.NewRecorder()
+
+		handler.ConfirmEvent(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	})
+
+	t.Run("reopens with a new deadline", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		handler := setupTestHandler(mockRepo)
+		mockRepo.On("GetEvent", uint(1)).Return(closedEvent(), nil)
+		mockRepo.On("UpdateEvent", mock.MatchedBy(func(event *models.Event) bool {
+			return event.RemindedAt == nil
+		})).Return(nil)
+		mockRepo.On("TransitionEvent", mock.MatchedBy(func(event *models.Event) bool {
+			return event.Status == models.EventPolling && event.DecisionOutcome == ""
+		}), []models.EventStatus{models.EventClosed}).Return(true, nil)
+
+		deadline := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
+		req := httptest.NewRequest("PUT", "/", strings.NewReader(`{"title":"Planning","duration":60,"poll_deadline":"`+deadline+`"}`))
+		req = asParticipant(mux.SetURLVars(req, map[string]string{"id": "1"}), organizerID)
+		w := httptest.NewRecorder()
+
+		handler.UpdateEvent(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestCreateEventDecisionPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     string
+		wantStatus int
+		wantPolicy models.DecisionPolicy
+	}{
+		{"defaults to manual", "", http.StatusCreated, models.DecisionManual},
+		{"automatic", "all_required_available", http.StatusCreated, models.DecisionAllRequiredAvailable},
+		{"unknown", "coin_toss", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			handler := setupTestHandler(mockRepo)
+			mockRepo.On("CreateEvent", mock.MatchedBy(func(event *models.Event) bool {
+				return event.DecisionPolicy == tt.wantPolicy && event.DecisionOutcome == ""
+			})).Return(nil)
+
+			deadline := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
+			body := `{"title":"Planning","duration":60,"poll_deadline":"` + deadline + `","decision_policy":"` + tt.policy +
+				`","decision_outcome":"confirmed"}`
+			req := asParticipant(httptest.NewRequest("POST", "/events", strings.NewReader(body)), organizerID)
+			w := httptest.NewRecorder()
+
+			handler.CreateEvent(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantStatus == http.StatusBadRequest {
+				assert.Contains(t, w.Body.String(), "decision_policy")
+				mockRepo.AssertNotCalled(t, "CreateEvent", mock.Anything)
+			}
+		})
+	}
+}
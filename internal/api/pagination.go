@@ -0,0 +1,129 @@
+package api
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tusharsingune/meeting-scheduler/internal/middleware"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"github.com/tusharsingune/meeting-scheduler/internal/repository"
+)
+
+// listResponse is the envelope of every paginated listing. Clients fetch the
+// next page by passing NextCursor back as the cursor parameter; it is left
+// out on the last page.
+type listResponse struct {
+	Data       interface{} `json:"data"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// listOptions reads the limit, cursor and sort parameters of a listing
+func listOptions(r *http.Request) (repository.ListOptions, middleware.ValidationErrors) {
+	query := r.URL.Query()
+	opts := repository.ListOptions{
+		Cursor: query.Get("cursor"),
+		Sort:   query.Get("sort"),
+	}
+
+	var errs middleware.ValidationErrors
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > repository.MaxPageLimit {
+			errs = append(errs, middleware.ValidationError{
+				Field:   "limit",
+				Message: "limit must be a number from 1 to " + strconv.Itoa(repository.MaxPageLimit),
+			})
+		}
+		opts.Limit = n
+	}
+	return opts, errs
+}
+
+// eventFilter reads the parameters of the event listing
+func eventFilter(r *http.Request, caller *models.Participant) (repository.EventFilter, middleware.ValidationErrors) {
+	opts, errs := listOptions(r)
+	query := r.URL.Query()
+	filter := repository.EventFilter{ListOptions: opts, VisibleTo: caller.ID}
+
+	if organizer := query.Get("organizer_id"); organizer != "" {
+		id, err := strconv.ParseUint(organizer, 10, 32)
+		if err != nil {
+			errs = append(errs, middleware.ValidationError{Field: "organizer_id", Message: "organizer_id must be a participant ID"})
+		}
+		filter.OrganizerID = uint(id)
+	}
+
+	if statuses := query.Get("status"); statuses != "" {
+		for _, status := range strings.Split(statuses, ",") {
+			status := models.EventStatus(strings.TrimSpace(status))
+			switch status {
+			case models.EventDraft, models.EventPolling, models.EventClosed, models.EventConfirmed, models.EventCancelled:
+				filter.Statuses = append(filter.Statuses, status)
+			default:
+				errs = append(errs, middleware.ValidationError{
+					Field:   "status",
+					Message: "status must be draft, polling, closed, confirmed or cancelled",
+				})
+			}
+		}
+	}
+
+	for _, bound := range []struct {
+		name string
+		dest **time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		value := query.Get(bound.name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			errs = append(errs, middleware.ValidationError{Field: bound.name, Message: bound.name + " must be an RFC 3339 time"})
+			continue
+		}
+		*bound.dest = &t
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		errs = append(errs, middleware.ValidationError{Field: "to", Message: "to must be after from"})
+	}
+	return filter, errs
+}
+
+// participantFilter reads the parameters of the participant listing
+func participantFilter(r *http.Request, caller *models.Participant) (repository.ParticipantFilter, middleware.ValidationErrors) {
+	opts, errs := listOptions(r)
+	return repository.ParticipantFilter{ListOptions: opts, VisibleTo: caller.ID, EmailPrefix: r.URL.Query().Get("email")}, errs
+}
+
+// offsetPage returns the page of a list computed in full, such as the ranked
+// recommendations, that starts at the offset in the cursor. Its cursor is the
+// offset of the next page.
+func offsetPage[T any](items []T, opts repository.ListOptions) ([]T, string, bool) {
+	offset := 0
+	if opts.Cursor != "" {
+		decoded, err := base64.RawURLEncoding.DecodeString(opts.Cursor)
+		if err != nil {
+			return nil, "", false
+		}
+		offset, err = strconv.Atoi(strings.TrimPrefix(string(decoded), "offset:"))
+		if err != nil || offset < 0 || !strings.HasPrefix(string(decoded), "offset:") {
+			return nil, "", false
+		}
+	}
+	limit := opts.Limit
+	if limit == 0 {
+		limit = repository.DefaultPageLimit
+	}
+
+	if offset >= len(items) {
+		return []T{}, "", true
+	}
+	end := offset + limit
+	if end >= len(items) {
+		return items[offset:], "", true
+	}
+	return items[offset:end], base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(end))), true
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"github.com/tusharsingune/meeting-scheduler/internal/repository"
+)
+
+// page is a decoded list response
+type page[T any] struct {
+	Data       []T    `json:"data"`
+	NextCursor string `json:"next_cursor"`
+}
+
+func decodePage[T any](t *testing.T, w *httptest.ResponseRecorder) page[T] {
+	t.Helper()
+	var p page[T]
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&p))
+	return p
+}
+
+func TestListEvents(t *testing.T) {
+	mockRepo := new(MockRepository)
+	router := mux.NewRouter()
+	RegisterHandlers(router, mockRepo, testConfig())
+
+	from := time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2030, 7, 1, 0, 0, 0, 0, time.UTC)
+	mockRepo.On("ListEvents", repository.EventFilter{
+		ListOptions: repository.ListOptions{Limit: 2, Cursor: "abc", Sort: "-title"},
+		VisibleTo:   5,
+		OrganizerID: 5,
+		Statuses:    []models.EventStatus{models.EventPolling, models.EventClosed},
+		From:        &from,
+		To:          &to,
+	}).Return([]models.Event{{ID: 3, Title: "Retro", OrganizerId: 5}}, "def", nil)
+
+	req := httptest.NewRequest(http.MethodGet,
+		"/api/v1/events?limit=2&cursor=abc&sort=-title&organizer_id=5&status=polling,closed&from=2030-06-01T00:00:00Z&to=2030-07-01T00:00:00Z", nil)
+	authorize(t, mockRepo, req, 5)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	events := decodePage[models.Event](t, w)
+	require.Len(t, events.Data, 1)
+	assert.Equal(t, "Retro", events.Data[0].Title)
+	assert.Equal(t, "def", events.NextCursor)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListEventsRejectsInvalidParameters(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		field string
+	}{
+		{"limit too large", "limit=500", "limit"},
+		{"limit not a number", "limit=ten", "limit"},
+		{"unknown status", "status=polling,archived", "status"},
+		{"unparseable from", "from=yesterday", "from"},
+		{"empty range", "from=2030-06-02T00:00:00Z&to=2030-06-01T00:00:00Z", "to"},
+		{"organizer not an ID", "organizer_id=ada", "organizer_id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			handler := setupTestHandler(mockRepo)
+
+			req := asParticipant(httptest.NewRequest(http.MethodGet, "/events?"+tt.query, nil), 1)
+			w := httptest.NewRecorder()
+
+			handler.ListEvents(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			problem := decodeProblem(t, w)
+			require.Len(t, problem.Errors, 1)
+			assert.Equal(t, tt.field, problem.Errors[0].Field)
+			mockRepo.AssertNotCalled(t, "ListEvents", mock.Anything)
+		})
+	}
+}
+
+func TestListEventsWithUnknownSort(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+	mockRepo.On("ListEvents", mock.Anything).Return(nil, "", &repository.Error{
+		Kind:    repository.ErrValidation,
+		Message: `Cannot sort by "organizer"; sort by created_at, title`,
+	})
+
+	req := asParticipant(httptest.NewRequest(http.MethodGet, "/events?sort=organizer", nil), 1)
+	w := httptest.NewRecorder()
+
+	handler.ListEvents(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, decodeProblem(t, w).Detail, "sort by created_at, title")
+}
+
+func TestListParticipants(t *testing.T) {
+	mockRepo := new(MockRepository)
+	router := mux.NewRouter()
+	RegisterHandlers(router, mockRepo, testConfig())
+
+	mockRepo.On("ListParticipants", repository.ParticipantFilter{
+		ListOptions: repository.ListOptions{Sort: "email"},
+		VisibleTo:   1,
+		EmailPrefix: "ada@",
+	}).Return([]models.Participant{}, "", nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/participants?email=ada@&sort=email", nil)
+	authorize(t, mockRepo, req, 1)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `{"data":[]}`, w.Body.String(), "an empty page has no next cursor")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetRecommendationsPages(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	start := time.Date(2030, 6, 3, 9, 0, 0, 0, time.UTC)
+	var ranked []models.TimeSlotRecommendation
+	for i := 1; i <= 3; i++ {
+		slot := models.TimeSlot{ID: uint(i), EventID: 1, StartTime: start.Add(time.Duration(i) * time.Hour)}
+		slot.EndTime = slot.StartTime.Add(time.Hour)
+		ranked = append(ranked, models.TimeSlotRecommendation{TimeSlot: slot, Score: float64(4 - i)})
+	}
+	mockRepo.On("GetEvent", uint(1)).Return(&models.Event{ID: 1, OrganizerId: 1}, nil)
+	mockRepo.On("GetTimeSlotRecommendations", uint(1)).Return(ranked, nil)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/events/1/recommendations?"+query, nil)
+		req = asParticipant(mux.SetURLVars(req, map[string]string{"id": "1"}), 1)
+		w := httptest.NewRecorder()
+		handler.GetRecommendations(w, req)
+		return w
+	}
+
+	w := get("limit=2")
+	require.Equal(t, http.StatusOK, w.Code)
+	first := decodePage[models.TimeSlotRecommendation](t, w)
+	require.Len(t, first.Data, 2)
+	assert.Equal(t, uint(1), first.Data[0].TimeSlot.ID)
+	assert.Equal(t, uint(2), first.Data[1].TimeSlot.ID)
+	require.NotEmpty(t, first.NextCursor)
+
+	w = get("limit=2&cursor=" + first.NextCursor)
+	require.Equal(t, http.StatusOK, w.Code)
+	second := decodePage[models.TimeSlotRecommendation](t, w)
+	require.Len(t, second.Data, 1)
+	assert.Equal(t, uint(3), second.Data[0].TimeSlot.ID)
+	assert.Empty(t, second.NextCursor)
+
+	w = get("cursor=bm90LWFuLW9mZnNldA")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "cursor", decodeProblem(t, w).Errors[0].Field)
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"go.uber.org/zap"
+)
+
+// relation is how a participant is involved in an event
+type relation int
+
+const (
+	relationNone relation = iota
+	relationInvitee
+	relationCoOrganizer
+	relationOrganizer
+)
+
+// eventRelation returns how a participant is involved in an event. The
+// event's invitations must be loaded.
+func eventRelation(event *models.Event, participantID uint) relation {
+	if event.OrganizerId == participantID {
+		return relationOrganizer
+	}
+	for _, invitation := range event.Invitations {
+		if invitation.ParticipantID != participantID {
+			continue
+		}
+		if invitation.CoOrganizer {
+			return relationCoOrganizer
+		}
+		return relationInvitee
+	}
+	return relationNone
+}
+
+// eventAction is something a participant may be allowed to do with an event
+type eventAction string
+
+const (
+	actionView             eventAction = "view"              // read the event, its slots, invitations and answers
+	actionAnswer           eventAction = "answer"            // submit one's own availability
+	actionManage           eventAction = "manage"            // edit the event, its slots, invitees and lifecycle
+	actionDelete           eventAction = "delete"            // delete the event
+	actionManageOrganizers eventAction = "manage_organizers" // hand over the event or change its co-organizers
+)
+
+// permission lists who may perform an action and what everyone else is told
+type permission struct {
+	allowed []relation
+	denied  string
+}
+
+// eventPolicy is the authorization policy for events
+var eventPolicy = map[eventAction]permission{
+	actionView: {
+		allowed: []relation{relationOrganizer, relationCoOrganizer, relationInvitee},
+		denied:  "Only the organizers and invitees of this event can view it",
+	},
+	actionAnswer: {
+		allowed: []relation{relationOrganizer, relationCoOrganizer, relationInvitee},
+		denied:  "Only the organizers and invitees of this event can answer it",
+	},
+	actionManage: {
+		allowed: []relation{relationOrganizer, relationCoOrganizer},
+		denied:  "Only the organizers of this event can change it",
+	},
+	actionDelete: {
+		allowed: []relation{relationOrganizer},
+		denied:  "Only the organizer of this event can delete it",
+	},
+	actionManageOrganizers: {
+		allowed: []relation{relationOrganizer},
+		denied:  "Only the organizer of this event can change its organizers",
+	},
+}
+
+// can reports whether a participant may perform an action on an event
+func can(event *models.Event, participantID uint, action eventAction) bool {
+	rel := eventRelation(event, participantID)
+	for _, allowed := range eventPolicy[action].allowed {
+		if rel == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// forbid responds with 403 and the reason an action is not allowed
+func (h *Handler) forbid(w http.ResponseWriter, r *http.Request, participantID uint, eventID uint, message string) {
+	h.log(r.Context()).Warn("Forbidden",
+		zap.Uint("participant_id", participantID),
+		zap.Uint("event_id", eventID),
+		zap.String("reason", message))
+	respondWithError(w, http.StatusForbidden, message)
+}
+
+// authorizeEvent loads an event and checks that the caller may perform the
+// action on it. It responds with an error and returns nil when they may not.
+func (h *Handler) authorizeEvent(w http.ResponseWriter, r *http.Request, eventID uint, action eventAction) (*models.Event, *models.Participant) {
+	caller := currentParticipant(w, r)
+	if caller == nil {
+		return nil, nil
+	}
+
+	event, err := h.Repo.GetEvent(r.Context(), eventID)
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to get event")
+		return nil, nil
+	}
+
+	if !can(event, caller.ID, action) {
+		h.forbid(w, r, caller.ID, event.ID, eventPolicy[action].denied)
+		return nil, nil
+	}
+	return event, caller
+}
+
+// authorizeAnswer loads an event and checks that the caller may answer it on
+// behalf of the given participant, which is only ever themselves
+func (h *Handler) authorizeAnswer(w http.ResponseWriter, r *http.Request, eventID, participantID uint) *models.Event {
+	event, caller := h.authorizeEvent(w, r, eventID, actionAnswer)
+	if event == nil {
+		return nil
+	}
+	if participantID != caller.ID {
+		h.forbid(w, r, caller.ID, event.ID, "Participants can only answer for themselves")
+		return nil
+	}
+	return event
+}
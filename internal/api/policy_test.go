@@ -0,0 +1,185 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+)
+
+// Participants of policyEvent
+const (
+	organizerID   uint = 1
+	coOrganizerID uint = 2
+	inviteeID     uint = 3
+	strangerID    uint = 4
+)
+
+// policyEvent is organized by participant 1 with 2 as co-organizer and 3 invited
+func policyEvent() *models.Event {
+	start := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Hour)
+	return &models.Event{
+		ID:          1,
+		Title:       "Planning",
+		OrganizerId: organizerID,
+		Duration:    60,
+		Status:      models.EventPolling,
+		TimeSlots:   []models.TimeSlot{{ID: 5, EventID: 1, StartTime: start, EndTime: start.Add(time.Hour)}},
+		Invitations: []models.EventInvitation{
+			{EventID: 1, ParticipantID: coOrganizerID, CoOrganizer: true},
+			{EventID: 1, ParticipantID: inviteeID},
+		},
+	}
+}
+
+func TestEventPolicy(t *testing.T) {
+	event := policyEvent()
+
+	tests := []struct {
+		action  eventAction
+		allowed []uint
+	}{
+		{actionView, []uint{organizerID, coOrganizerID, inviteeID}},
+		{actionAnswer, []uint{organizerID, coOrganizerID, inviteeID}},
+		{actionManage, []uint{organizerID, coOrganizerID}},
+		{actionDelete, []uint{organizerID}},
+		{actionManageOrganizers, []uint{organizerID}},
+	}
+
+	for _, tt := range tests {
+		for _, participantID := range []uint{organizerID, coOrganizerID, inviteeID, strangerID} {
+			want := false
+			for _, id := range tt.allowed {
+				want = want || id == participantID
+			}
+			assert.Equal(t, want, can(event, participantID, tt.action), "participant %d, action %s", participantID, tt.action)
+		}
+	}
+}
+
+func TestHandlersEnforcePolicy(t *testing.T) {
+	type handlerFunc func(h *Handler) http.HandlerFunc
+
+	tests := []struct {
+		name     string
+		handler  handlerFunc
+		method   string
+		vars     map[string]string
+		body     string
+		caller   uint
+		expected int
+	}{
+		// Viewing
+		{"invitee views event", func(h *Handler) http.HandlerFunc { return h.GetEvent }, "GET", map[string]string{"id": "1"}, "", inviteeID, http.StatusOK},
+		{"stranger views event", func(h *Handler) http.HandlerFunc { return h.GetEvent }, "GET", map[string]string{"id": "1"}, "", strangerID, http.StatusForbidden},
+		{"stranger views recommendations", func(h *Handler) http.HandlerFunc { return h.GetRecommendations }, "GET", map[string]string{"id": "1"}, "", strangerID, http.StatusForbidden},
+
+		// Managing
+		{"organizer updates event", func(h *Handler) http.HandlerFunc { return h.UpdateEvent }, "PUT", map[string]string{"id": "1"}, `{"title":"Renamed","duration":60}`, organizerID, http.StatusOK},
+		{"co-organizer updates event", func(h *Handler) http.HandlerFunc { return h.UpdateEvent }, "PUT", map[string]string{"id": "1"}, `{"title":"Renamed","duration":60}`, coOrganizerID, http.StatusOK},
+		{"invitee updates event", func(h *Handler) http.HandlerFunc { return h.UpdateEvent }, "PUT", map[string]string{"id": "1"}, `{"title":"Renamed","duration":60}`, inviteeID, http.StatusForbidden},
+		{"stranger updates event", func(h *Handler) http.HandlerFunc { return h.UpdateEvent }, "PUT", map[string]string{"id": "1"}, `{"title":"Renamed","duration":60}`, strangerID, http.StatusForbidden},
+		{"co-organizer hands over event", func(h *Handler) http.HandlerFunc { return h.UpdateEvent }, "PUT", map[string]string{"id": "1"}, `{"title":"Planning","organizer_id":2,"duration":60}`, coOrganizerID, http.StatusForbidden},
+		{"organizer hands over event", func(h *Handler) http.HandlerFunc { return h.UpdateEvent }, "PUT", map[string]string{"id": "1"}, `{"title":"Planning","organizer_id":2,"duration":60}`, organizerID, http.StatusOK},
+		{"invitee adds time slot", func(h *Handler) http.HandlerFunc { return h.AddTimeSlot }, "POST", map[string]string{"id": "1"}, `{}`, inviteeID, http.StatusForbidden},
+		{"invitee confirms event", func(h *Handler) http.HandlerFunc { return h.ConfirmEvent }, "POST", map[string]string{"id": "1"}, `{"time_slot_id":5}`, inviteeID, http.StatusForbidden},
+		{"co-organizer confirms event", func(h *Handler) http.HandlerFunc { return h.ConfirmEvent }, "POST", map[string]string{"id": "1"}, `{"time_slot_id":5}`, coOrganizerID, http.StatusOK},
+		{"invitee cancels event", func(h *Handler) http.HandlerFunc { return h.CancelEvent }, "POST", map[string]string{"id": "1"}, "", inviteeID, http.StatusForbidden},
+
+		// Deleting
+		{"organizer deletes event", func(h *Handler) http.HandlerFunc { return h.DeleteEvent }, "DELETE", map[string]string{"id": "1"}, "", organizerID, http.StatusNoContent},
+		{"co-organizer deletes event", func(h *Handler) http.HandlerFunc { return h.DeleteEvent }, "DELETE", map[string]string{"id": "1"}, "", coOrganizerID, http.StatusForbidden},
+
+		// Invitations
+		{"co-organizer invites attendee", func(h *Handler) http.HandlerFunc { return h.InviteParticipant }, "POST", map[string]string{"id": "1"}, `{"participant_id":4}`, coOrganizerID, http.StatusCreated},
+		{"co-organizer appoints co-organizer", func(h *Handler) http.HandlerFunc { return h.InviteParticipant }, "POST", map[string]string{"id": "1"}, `{"participant_id":4,"co_organizer":true}`, coOrganizerID, http.StatusForbidden},
+		{"co-organizer demotes co-organizer", func(h *Handler) http.HandlerFunc { return h.InviteParticipant }, "POST", map[string]string{"id": "1"}, `{"participant_id":2}`, coOrganizerID, http.StatusForbidden},
+		{"organizer appoints co-organizer", func(h *Handler) http.HandlerFunc { return h.InviteParticipant }, "POST", map[string]string{"id": "1"}, `{"participant_id":4,"co_organizer":true}`, organizerID, http.StatusCreated},
+		{"invitee invites attendee", func(h *Handler) http.HandlerFunc { return h.InviteParticipant }, "POST", map[string]string{"id": "1"}, `{"participant_id":4}`, inviteeID, http.StatusForbidden},
+		{"co-organizer removes invitee", func(h *Handler) http.HandlerFunc { return h.RemoveInvitation }, "DELETE", map[string]string{"id": "1", "pid": "3"}, "", coOrganizerID, http.StatusNoContent},
+		{"co-organizer removes co-organizer", func(h *Handler) http.HandlerFunc { return h.RemoveInvitation }, "DELETE", map[string]string{"id": "1", "pid": "2"}, "", coOrganizerID, http.StatusForbidden},
+
+		// Answering
+		{"invitee answers for themselves", func(h *Handler) http.HandlerFunc { return h.SubmitAvailability }, "POST", map[string]string{"id": "1"}, `{"participant_id":3,"time_slot_id":5,"status":"yes"}`, inviteeID, http.StatusCreated},
+		{"invitee answers without participant", func(h *Handler) http.HandlerFunc { return h.SubmitAvailability }, "POST", map[string]string{"id": "1"}, `{"time_slot_id":5,"status":"yes"}`, inviteeID, http.StatusCreated},
+		{"invitee answers for someone else", func(h *Handler) http.HandlerFunc { return h.SubmitAvailability }, "POST", map[string]string{"id": "1"}, `{"participant_id":2,"time_slot_id":5,"status":"yes"}`, inviteeID, http.StatusForbidden},
+		{"organizer answers for invitee", func(h *Handler) http.HandlerFunc { return h.SubmitAvailability }, "POST", map[string]string{"id": "1"}, `{"participant_id":3,"time_slot_id":5,"status":"yes"}`, organizerID, http.StatusForbidden},
+		{"stranger answers for themselves", func(h *Handler) http.HandlerFunc { return h.SubmitAvailability }, "POST", map[string]string{"id": "1"}, `{"participant_id":4,"time_slot_id":5,"status":"yes"}`, strangerID, http.StatusForbidden},
+		{"invitee answers in bulk for someone else", func(h *Handler) http.HandlerFunc { return h.SubmitBulkAvailability }, "POST", map[string]string{"id": "1"}, `{"participant_id":2,"availabilities":[{"time_slot_id":5,"status":"no"}]}`, inviteeID, http.StatusForbidden},
+
+		// Calendars
+		{"participant exports someone else's calendar", func(h *Handler) http.HandlerFunc { return h.ExportParticipantCalendar }, "GET", map[string]string{"id": "3"}, "", strangerID, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			handler := setupTestHandler(mockRepo)
+
+			mockRepo.On("GetEvent", uint(1)).Return(policyEvent(), nil)
+			mockRepo.On("GetParticipant", mock.Anything).Return(&models.Participant{ID: strangerID}, nil)
+			mockRepo.On("GetTimeSlotRecommendations", uint(1)).Return([]models.TimeSlotRecommendation{}, nil)
+			writes := []string{"UpdateEvent", "DeleteEvent", "InviteParticipant", "UpsertAvailability"}
+			for _, method := range writes {
+				mockRepo.On(method, mock.Anything).Return(nil)
+			}
+			mockRepo.On("RemoveInvitation", mock.Anything, mock.Anything).Return(nil)
+			mockRepo.On("UpsertAvailabilities", mock.Anything, mock.Anything).Return(nil)
+			mockRepo.On("TransitionEvent", mock.Anything, mock.Anything).Return(true, nil)
+
+			req := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body))
+			req = asParticipant(mux.SetURLVars(req, tt.vars), tt.caller)
+			w := httptest.NewRecorder()
+
+			tt.handler(handler)(w, req)
+
+			assert.Equal(t, tt.expected, w.Code, w.Body.String())
+			if tt.expected == http.StatusForbidden {
+				for _, method := range writes {
+					mockRepo.AssertNotCalled(t, method, mock.Anything)
+				}
+				mockRepo.AssertNotCalled(t, "RemoveInvitation", mock.Anything, mock.Anything)
+				mockRepo.AssertNotCalled(t, "UpsertAvailabilities", mock.Anything, mock.Anything)
+				mockRepo.AssertNotCalled(t, "TransitionEvent", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestCreateEventAsSomeoneElse(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	req := httptest.NewRequest("POST", "/events", strings.NewReader(`{"title":"Planning","organizer_id":2,"duration":60}`))
+	req = asParticipant(req, organizerID)
+	w := httptest.NewRecorder()
+
+	handler.CreateEvent(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockRepo.AssertNotCalled(t, "CreateEvent", mock.Anything)
+}
+
+func TestCreateEventDefaultsOrganizerToCaller(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	mockRepo.On("CreateEvent", mock.MatchedBy(func(e *models.Event) bool {
+		return e.OrganizerId == coOrganizerID
+	})).Return(nil)
+
+	req := httptest.NewRequest("POST", "/events", strings.NewReader(`{"title":"Planning","duration":60}`))
+	req = asParticipant(req, coOrganizerID)
+	w := httptest.NewRecorder()
+
+	handler.CreateEvent(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tusharsingune/meeting-scheduler/internal/middleware"
+	"github.com/tusharsingune/meeting-scheduler/internal/repository"
+)
+
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) middleware.Problem {
+	t.Helper()
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	var problem middleware.Problem
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+	return problem
+}
+
+func TestRepositoryErrorsAsProblems(t *testing.T) {
+	sqlErr := errors.New(`ERROR: insert or update on table "events" violates foreign key constraint (SQLSTATE 23503)`)
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantDetail string
+	}{
+		{"not found", &repository.Error{Kind: repository.ErrNotFound, Message: "Participant not found"}, http.StatusNotFound, "Participant not found"},
+		{"conflict", &repository.Error{Kind: repository.ErrConflict, Message: "A participant already exists", Err: sqlErr}, http.StatusConflict, "A participant already exists"},
+		{"validation", &repository.Error{Kind: repository.ErrValidation, Message: "Participant has a value the database does not accept", Err: sqlErr}, http.StatusUnprocessableEntity, "Participant has a value the database does not accept"},
+		{"database failure", sqlErr, http.StatusInternalServerError, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			handler := setupTestHandler(mockRepo)
+			mockRepo.On("ListParticipants", mock.Anything).Return(nil, "", tt.err)
+
+			req := httptest.NewRequest(http.MethodGet, "/participants/9", nil)
+			req.Header.Set("X-Request-ID", "req-42")
+			req = asParticipant(mux.SetURLVars(req, map[string]string{"id": "9"}), 9)
+			w := httptest.NewRecorder()
+
+			middleware.RequestID(http.HandlerFunc(handler.GetParticipant)).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			problem := decodeProblem(t, w)
+			assert.Equal(t, tt.wantStatus, problem.Status)
+			assert.Equal(t, http.StatusText(tt.wantStatus), problem.Title)
+			assert.Equal(t, tt.wantDetail, problem.Detail)
+			assert.Equal(t, "req-42", problem.RequestID)
+			assert.NotContains(t, problem.Detail, "SQLSTATE", "database errors are not leaked")
+		})
+	}
+}
+
+func TestCreateParticipantWithTakenEmail(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+	mockRepo.On("CreateParticipant", mock.Anything).Return(&repository.Error{
+		Kind:    repository.ErrConflict,
+		Message: "A participant with this email already exists",
+		Err:     errors.New(`ERROR: duplicate key value violates unique constraint "participants_email_key" (SQLSTATE 23505)`),
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/participants",
+		strings.NewReader(`{"name":"Ada","email":"ada@example.com","time_zone":"UTC"}`))
+	w := httptest.NewRecorder()
+
+	middleware.RequestID(http.HandlerFunc(handler.CreateParticipant)).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	problem := decodeProblem(t, w)
+	assert.Equal(t, "A participant with this email already exists", problem.Detail)
+	assert.NotEmpty(t, problem.RequestID, "a request ID is generated when the client sends none")
+	assert.Equal(t, w.Header().Get("X-Request-ID"), problem.RequestID)
+	mockRepo.AssertNotCalled(t, "CreateAPIKey", mock.Anything)
+}
+
+func TestValidationErrorsAsProblem(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/participants", strings.NewReader(`{"name":"Ada","email":"ada@example.com","time_zone":"Mars/Olympus"}`))
+	w := httptest.NewRecorder()
+
+	handler.CreateParticipant(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	problem := decodeProblem(t, w)
+	require.NotEmpty(t, problem.Errors)
+	assert.Equal(t, "time_zone", problem.Errors[0].Field)
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/tusharsingune/meeting-scheduler/internal/middleware"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"github.com/tusharsingune/meeting-scheduler/internal/scheduling"
+	"go.uber.org/zap"
+)
+
+// maxListedOccurrences is how many upcoming occurrences an event response lists
+const maxListedOccurrences = 20
+
+// defaultSeriesTimeZone makes a recurring event without a time zone follow
+// its organizer's wall clock
+func (h *Handler) defaultSeriesTimeZone(ctx context.Context, event *models.Event) {
+	if !event.IsRecurring() || event.TimeZone != "" {
+		return
+	}
+	event.TimeZone = "UTC"
+	if organizer, err := h.Repo.GetParticipant(ctx, event.OrganizerId); err == nil && organizer.TimeZone != "" {
+		event.TimeZone = organizer.TimeZone
+	}
+}
+
+// attachOccurrences lists the upcoming occurrences of a confirmed recurring
+// event, looking as far ahead as the scheduling horizon from now or from the
+// start of a series that has not begun yet
+func (h *Handler) attachOccurrences(ctx context.Context, event *models.Event, now time.Time, loc *time.Location) {
+	if !event.IsRecurring() || event.ConfirmedTimeSlot == nil {
+		return
+	}
+	horizon := h.Horizon
+	if horizon <= 0 {
+		horizon = 365 * 24 * time.Hour
+	}
+	until := now.Add(horizon)
+	if start := event.ConfirmedTimeSlot.StartTime; start.After(now) {
+		until = start.Add(horizon)
+	}
+	occurrences, err := scheduling.Occurrences(*event, now, until)
+	if err != nil {
+		h.log(ctx).Error("Failed to expand recurrence", zap.Error(err), zap.Uint("event_id", event.ID))
+		return
+	}
+	if len(occurrences) > maxListedOccurrences {
+		occurrences = occurrences[:maxListedOccurrences]
+	}
+	if loc != nil {
+		scheduling.LocalizeOccurrences(occurrences, loc)
+	}
+	event.Occurrences = occurrences
+}
+
+// CreateOccurrenceException handles cancelling or moving a single occurrence
+// of a confirmed recurring event. A second exception for the same occurrence
+// replaces the first.
+func (h *Handler) CreateOccurrenceException(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	eventID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		h.log(r.Context()).Error("Invalid event ID", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid event ID")
+		return
+	}
+
+	var exception models.OccurrenceException
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&exception); err != nil {
+		h.log(r.Context()).Error("Failed to decode request body", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if errs := middleware.ValidateStruct(exception); len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+
+	event, _ := h.authorizeEvent(w, r, uint(eventID), actionManage)
+	if event == nil {
+		return
+	}
+	if !event.IsRecurring() {
+		respondWithError(w, http.StatusConflict, "Event does not recur")
+		return
+	}
+	if event.Status != models.EventConfirmed {
+		respondWithError(w, http.StatusConflict, "Event is "+string(event.Status)+"; only confirmed series have occurrences")
+		return
+	}
+
+	ok, err := scheduling.IsOccurrence(*event, exception.OccurrenceStart)
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to expand recurrence")
+		return
+	}
+	if !ok {
+		respondWithValidationErrors(w, middleware.ValidationErrors{{
+			Field:   "occurrence_start",
+			Message: "No occurrence of this event starts at this time",
+		}})
+		return
+	}
+
+	if exception.Cancelled {
+		exception.StartTime, exception.EndTime = nil, nil
+	} else {
+		var errs middleware.ValidationErrors
+		if !exception.EndTime.After(*exception.StartTime) {
+			errs = append(errs, middleware.ValidationError{Field: "end_time", Message: "End time must be after start time"})
+		}
+		if msg := h.checkSchedulingWindow(*exception.StartTime, time.Now()); msg != "" {
+			errs = append(errs, middleware.ValidationError{Field: "start_time", Message: msg})
+		}
+		if len(errs) > 0 {
+			respondWithValidationErrors(w, errs)
+			return
+		}
+	}
+
+	exception.ID = 0
+	exception.EventID = event.ID
+	exception.OccurrenceStart = exception.OccurrenceStart.UTC()
+	if err := h.Repo.UpsertOccurrenceException(r.Context(), &exception); err != nil {
+		h.respondWithFailure(w, r, err, "Failed to save occurrence exception")
+		return
+	}
+
+	h.log(r.Context()).Info("Occurrence exception saved successfully",
+		zap.Uint("event_id", event.ID),
+		zap.Time("occurrence_start", exception.OccurrenceStart),
+		zap.Bool("cancelled", exception.Cancelled))
+	respondWithJSON(w, http.StatusCreated, exception)
+}
+
+// DeleteOccurrenceException handles restoring an occurrence to its scheduled time
+func (h *Handler) DeleteOccurrenceException(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	eventID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		h.log(r.Context()).Error("Invalid event ID", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid event ID")
+		return
+	}
+
+	exceptionID, err := strconv.ParseUint(vars["eid"], 10, 32)
+	if err != nil {
+		h.log(r.Context()).Error("Invalid exception ID", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid exception ID")
+		return
+	}
+
+	if event, _ := h.authorizeEvent(w, r, uint(eventID), actionManage); event == nil {
+		return
+	}
+
+	if err := h.Repo.DeleteOccurrenceException(r.Context(), uint(eventID), uint(exceptionID)); err != nil {
+		h.respondWithFailure(w, r, err, "Failed to delete occurrence exception")
+		return
+	}
+
+	h.log(r.Context()).Info("Occurrence exception deleted successfully",
+		zap.Uint64("event_id", eventID),
+		zap.Uint64("exception_id", exceptionID))
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,227 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+)
+
+// confirmedSeries is a weekly event confirmed for Tuesdays at 10:00 in Berlin
+func confirmedSeries() *models.Event {
+	start := time.Date(2030, 6, 4, 8, 0, 0, 0, time.UTC)
+	slot := models.TimeSlot{ID: 4, EventID: 1, StartTime: start, EndTime: start.Add(30 * time.Minute)}
+	event := &models.Event{
+		ID:          1,
+		Title:       "Standup",
+		OrganizerId: 1,
+		Duration:    30,
+		Recurrence:  "FREQ=WEEKLY;BYDAY=TU",
+		TimeZone:    "Europe/Berlin",
+		TimeSlots:   []models.TimeSlot{slot},
+	}
+	event.Confirm(slot, start.Add(-48*time.Hour))
+	return event
+}
+
+func TestCreateRecurringEventUsesOrganizerTimeZone(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	mockRepo.On("GetParticipant", uint(1)).Return(&models.Participant{ID: 1, TimeZone: "America/New_York"}, nil)
+	mockRepo.On("CreateEvent", mock.MatchedBy(func(e *models.Event) bool {
+		return e.Recurrence == "FREQ=WEEKLY;BYDAY=TU" && e.TimeZone == "America/New_York"
+	})).Return(nil)
+
+	body, _ := json.Marshal(models.Event{Title: "Standup", OrganizerId: 1, Duration: 15, Recurrence: "FREQ=WEEKLY;BYDAY=TU"})
+	req := asParticipant(httptest.NewRequest("POST", "/events", bytes.NewBuffer(body)), 1)
+	w := httptest.NewRecorder()
+
+	handler.CreateEvent(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateEventRejectsInvalidRecurrence(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	body, _ := json.Marshal(models.Event{Title: "Standup", OrganizerId: 1, Duration: 15, Recurrence: "FREQ=HOURLY", TimeZone: "Mars/Olympus"})
+	req := asParticipant(httptest.NewRequest("POST", "/events", bytes.NewBuffer(body)), 1)
+	w := httptest.NewRecorder()
+
+	handler.CreateEvent(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"recurrence"`)
+	assert.Contains(t, w.Body.String(), `"field":"time_zone"`)
+	mockRepo.AssertNotCalled(t, "CreateEvent", mock.Anything)
+}
+
+func TestUpdateEventRejectsRecurrenceChangeAfterConfirmation(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	mockRepo.On("GetEvent", uint(1)).Return(confirmedSeries(), nil)
+
+	body, _ := json.Marshal(models.Event{Title: "Standup", OrganizerId: 1, Duration: 30, Recurrence: "FREQ=DAILY"})
+	req := httptest.NewRequest("PUT", "/events/1", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	vars := map[string]string{"id": "1"}
+	req = asParticipant(mux.SetURLVars(req, vars), 1)
+
+	handler.UpdateEvent(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockRepo.AssertNotCalled(t, "UpdateEvent", mock.Anything)
+}
+
+func TestGetEventListsOccurrences(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+	handler.Horizon = 0
+
+	event := confirmedSeries()
+	event.Exceptions = []models.OccurrenceException{
+		{ID: 1, EventID: 1, OccurrenceStart: time.Date(2030, 6, 11, 8, 0, 0, 0, time.UTC), Cancelled: true},
+	}
+	mockRepo.On("GetEvent", uint(1)).Return(event, nil)
+
+	req := httptest.NewRequest("GET", "/events/1", nil)
+	w := httptest.NewRecorder()
+
+	vars := map[string]string{"id": "1"}
+	req = asParticipant(mux.SetURLVars(req, vars), 1)
+
+	handler.GetEvent(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.Event
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Len(t, response.Occurrences, maxListedOccurrences)
+	assert.Len(t, response.Exceptions, 1)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateOccurrenceException(t *testing.T) {
+	moved := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Hour)
+	movedEnd := moved.Add(30 * time.Minute)
+
+	tests := []struct {
+		name         string
+		event        *models.Event
+		exception    models.OccurrenceException
+		expectedCode int
+	}{
+		{
+			name:         "cancel an occurrence",
+			event:        confirmedSeries(),
+			exception:    models.OccurrenceException{OccurrenceStart: time.Date(2030, 6, 11, 8, 0, 0, 0, time.UTC), Cancelled: true},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "move an occurrence",
+			event:        confirmedSeries(),
+			exception:    models.OccurrenceException{OccurrenceStart: time.Date(2030, 6, 18, 8, 0, 0, 0, time.UTC), StartTime: &moved, EndTime: &movedEnd},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "moving requires a new time",
+			event:        confirmedSeries(),
+			exception:    models.OccurrenceException{OccurrenceStart: time.Date(2030, 6, 18, 8, 0, 0, 0, time.UTC)},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "no occurrence at that time",
+			event:        confirmedSeries(),
+			exception:    models.OccurrenceException{OccurrenceStart: time.Date(2030, 6, 12, 8, 0, 0, 0, time.UTC), Cancelled: true},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "event that does not recur",
+			event:        confirmedEvent(),
+			exception:    models.OccurrenceException{OccurrenceStart: time.Date(2030, 6, 3, 10, 0, 0, 0, time.UTC), Cancelled: true},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "series still polling",
+			event:        &models.Event{ID: 1, OrganizerId: 1, Recurrence: "FREQ=WEEKLY", Status: models.EventPolling},
+			exception:    models.OccurrenceException{OccurrenceStart: time.Date(2030, 6, 11, 8, 0, 0, 0, time.UTC), Cancelled: true},
+			expectedCode: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			handler := setupTestHandler(mockRepo)
+
+			mockRepo.On("GetEvent", uint(1)).Return(tt.event, nil)
+			mockRepo.On("UpsertOccurrenceException", mock.MatchedBy(func(e *models.OccurrenceException) bool {
+				return e.EventID == 1 && e.OccurrenceStart.Equal(tt.exception.OccurrenceStart)
+			})).Return(nil)
+
+			body, _ := json.Marshal(tt.exception)
+			req := httptest.NewRequest("POST", "/events/1/exceptions", bytes.NewBuffer(body))
+			w := httptest.NewRecorder()
+
+			vars := map[string]string{"id": "1"}
+			req = asParticipant(mux.SetURLVars(req, vars), 1)
+
+			handler.CreateOccurrenceException(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedCode == http.StatusCreated {
+				mockRepo.AssertCalled(t, "UpsertOccurrenceException", mock.Anything)
+			} else {
+				mockRepo.AssertNotCalled(t, "UpsertOccurrenceException", mock.Anything)
+			}
+		})
+	}
+}
+
+func TestExportRecurringEventCalendar(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	moved := time.Date(2030, 6, 19, 12, 0, 0, 0, time.UTC)
+	movedEnd := moved.Add(30 * time.Minute)
+	event := confirmedSeries()
+	event.Exceptions = []models.OccurrenceException{
+		{OccurrenceStart: time.Date(2030, 6, 11, 8, 0, 0, 0, time.UTC), Cancelled: true},
+		{OccurrenceStart: time.Date(2030, 6, 18, 8, 0, 0, 0, time.UTC), StartTime: &moved, EndTime: &movedEnd},
+	}
+	mockRepo.On("GetEvent", uint(1)).Return(event, nil)
+	mockRepo.On("GetParticipant", uint(1)).Return(&models.Participant{ID: 1, Name: "Ada", Email: "ada@example.com"}, nil)
+	mockRepo.On("GetEventAvailabilities", uint(1)).Return([]models.Availability{}, nil)
+
+	req := httptest.NewRequest("GET", "/events/1.ics", nil)
+	w := httptest.NewRecorder()
+
+	vars := map[string]string{"id": "1"}
+	req = asParticipant(mux.SetURLVars(req, vars), 1)
+
+	handler.ExportEventCalendar(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	body := w.Body.String()
+	assert.Contains(t, body, "BEGIN:VTIMEZONE\r\nTZID:Europe/Berlin\r\n")
+	assert.Contains(t, body, "DTSTART;TZID=Europe/Berlin:20300604T100000\r\n")
+	assert.Contains(t, body, "RRULE:FREQ=WEEKLY;BYDAY=TU\r\n")
+	assert.Contains(t, body, "EXDATE;TZID=Europe/Berlin:20300611T100000\r\n")
+	assert.Contains(t, body, "RECURRENCE-ID;TZID=Europe/Berlin:20300618T100000\r\n")
+	assert.Contains(t, body, "DTSTART;TZID=Europe/Berlin:20300619T140000\r\n")
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tusharsingune/meeting-scheduler/internal/calendar"
+	"github.com/tusharsingune/meeting-scheduler/internal/middleware"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+)
+
+// validateTimeSlot checks a new time slot against its event and the slots
+// created along with it. Overlaps with the slots the event already has are
+// left to the repository, which checks them while holding a lock on the event.
+func (h *Handler) validateTimeSlot(slot models.TimeSlot, event *models.Event, existing []models.TimeSlot, now time.Time) middleware.ValidationErrors {
+	var errs middleware.ValidationErrors
+
+	if slot.StartTime.IsZero() {
+		errs = append(errs, middleware.ValidationError{Field: "start_time", Message: "This field is required"})
+	}
+	if slot.EndTime.IsZero() {
+		errs = append(errs, middleware.ValidationError{Field: "end_time", Message: "This field is required"})
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	if !slot.EndTime.After(slot.StartTime) {
+		errs = append(errs, middleware.ValidationError{Field: "end_time", Message: "End time must be after start time"})
+	} else if length := slot.EndTime.Sub(slot.StartTime); length < time.Duration(event.Duration)*time.Minute {
+		errs = append(errs, middleware.ValidationError{
+			Field:   "end_time",
+			Message: fmt.Sprintf("Time slot is shorter than the event duration of %d minutes", event.Duration),
+		})
+	}
+
+	if msg := h.checkSchedulingWindow(slot.StartTime, now); msg != "" {
+		errs = append(errs, middleware.ValidationError{Field: "start_time", Message: msg})
+	}
+
+	for _, other := range existing {
+		if slot.Overlaps(other) {
+			errs = append(errs, middleware.ValidationError{
+				Field: "start_time",
+				Message: fmt.Sprintf("Overlaps time slot %s to %s",
+					other.StartTime.UTC().Format(time.RFC3339), other.EndTime.UTC().Format(time.RFC3339)),
+			})
+		}
+	}
+
+	return errs
+}
+
+// validateTimeSlots checks the time slots of a new event against the event
+// and each other
+func (h *Handler) validateTimeSlots(event *models.Event, now time.Time) middleware.ValidationErrors {
+	var errs middleware.ValidationErrors
+	for i, slot := range event.TimeSlots {
+		for _, err := range h.validateTimeSlot(slot, event, event.TimeSlots[:i], now) {
+			err.Field = fmt.Sprintf("time_slots[%d].%s", i, err.Field)
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// checkSchedulingWindow returns why a start time cannot be scheduled, or an
+// empty string if it lies between now and the scheduling horizon
+func (h *Handler) checkSchedulingWindow(start, now time.Time) string {
+	if start.Before(now) {
+		return "Start time is in the past"
+	}
+	if h.Horizon > 0 && start.After(now.Add(h.Horizon)) {
+		return fmt.Sprintf("Start time is beyond the scheduling horizon of %d days", int(h.Horizon.Hours()/24))
+	}
+	return ""
+}
+
+// validateEvent checks the recurrence rule, time zone and decision policy of an event
+func validateEvent(event models.Event) middleware.ValidationErrors {
+	var errs middleware.ValidationErrors
+	if event.Recurrence != "" {
+		if _, err := calendar.ParseRecurrence(event.Recurrence); err != nil {
+			errs = append(errs, middleware.ValidationError{
+				Field:   "recurrence",
+				Message: "Invalid recurrence rule" + strings.TrimPrefix(err.Error(), "invalid recurrence rule"),
+			})
+		}
+	}
+	if event.TimeZone != "" {
+		if _, err := time.LoadLocation(event.TimeZone); err != nil {
+			errs = append(errs, middleware.ValidationError{Field: "time_zone", Message: "Unknown time zone"})
+		}
+	}
+	switch event.DecisionPolicy {
+	case "", models.DecisionManual, models.DecisionTopRecommendation, models.DecisionAllRequiredAvailable:
+	default:
+		errs = append(errs, middleware.ValidationError{
+			Field:   "decision_policy",
+			Message: "Decision policy must be manual, top_recommendation or all_required_available",
+		})
+	}
+	return errs
+}
+
+// equalTimes reports whether two optional times are both unset or the same instant
+func equalTimes(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
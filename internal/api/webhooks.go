@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/tusharsingune/meeting-scheduler/internal/middleware"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"github.com/tusharsingune/meeting-scheduler/internal/repository"
+	"github.com/tusharsingune/meeting-scheduler/internal/webhook"
+	"go.uber.org/zap"
+)
+
+// publish records a change to an event in the outbox for delivery to the
+// organizer's webhooks. Call it with the transaction making the change.
+func publish(ctx context.Context, tx repository.Repository, eventType string, event *models.Event, data interface{}) error {
+	outbox, err := webhook.NewOutboxEvent(eventType, event.OrganizerId, data, time.Now())
+	if err != nil {
+		return err
+	}
+	return tx.CreateOutboxEvent(ctx, outbox)
+}
+
+// publicHost reports whether a webhook URL's host may be public. Host names
+// are resolved, and checked again, only when a delivery connects.
+func publicHost(host string) bool {
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return false
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return webhook.PublicAddress(addr)
+	}
+	return true
+}
+
+// CreateWebhook handles registering a webhook that is told about changes to
+// the caller's events
+func (h *Handler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	participant := currentParticipant(w, r)
+	if participant == nil {
+		return
+	}
+
+	var req models.WebhookRequest
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&req); err != nil {
+		h.log(r.Context()).Error("Failed to decode request body", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	errs := middleware.ValidateStruct(req)
+	if u, err := url.Parse(req.URL); err == nil {
+		if u.Scheme != "http" && u.Scheme != "https" {
+			errs = append(errs, middleware.ValidationError{Field: "url", Message: "URL must use http or https"})
+		} else if !publicHost(u.Hostname()) {
+			errs = append(errs, middleware.ValidationError{Field: "url", Message: "URL must point to a public address"})
+		}
+	}
+	if len(errs) > 0 {
+		respondWithValidationErrors(w, errs)
+		return
+	}
+
+	hook := models.Webhook{
+		ParticipantID: participant.ID,
+		URL:           req.URL,
+		Secret:        req.Secret,
+		EventTypes:    req.EventTypes,
+	}
+	if err := h.Repo.CreateWebhook(r.Context(), &hook); err != nil {
+		h.respondWithFailure(w, r, err, "Failed to create webhook")
+		return
+	}
+
+	h.log(r.Context()).Info("Webhook created successfully",
+		zap.Uint("participant_id", participant.ID),
+		zap.Uint("webhook_id", hook.ID))
+	respondWithJSON(w, http.StatusCreated, hook)
+}
+
+// GetWebhooks handles listing the caller's webhooks
+func (h *Handler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	participant := currentParticipant(w, r)
+	if participant == nil {
+		return
+	}
+
+	hooks, err := h.Repo.GetParticipantWebhooks(r.Context(), participant.ID)
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to get webhooks")
+		return
+	}
+
+	h.log(r.Context()).Info("Webhooks retrieved successfully", zap.Uint("participant_id", participant.ID))
+	respondWithJSON(w, http.StatusOK, hooks)
+}
+
+// DeleteWebhook handles removing one of the caller's webhooks
+func (h *Handler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	participant := currentParticipant(w, r)
+	if participant == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		h.log(r.Context()).Error("Invalid webhook ID", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	if err := h.Repo.DeleteWebhook(r.Context(), participant.ID, uint(id)); err != nil {
+		h.respondWithFailure(w, r, err, "Failed to delete webhook")
+		return
+	}
+
+	h.log(r.Context()).Info("Webhook deleted successfully",
+		zap.Uint("participant_id", participant.ID),
+		zap.Uint64("webhook_id", id))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetWebhookDeliveries handles reading the delivery log of one of the
+// caller's webhooks, newest first
+func (h *Handler) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	participant := currentParticipant(w, r)
+	if participant == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		h.log(r.Context()).Error("Invalid webhook ID", zap.Error(err))
+		respondWithError(w, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	deliveries, err := h.Repo.GetWebhookDeliveries(r.Context(), participant.ID, uint(id))
+	if err != nil {
+		h.respondWithFailure(w, r, err, "Failed to get webhook deliveries")
+		return
+	}
+
+	h.log(r.Context()).Info("Webhook deliveries retrieved successfully",
+		zap.Uint("participant_id", participant.ID),
+		zap.Uint64("webhook_id", id))
+	respondWithJSON(w, http.StatusOK, deliveries)
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"github.com/tusharsingune/meeting-scheduler/internal/webhook"
+)
+
+func TestCreateWebhook(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantField  string
+	}{
+		{"valid", `{"url":"https://example.com/hook","secret":"0123456789abcdef","event_types":["event.confirmed"]}`, http.StatusCreated, ""},
+		{"all event types", `{"url":"https://example.com/hook","secret":"0123456789abcdef"}`, http.StatusCreated, ""},
+		{"short secret", `{"url":"https://example.com/hook","secret":"short"}`, http.StatusBadRequest, "secret"},
+		{"unknown event type", `{"url":"https://example.com/hook","secret":"0123456789abcdef","event_types":["event.exploded"]}`, http.StatusBadRequest, "event_types[0]"},
+		{"not http", `{"url":"ftp://example.com/hook","secret":"0123456789abcdef"}`, http.StatusBadRequest, "url"},
+		{"loopback", `{"url":"http://127.0.0.1:8080/hook","secret":"0123456789abcdef"}`, http.StatusBadRequest, "url"},
+		{"localhost", `{"url":"http://localhost/hook","secret":"0123456789abcdef"}`, http.StatusBadRequest, "url"},
+		{"metadata service", `{"url":"http://169.254.169.254/latest","secret":"0123456789abcdef"}`, http.StatusBadRequest, "url"},
+		{"private network", `{"url":"https://[fd00::1]/hook","secret":"0123456789abcdef"}`, http.StatusBadRequest, "url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			handler := setupTestHandler(mockRepo)
+			mockRepo.On("CreateWebhook", mock.MatchedBy(func(hook *models.Webhook) bool {
+				return hook.ParticipantID == organizerID
+			})).Return(nil)
+
+			req := asParticipant(httptest.NewRequest("POST", "/webhooks", strings.NewReader(tt.body)), organizerID)
+			w := httptest.NewRecorder()
+
+			handler.CreateWebhook(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantField != "" {
+				assert.Contains(t, w.Body.String(), `"field":"`+tt.wantField+`"`)
+				mockRepo.AssertNotCalled(t, "CreateWebhook", mock.Anything)
+			} else {
+				assert.NotContains(t, w.Body.String(), "0123456789abcdef", "the secret is never returned")
+			}
+		})
+	}
+}
+
+func TestConfirmEventPublishesWebhook(t *testing.T) {
+	mockRepo := new(MockRepository)
+	handler := setupTestHandler(mockRepo)
+
+	mockRepo.On("GetEvent", uint(1)).Return(policyEvent(), nil)
+	mockRepo.On("TransitionEvent", mock.Anything, mock.Anything).Return(true, nil)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"time_slot_id":5}`))
+	req = asParticipant(mux.SetURLVars(req, map[string]string{"id": "1"}), organizerID)
+	w := httptest.NewRecorder()
+
+	handler.ConfirmEvent(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	mockRepo.AssertCalled(t, "CreateOutboxEvent", mock.MatchedBy(func(event *models.OutboxEvent) bool {
+		return event.Type == webhook.EventConfirmed && event.OrganizerID == organizerID &&
+			strings.Contains(event.Payload, `"status":"confirmed"`)
+	}))
+}
+
+func TestFailedOutboxFailsTheChange(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockRepo.On("CreateOutboxEvent", mock.Anything).Return(errors.New("database down"))
+	handler := setupTestHandler(mockRepo)
+
+	mockRepo.On("GetEvent", uint(1)).Return(policyEvent(), nil)
+	mockRepo.On("TransitionEvent", mock.Anything, mock.Anything).Return(true, nil)
+
+	req := httptest.NewRequest("POST", "/", nil)
+	req = asParticipant(mux.SetURLVars(req, map[string]string{"id": "1"}), organizerID)
+	w := httptest.NewRecorder()
+
+	handler.CancelEvent(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestGetWebhookDeliveries(t *testing.T) {
+	mockRepo := new(MockRepository)
+	router := mux.NewRouter()
+	RegisterHandlers(router, mockRepo, testConfig())
+
+	mockRepo.On("GetWebhookDeliveries", organizerID, uint(2)).Return([]models.WebhookDelivery{
+		{ID: 4, WebhookID: 2, EventType: webhook.EventCreated, Status: models.WebhookDeliveryDelivered, Attempts: 1},
+	}, nil)
+
+	req := httptest.NewRequest("GET", "/api/v1/webhooks/2/deliveries", nil)
+	authorize(t, mockRepo, req, organizerID)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"status":"delivered"`)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// APIKeyPrefix starts every API key, telling keys apart from bearer tokens
+const APIKeyPrefix = "msk_"
+
+// apiKeyDisplayLength is how much of a key is kept to tell keys apart in listings
+const apiKeyDisplayLength = len(APIKeyPrefix) + 6
+
+// InvitationTokenPrefix starts every invitation link token
+const InvitationTokenPrefix = "msi_"
+
+// GenerateAPIKey returns a new random API key
+func GenerateAPIKey() (string, error) {
+	return generate(APIKeyPrefix)
+}
+
+// GenerateInvitationToken returns a new random token for an invitation link.
+// Only its hash is stored, as for API keys, so links neither depend on the
+// signing key nor can be rebuilt from the database.
+func GenerateInvitationToken() (string, error) {
+	return generate(InvitationTokenPrefix)
+}
+
+// generate returns a random credential starting with prefix
+func generate(prefix string) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return prefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// IsAPIKey reports whether a credential looks like an API key rather than a token
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
+
+// HashAPIKey returns the hash an API key is stored and looked up by. Keys are
+// long and random, so a single SHA-256 is enough to make a leaked hash useless.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// HashInvitationToken returns the hash an invitation link is stored and
+// looked up by
+func HashInvitationToken(token string) string {
+	return HashAPIKey(token)
+}
+
+// APIKeyDisplayPrefix returns the start of a key, which is safe to show
+func APIKeyDisplayPrefix(key string) string {
+	if len(key) < apiKeyDisplayLength {
+		return key
+	}
+	return key[:apiKeyDisplayLength]
+}
@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignerRoundTrip(t *testing.T) {
+	signer, err := NewSigner([]byte("local-signing-key-for-tests"))
+	require.NoError(t, err)
+
+	now := time.Date(2030, 6, 3, 12, 0, 0, 0, time.UTC)
+	token, issued := signer.Issue(42, now, time.Hour)
+
+	claims, err := signer.Verify(token, now.Add(59*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, uint(42), claims.ParticipantID)
+	assert.True(t, claims.IssuedAt.Equal(now))
+	assert.True(t, claims.ExpiresAt.Equal(issued.ExpiresAt))
+
+	_, err = signer.Verify(token, now.Add(time.Hour))
+	assert.ErrorIs(t, err, ErrTokenExpired)
+}
+
+func TestSignerRejectsTamperedTokens(t *testing.T) {
+	signer, err := NewSigner([]byte("local-signing-key-for-tests"))
+	require.NoError(t, err)
+	other, err := NewSigner([]byte("another-signing-key-for-tests"))
+	require.NoError(t, err)
+
+	now := time.Date(2030, 6, 3, 12, 0, 0, 0, time.UTC)
+	token, _ := signer.Issue(42, now, time.Hour)
+	parts := strings.Split(token, ".")
+
+	// A payload claiming another participant, signed by nobody
+	forged := signer.Sign(Claims{ParticipantID: 1, IssuedAt: now, ExpiresAt: now.Add(time.Hour)})
+	swapped := parts[0] + "." + strings.Split(forged, ".")[1] + "." + parts[2]
+
+	// An unsigned token, which some JWT libraries accept
+	unsigned := "eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0." + parts[1] + "."
+
+	foreign, _ := other.Issue(42, now, time.Hour)
+
+	for name, token := range map[string]string{
+		"swapped payload": swapped,
+		"unsigned":        unsigned,
+		"other key":       foreign,
+		"not a token":     "abc",
+		"empty":           "",
+	} {
+		_, err := signer.Verify(token, now)
+		assert.ErrorIs(t, err, ErrInvalidToken, name)
+	}
+}
+
+func TestNewSignerRejectsShortKeys(t *testing.T) {
+	_, err := NewSigner([]byte("short"))
+	assert.Error(t, err)
+}
+
+func TestAPIKeys(t *testing.T) {
+	key, err := GenerateAPIKey()
+	require.NoError(t, err)
+	other, err := GenerateAPIKey()
+	require.NoError(t, err)
+
+	assert.True(t, IsAPIKey(key))
+	assert.NotEqual(t, key, other)
+	assert.Len(t, HashAPIKey(key), 64)
+	assert.Equal(t, HashAPIKey(key), HashAPIKey(key))
+	assert.NotEqual(t, HashAPIKey(key), HashAPIKey(other))
+	assert.True(t, strings.HasPrefix(key, APIKeyDisplayPrefix(key)))
+	assert.Less(t, len(APIKeyDisplayPrefix(key)), len(key)/2)
+}
+
+func TestInvitationTokens(t *testing.T) {
+	token, err := GenerateInvitationToken()
+	require.NoError(t, err)
+	other, err := GenerateInvitationToken()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(token, InvitationTokenPrefix))
+	assert.False(t, IsAPIKey(token), "an invitation token is not an API key")
+	assert.NotEqual(t, token, other)
+	assert.Len(t, HashInvitationToken(token), 64)
+	assert.NotEqual(t, HashInvitationToken(token), HashInvitationToken(other))
+}
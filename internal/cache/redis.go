@@ -0,0 +1,64 @@
+// Package cache stores values shared by the servers of a deployment in Redis
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/tusharsingune/meeting-scheduler/internal/config"
+)
+
+// Redis is a cache kept in a Redis server
+type Redis struct {
+	client *redis.Client
+}
+
+// NewRedis connects to the Redis server in the configuration and checks that
+// it answers
+func NewRedis(ctx context.Context, cfg config.RedisConfig) (*Redis, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Addr,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return &Redis{client: client}, nil
+}
+
+// Get returns the value stored under key, and false when there is none
+func (c *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set stores value under key for ttl
+func (c *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, key, value, ttl).Err()
+}
+
+// Delete removes the values stored under keys
+func (c *Redis) Delete(ctx context.Context, keys ...string) error {
+	return c.client.Del(ctx, keys...).Err()
+}
+
+// Incr increments the integer stored under key, which starts at zero and
+// does not expire, and returns the result
+func (c *Redis) Incr(ctx context.Context, key string) (int64, error) {
+	return c.client.Incr(ctx, key).Result()
+}
+
+// Close closes the connections to the server
+func (c *Redis) Close() error {
+	return c.client.Close()
+}
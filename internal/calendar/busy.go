@@ -0,0 +1,226 @@
+package calendar
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Period is a span of time from Start up to, but not including, End
+type Period struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Overlaps reports whether the period shares any time with the one from start to end
+func (p Period) Overlaps(start, end time.Time) bool {
+	return p.Start.Before(end) && start.Before(p.End)
+}
+
+// BusyTimes reads iCalendar data and returns the periods between from and to
+// in which the calendar's owner is busy, merged and in order. Busy periods are
+// the occurrences of VEVENTs, except cancelled and transparent (free) ones,
+// and the busy periods of VFREEBUSY components. Floating times and all-day
+// events are read in loc.
+func BusyTimes(r io.Reader, from, to time.Time, loc *time.Location) ([]Period, error) {
+	root, err := parse(r)
+	if err != nil {
+		return nil, err
+	}
+	z, err := newZones(root, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	var events, freeBusy []*component
+	root.walk(func(c *component) {
+		switch c.Name {
+		case "VEVENT":
+			events = append(events, c)
+		case "VFREEBUSY":
+			freeBusy = append(freeBusy, c)
+		}
+	})
+
+	// Moved or changed instances of a recurring event are separate VEVENTs
+	// with a RECURRENCE-ID, and replace the occurrence they identify
+	overridden := make(map[string]map[int64]bool)
+	for _, event := range events {
+		rid := event.get("RECURRENCE-ID")
+		if rid == nil {
+			continue
+		}
+		t, _, err := z.parseTime(rid.Value, rid.Params)
+		if err != nil {
+			return nil, fmt.Errorf("%w: RECURRENCE-ID: %v", ErrMalformed, err)
+		}
+		uid := uidOf(event)
+		if overridden[uid] == nil {
+			overridden[uid] = make(map[int64]bool)
+		}
+		overridden[uid][t.Unix()] = true
+	}
+
+	var busy []Period
+	for _, event := range events {
+		periods, err := eventPeriods(event, z, overridden[uidOf(event)], from, to)
+		if err != nil {
+			return nil, fmt.Errorf("%w: VEVENT %q: %v", ErrMalformed, uidOf(event), err)
+		}
+		busy = append(busy, periods...)
+	}
+	for _, fb := range freeBusy {
+		periods, err := freeBusyPeriods(fb, z, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("%w: VFREEBUSY: %v", ErrMalformed, err)
+		}
+		busy = append(busy, periods...)
+	}
+
+	return merge(busy), nil
+}
+
+func uidOf(c *component) string {
+	if uid := c.get("UID"); uid != nil {
+		return uid.Value
+	}
+	return ""
+}
+
+// eventPeriods returns the occurrences of a VEVENT between from and to,
+// leaving out excluded and overridden ones
+func eventPeriods(c *component, z *zones, overridden map[int64]bool, from, to time.Time) ([]Period, error) {
+	if status := c.get("STATUS"); status != nil && strings.EqualFold(status.Value, StatusCancelled) {
+		return nil, nil
+	}
+	if transp := c.get("TRANSP"); transp != nil && strings.EqualFold(transp.Value, "TRANSPARENT") {
+		return nil, nil
+	}
+
+	dtstart := c.get("DTSTART")
+	if dtstart == nil {
+		return nil, fmt.Errorf("missing DTSTART")
+	}
+	start, allDay, err := z.parseTime(dtstart.Value, dtstart.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	var duration time.Duration
+	switch {
+	case c.get("DTEND") != nil:
+		dtend := c.get("DTEND")
+		end, _, err := z.parseTime(dtend.Value, dtend.Params)
+		if err != nil {
+			return nil, err
+		}
+		duration = end.Sub(start)
+	case c.get("DURATION") != nil:
+		if duration, err = parseDuration(c.get("DURATION").Value); err != nil {
+			return nil, err
+		}
+	case allDay:
+		duration = 24 * time.Hour
+	}
+	if duration <= 0 {
+		return nil, nil
+	}
+
+	starts := []time.Time{start}
+	if rrule := c.get("RRULE"); rrule != nil {
+		rule, err := ParseRecurrence(rrule.Value)
+		if err != nil {
+			return nil, err
+		}
+		starts = rule.Between(start, from.Add(-duration), to)
+	}
+	for _, rdate := range c.all("RDATE") {
+		if strings.EqualFold(rdate.Params["VALUE"], "PERIOD") {
+			continue
+		}
+		times, err := z.parseTimes(rdate)
+		if err != nil {
+			return nil, err
+		}
+		starts = append(starts, times...)
+	}
+
+	excluded := make(map[int64]bool)
+	for _, exdate := range c.all("EXDATE") {
+		times, err := z.parseTimes(exdate)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range times {
+			excluded[t.Unix()] = true
+		}
+	}
+
+	// The overriding instances themselves carry a RECURRENCE-ID and are kept
+	isOverride := c.get("RECURRENCE-ID") != nil
+
+	var periods []Period
+	for _, s := range starts {
+		if excluded[s.Unix()] || (!isOverride && overridden[s.Unix()]) {
+			continue
+		}
+		p := Period{Start: s, End: s.Add(duration)}
+		if p.Overlaps(from, to) {
+			periods = append(periods, p)
+		}
+	}
+	return periods, nil
+}
+
+// freeBusyPeriods returns the busy periods of a VFREEBUSY between from and to
+func freeBusyPeriods(c *component, z *zones, from, to time.Time) ([]Period, error) {
+	var periods []Period
+	for _, fb := range c.all("FREEBUSY") {
+		if strings.EqualFold(fb.Params["FBTYPE"], "FREE") {
+			continue
+		}
+		for _, value := range strings.Split(fb.Value, ",") {
+			startValue, endValue, ok := strings.Cut(value, "/")
+			if !ok {
+				return nil, fmt.Errorf("invalid period %q", value)
+			}
+			start, _, err := z.parseTime(startValue, fb.Params)
+			if err != nil {
+				return nil, err
+			}
+			var end time.Time
+			if strings.HasPrefix(strings.TrimLeft(endValue, "+-"), "P") {
+				d, err := parseDuration(endValue)
+				if err != nil {
+					return nil, err
+				}
+				end = start.Add(d)
+			} else if end, _, err = z.parseTime(endValue, fb.Params); err != nil {
+				return nil, err
+			}
+			p := Period{Start: start, End: end}
+			if end.After(start) && p.Overlaps(from, to) {
+				periods = append(periods, p)
+			}
+		}
+	}
+	return periods, nil
+}
+
+// merge sorts periods and joins those that overlap or touch
+func merge(periods []Period) []Period {
+	sort.Slice(periods, func(i, j int) bool { return periods[i].Start.Before(periods[j].Start) })
+	var merged []Period
+	for _, p := range periods {
+		if n := len(merged); n > 0 && !p.Start.After(merged[n-1].End) {
+			if p.End.After(merged[n-1].End) {
+				merged[n-1].End = p.End
+			}
+			continue
+		}
+		merged = append(merged, p)
+	}
+	return merged
+}
@@ -0,0 +1,160 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func busyTimes(t *testing.T, ics string, from, to time.Time) []Period {
+	t.Helper()
+	periods, err := BusyTimes(strings.NewReader(ics), from, to, time.UTC)
+	require.NoError(t, err)
+	return periods
+}
+
+func TestBusyTimesEvents(t *testing.T) {
+	ics := `BEGIN:VCALENDAR
+VERSION:2.0
+BEGIN:VEVENT
+UID:standup
+DTSTART;TZID=Europe/Berlin:20300603T090000
+DTEND;TZID=Europe/Berlin:20300603T093000
+RRULE:FREQ=DAILY;COUNT=5
+EXDATE;TZID=Europe/Berlin:20300605T090000
+END:VEVENT
+BEGIN:VEVENT
+UID:standup
+RECURRENCE-ID;TZID=Europe/Berlin:20300606T090000
+DTSTART;TZID=Europe/Berlin:20300606T140000
+DURATION:PT30M
+END:VEVENT
+BEGIN:VEVENT
+UID:lunch
+DTSTART:20300604T110000Z
+DTEND:20300604T120000Z
+TRANSP:TRANSPARENT
+END:VEVENT
+BEGIN:VEVENT
+UID:cancelled
+DTSTART:20300604T130000Z
+DTEND:20300604T140000Z
+STATUS:CANCELLED
+END:VEVENT
+END:VCALENDAR
+`
+	from := time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2030, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	periods := busyTimes(t, ics, from, to)
+
+	var got []string
+	for _, p := range periods {
+		got = append(got, p.Start.UTC().Format(time.RFC3339)+"/"+p.End.UTC().Format("15:04"))
+	}
+	assert.Equal(t, []string{
+		"2030-06-03T07:00:00Z/07:30",
+		"2030-06-04T07:00:00Z/07:30",
+		"2030-06-06T12:00:00Z/12:30",
+		"2030-06-07T07:00:00Z/07:30",
+	}, got)
+}
+
+func TestBusyTimesAllDayAndFloating(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:holiday\r\n" +
+		"DTSTART;VALUE=DATE:20300603\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:dentist\r\n" +
+		"DTSTART:20300605T150000\r\n" +
+		"DTEND:20300605T1\r\n" +
+		" 60000\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	periods, err := BusyTimes(strings.NewReader(ics),
+		time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2030, 6, 30, 0, 0, 0, 0, time.UTC), tokyo)
+	require.NoError(t, err)
+
+	require.Len(t, periods, 2)
+	assert.Equal(t, time.Date(2030, 6, 3, 0, 0, 0, 0, tokyo).Unix(), periods[0].Start.Unix())
+	assert.Equal(t, time.Date(2030, 6, 4, 0, 0, 0, 0, tokyo).Unix(), periods[0].End.Unix())
+	assert.Equal(t, time.Date(2030, 6, 5, 15, 0, 0, 0, tokyo).Unix(), periods[1].Start.Unix())
+	assert.Equal(t, time.Hour, periods[1].End.Sub(periods[1].Start))
+}
+
+func TestBusyTimesFreeBusy(t *testing.T) {
+	ics := `BEGIN:VCALENDAR
+BEGIN:VFREEBUSY
+FREEBUSY:20300603T090000Z/20300603T100000Z,20300603T093000Z/PT1H
+FREEBUSY;FBTYPE=FREE:20300603T120000Z/20300603T130000Z
+FREEBUSY;FBTYPE=BUSY-TENTATIVE:20300604T090000Z/PT15M
+END:VFREEBUSY
+END:VCALENDAR
+`
+	periods := busyTimes(t, ics, time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2030, 6, 30, 0, 0, 0, 0, time.UTC))
+
+	require.Len(t, periods, 2)
+	assert.Equal(t, time.Date(2030, 6, 3, 9, 0, 0, 0, time.UTC), periods[0].Start)
+	assert.Equal(t, time.Date(2030, 6, 3, 10, 30, 0, 0, time.UTC), periods[0].End)
+	assert.Equal(t, 15*time.Minute, periods[1].End.Sub(periods[1].Start))
+}
+
+func TestBusyTimesCustomTimeZone(t *testing.T) {
+	// Outlook names zones after Windows and defines them with VTIMEZONE
+	ics := `BEGIN:VCALENDAR
+BEGIN:VTIMEZONE
+TZID:W. Europe Standard Time
+BEGIN:STANDARD
+DTSTART:16010101T030000
+TZOFFSETFROM:+0200
+TZOFFSETTO:+0100
+RRULE:FREQ=YEARLY;BYDAY=-1SU;BYMONTH=10
+END:STANDARD
+BEGIN:DAYLIGHT
+DTSTART:16010101T020000
+TZOFFSETFROM:+0100
+TZOFFSETTO:+0200
+RRULE:FREQ=YEARLY;BYDAY=-1SU;BYMONTH=3
+END:DAYLIGHT
+END:VTIMEZONE
+BEGIN:VEVENT
+UID:winter
+DTSTART;TZID=W. Europe Standard Time:20300107T090000
+DTEND;TZID=W. Europe Standard Time:20300107T100000
+END:VEVENT
+BEGIN:VEVENT
+UID:summer
+DTSTART;TZID="W. Europe Standard Time":20300701T090000
+DTEND;TZID="W. Europe Standard Time":20300701T100000
+END:VEVENT
+END:VCALENDAR
+`
+	periods := busyTimes(t, ics, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2030, 12, 31, 0, 0, 0, 0, time.UTC))
+
+	require.Len(t, periods, 2)
+	assert.Equal(t, time.Date(2030, 1, 7, 8, 0, 0, 0, time.UTC), periods[0].Start.UTC())
+	assert.Equal(t, time.Date(2030, 7, 1, 7, 0, 0, 0, time.UTC), periods[1].Start.UTC())
+}
+
+func TestBusyTimesRejectsMalformedInput(t *testing.T) {
+	tests := map[string]string{
+		"not a calendar":    "hello",
+		"unclosed":          "BEGIN:VCALENDAR\nBEGIN:VEVENT\nEND:VCALENDAR\n",
+		"unknown time zone": "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART;TZID=Mars/Olympus:20300603T090000\nEND:VEVENT\nEND:VCALENDAR\n",
+		"bad recurrence":    "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20300603T090000Z\nDURATION:PT1H\nRRULE:FREQ=SOMETIMES\nEND:VEVENT\nEND:VCALENDAR\n",
+	}
+
+	for name, ics := range tests {
+		_, err := BusyTimes(strings.NewReader(ics), time.Time{}, time.Now(), time.UTC)
+		assert.ErrorIs(t, err, ErrMalformed, name)
+	}
+}
@@ -0,0 +1,331 @@
+// Package calendar encodes and parses iCalendar (RFC 5545) data
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ProductID identifies this application in generated calendars
+const ProductID = "-//Meeting Scheduler//Meeting Scheduler API//EN"
+
+// Participation roles of an attendee
+const (
+	RoleChair    = "CHAIR"
+	RoleRequired = "REQ-PARTICIPANT"
+	RoleOptional = "OPT-PARTICIPANT"
+)
+
+// Participation statuses of an attendee
+const (
+	PartStatNeedsAction = "NEEDS-ACTION"
+	PartStatAccepted    = "ACCEPTED"
+	PartStatTentative   = "TENTATIVE"
+	PartStatDeclined    = "DECLINED"
+)
+
+// Event statuses
+const (
+	StatusTentative = "TENTATIVE"
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+// Calendar is a VCALENDAR object
+type Calendar struct {
+	Name   string // shown by clients that subscribe to the calendar
+	Events []Event
+}
+
+// Event is a VEVENT component
+type Event struct {
+	UID          string
+	Summary      string
+	Description  string
+	Start        time.Time
+	End          time.Time
+	Status       string
+	Created      time.Time
+	LastModified time.Time
+	Stamp        time.Time
+	Organizer    *Attendee
+	Attendees    []Attendee
+
+	// Location is the time zone Start and End are written in, UTC when nil. A
+	// recurring event needs it so that occurrences follow daylight saving time.
+	Location     *time.Location
+	Recurrence   string      // RRULE value
+	ExDates      []time.Time // cancelled occurrences of a recurring event
+	RecurrenceID time.Time   // scheduled start of the occurrence a moved instance replaces
+}
+
+// Attendee is a calendar user taking part in an event
+type Attendee struct {
+	Name     string
+	Email    string
+	Role     string
+	PartStat string
+}
+
+// Encode writes the calendar in iCalendar format
+func (c Calendar) Encode(w io.Writer) error {
+	e := &encoder{w: bufio.NewWriter(w)}
+
+	e.line("BEGIN", "VCALENDAR")
+	e.line("VERSION", "2.0")
+	e.line("PRODID", ProductID)
+	e.line("CALSCALE", "GREGORIAN")
+	e.line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		e.line("X-WR-CALNAME", escapeText(c.Name))
+	}
+	for _, zone := range c.timeZones() {
+		e.timeZone(zone.loc, zone.year)
+	}
+	for _, event := range c.Events {
+		event.encode(e)
+	}
+	e.line("END", "VCALENDAR")
+
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Flush()
+}
+
+// String returns the calendar in iCalendar format
+func (c Calendar) String() string {
+	var b strings.Builder
+	c.Encode(&b)
+	return b.String()
+}
+
+func (ev Event) encode(e *encoder) {
+	e.line("BEGIN", "VEVENT")
+	e.line("UID", ev.UID)
+	e.line("DTSTAMP", formatTime(ev.Stamp))
+	if !ev.RecurrenceID.IsZero() {
+		e.line(ev.timeProperty("RECURRENCE-ID", ev.RecurrenceID))
+	}
+	e.line(ev.timeProperty("DTSTART", ev.Start))
+	e.line(ev.timeProperty("DTEND", ev.End))
+	if ev.Recurrence != "" {
+		e.line("RRULE", ev.Recurrence)
+	}
+	for _, exdate := range ev.ExDates {
+		e.line(ev.timeProperty("EXDATE", exdate))
+	}
+	e.line("SUMMARY", escapeText(ev.Summary))
+	if ev.Description != "" {
+		e.line("DESCRIPTION", escapeText(ev.Description))
+	}
+	if ev.Status != "" {
+		e.line("STATUS", ev.Status)
+	}
+	if !ev.Created.IsZero() {
+		e.line("CREATED", formatTime(ev.Created))
+	}
+	if !ev.LastModified.IsZero() {
+		e.line("LAST-MODIFIED", formatTime(ev.LastModified))
+	}
+	if ev.Organizer != nil {
+		e.line("ORGANIZER"+params("CN", ev.Organizer.Name), mailto(ev.Organizer.Email))
+	}
+	for _, a := range ev.Attendees {
+		e.line("ATTENDEE"+params("CN", a.Name, "ROLE", a.Role, "PARTSTAT", a.PartStat), mailto(a.Email))
+	}
+	e.line("END", "VEVENT")
+}
+
+// timeProperty formats a date-time property in the event's time zone
+func (ev Event) timeProperty(name string, t time.Time) (string, string) {
+	if ev.Location == nil || ev.Location == time.UTC {
+		return name, formatTime(t)
+	}
+	return name + params("TZID", ev.Location.String()), t.In(ev.Location).Format(localLayout)
+}
+
+type zoneUse struct {
+	loc  *time.Location
+	year int
+}
+
+// timeZones returns the time zones the events are written in, with the
+// earliest year each is used in
+func (c Calendar) timeZones() []zoneUse {
+	var zones []zoneUse
+	index := make(map[string]int)
+	for _, ev := range c.Events {
+		if ev.Location == nil || ev.Location == time.UTC {
+			continue
+		}
+		year := ev.Start.In(ev.Location).Year()
+		if i, ok := index[ev.Location.String()]; ok {
+			if year < zones[i].year {
+				zones[i].year = year
+			}
+			continue
+		}
+		index[ev.Location.String()] = len(zones)
+		zones = append(zones, zoneUse{loc: ev.Location, year: year})
+	}
+	return zones
+}
+
+// timeZone writes a VTIMEZONE for a location. Its offset changes in the given
+// year are written as yearly rules on the nth weekday of the month, as used by
+// daylight saving time.
+func (e *encoder) timeZone(loc *time.Location, year int) {
+	e.line("BEGIN", "VTIMEZONE")
+	e.line("TZID", loc.String())
+
+	transitions := yearTransitions(loc, year)
+	if len(transitions) == 0 {
+		name, offset := time.Date(year, time.January, 1, 0, 0, 0, 0, loc).Zone()
+		e.line("BEGIN", "STANDARD")
+		e.line("DTSTART", "19700101T000000")
+		e.line("TZOFFSETFROM", formatOffset(offset))
+		e.line("TZOFFSETTO", formatOffset(offset))
+		e.line("TZNAME", escapeText(name))
+		e.line("END", "STANDARD")
+	}
+	for _, at := range transitions {
+		_, before := at.Add(-time.Second).Zone()
+		name, after := at.Zone()
+		kind := "STANDARD"
+		if at.IsDST() {
+			kind = "DAYLIGHT"
+		}
+		local := at.In(time.FixedZone("", before))
+		nth := (local.Day()-1)/7 + 1
+		if local.Day()+7 > daysIn(local.Year(), local.Month()) {
+			nth = -1
+		}
+
+		e.line("BEGIN", kind)
+		e.line("DTSTART", local.Format(localLayout))
+		e.line("TZOFFSETFROM", formatOffset(before))
+		e.line("TZOFFSETTO", formatOffset(after))
+		e.line("TZNAME", escapeText(name))
+		e.line("RRULE", fmt.Sprintf("FREQ=YEARLY;BYMONTH=%d;BYDAY=%d%s", local.Month(), nth, weekdayNames[local.Weekday()]))
+		e.line("END", kind)
+	}
+	e.line("END", "VTIMEZONE")
+}
+
+// yearTransitions returns the instants in a year at which a location changes its UTC offset
+func yearTransitions(loc *time.Location, year int) []time.Time {
+	var transitions []time.Time
+	t := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	end := time.Date(year+1, time.January, 1, 0, 0, 0, 0, loc)
+	for t.Before(end) {
+		next := t.Add(24 * time.Hour)
+		_, offset := t.Zone()
+		if _, nextOffset := next.Zone(); nextOffset != offset {
+			// Narrow down to the second at which the offset changes
+			lo, hi := t, next
+			for hi.Sub(lo) > time.Second {
+				mid := lo.Add(hi.Sub(lo) / 2)
+				if _, o := mid.Zone(); o == offset {
+					lo = mid
+				} else {
+					hi = mid
+				}
+			}
+			transitions = append(transitions, hi.Truncate(time.Second))
+		}
+		t = next
+	}
+	return transitions
+}
+
+// formatOffset formats a UTC offset in seconds as +hhmm or +hhmmss
+func formatOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign, seconds = "-", -seconds
+	}
+	s := fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds/60%60)
+	if seconds%60 != 0 {
+		s += fmt.Sprintf("%02d", seconds%60)
+	}
+	return s
+}
+
+// encoder writes content lines, folding them at 75 octets as RFC 5545 requires
+type encoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (e *encoder) line(name, value string) {
+	if e.err != nil {
+		return
+	}
+
+	line := name + ":" + value
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		if _, e.err = e.w.WriteString(line[:cut] + "\r\n "); e.err != nil {
+			return
+		}
+		line = line[cut:]
+		limit = 74 // continuation lines start with a space
+	}
+	_, e.err = e.w.WriteString(line + "\r\n")
+}
+
+// formatTime formats a time as a UTC date-time
+func formatTime(t time.Time) string {
+	return t.UTC().Format(utcLayout)
+}
+
+// escapeText escapes a TEXT property value
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// params formats property parameters given as name/value pairs, skipping empty values
+func params(pairs ...string) string {
+	var b strings.Builder
+	for i := 0; i+1 < len(pairs); i += 2 {
+		value := pairs[i+1]
+		if value == "" {
+			continue
+		}
+		b.WriteString(";" + pairs[i] + "=" + paramValue(value))
+	}
+	return b.String()
+}
+
+// paramValue quotes a parameter value if it contains separators. Double
+// quotes cannot be represented and are dropped.
+func paramValue(v string) string {
+	v = strings.ReplaceAll(v, `"`, "")
+	v = strings.NewReplacer("\r", " ", "\n", " ").Replace(v)
+	if strings.ContainsAny(v, ";:,") {
+		return `"` + v + `"`
+	}
+	return v
+}
+
+func mailto(email string) string {
+	return "mailto:" + email
+}
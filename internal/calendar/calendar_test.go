@@ -0,0 +1,111 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncode(t *testing.T) {
+	start := time.Date(2030, 6, 3, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	cal := Calendar{
+		Name: "Meetings",
+		Events: []Event{{
+			UID:         "event-1@meeting-scheduler",
+			Summary:     "Planning; Q3, H2",
+			Description: "Line one\nLine two \\ end",
+			Start:       start,
+			End:         start.Add(time.Hour),
+			Stamp:       start,
+			Status:      StatusConfirmed,
+			Organizer:   &Attendee{Name: "Doe, Jane", Email: "jane@example.com"},
+		}},
+	}
+
+	out := cal.String()
+
+	assert.True(t, strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(out, "END:VEVENT\r\nEND:VCALENDAR\r\n"))
+	assert.Contains(t, out, "DTSTART:20300603T100000Z\r\n")
+	assert.Contains(t, out, "SUMMARY:Planning\\; Q3\\, H2\r\n")
+	assert.Contains(t, out, "DESCRIPTION:Line one\\nLine two \\\\ end\r\n")
+	assert.Contains(t, out, "ORGANIZER;CN=\"Doe, Jane\":mailto:jane@example.com\r\n")
+}
+
+func TestEncodeFoldsLongLines(t *testing.T) {
+	cal := Calendar{Events: []Event{{
+		UID:     "event-1@meeting-scheduler",
+		Summary: strings.Repeat("é", 100),
+	}}}
+
+	out := cal.String()
+
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	assert.Contains(t, unfolded, "SUMMARY:"+strings.Repeat("é", 100)+"\r\n")
+}
+
+func TestEncodeRecurringEvent(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	start := time.Date(2030, 3, 26, 10, 0, 0, 0, berlin)
+	cal := Calendar{Events: []Event{
+		{
+			UID:        "event-1@meeting-scheduler",
+			Summary:    "Standup",
+			Start:      start,
+			End:        start.Add(15 * time.Minute),
+			Location:   berlin,
+			Recurrence: "FREQ=WEEKLY;BYDAY=TU",
+			ExDates:    []time.Time{start.AddDate(0, 0, 7)},
+		},
+		{
+			UID:          "event-1@meeting-scheduler",
+			Summary:      "Standup",
+			Start:        start.AddDate(0, 0, 15),
+			End:          start.AddDate(0, 0, 15).Add(15 * time.Minute),
+			Location:     berlin,
+			RecurrenceID: start.AddDate(0, 0, 14),
+		},
+	}}
+
+	out := cal.String()
+
+	assert.Contains(t, out, "DTSTART;TZID=Europe/Berlin:20300326T100000\r\n")
+	assert.Contains(t, out, "RRULE:FREQ=WEEKLY;BYDAY=TU\r\n")
+	assert.Contains(t, out, "EXDATE;TZID=Europe/Berlin:20300402T100000\r\n")
+	assert.Contains(t, out, "RECURRENCE-ID;TZID=Europe/Berlin:20300409T100000\r\n")
+	assert.Equal(t, 1, strings.Count(out, "BEGIN:VTIMEZONE"))
+	assert.Contains(t, out, "RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU\r\n")
+	assert.Contains(t, out, "RRULE:FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU\r\n")
+
+	// The written time zone describes the same offsets as the original
+	root, err := parse(strings.NewReader(out))
+	require.NoError(t, err)
+	var vtimezone *component
+	root.walk(func(c *component) {
+		if c.Name == "VTIMEZONE" {
+			vtimezone = c
+		}
+	})
+	require.NotNil(t, vtimezone)
+	loc, err := vtimezoneLocation("Europe/Berlin", vtimezone)
+	require.NoError(t, err)
+	for _, day := range []time.Time{
+		time.Date(2031, 1, 15, 12, 0, 0, 0, time.UTC),
+		time.Date(2031, 3, 30, 0, 59, 0, 0, time.UTC),
+		time.Date(2031, 3, 30, 1, 0, 0, 0, time.UTC),
+		time.Date(2031, 7, 15, 12, 0, 0, 0, time.UTC),
+	} {
+		_, want := day.In(berlin).Zone()
+		_, got := day.In(loc).Zone()
+		assert.Equal(t, want, got, day)
+	}
+}
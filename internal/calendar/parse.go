@@ -0,0 +1,206 @@
+package calendar
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Date and date-time formats of iCalendar values
+const (
+	dateLayout  = "20060102"
+	localLayout = "20060102T150405"
+	utcLayout   = "20060102T150405Z"
+)
+
+// maxLineLength bounds a single unfolded content line
+const maxLineLength = 1 << 20
+
+// ErrMalformed is returned when the input is not valid iCalendar data
+var ErrMalformed = errors.New("malformed iCalendar data")
+
+// property is a content line: a name, its parameters and a raw value
+type property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// component is a BEGIN/END block with its properties and nested components
+type component struct {
+	Name       string
+	Properties []property
+	Components []*component
+}
+
+// get returns the first property with the given name
+func (c *component) get(name string) *property {
+	for i := range c.Properties {
+		if c.Properties[i].Name == name {
+			return &c.Properties[i]
+		}
+	}
+	return nil
+}
+
+// all returns every property with the given name
+func (c *component) all(name string) []property {
+	var props []property
+	for _, p := range c.Properties {
+		if p.Name == name {
+			props = append(props, p)
+		}
+	}
+	return props
+}
+
+// walk calls fn for every component nested in c, depth first
+func (c *component) walk(fn func(*component)) {
+	for _, child := range c.Components {
+		fn(child)
+		child.walk(fn)
+	}
+}
+
+// parse reads iCalendar data into a tree of components under an unnamed root
+func parse(r io.Reader) (*component, error) {
+	root := &component{}
+	stack := []*component{root}
+
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	for n, line := range lines {
+		prop, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrMalformed, n+1, err)
+		}
+		current := stack[len(stack)-1]
+
+		switch prop.Name {
+		case "BEGIN":
+			child := &component{Name: strings.ToUpper(prop.Value)}
+			current.Components = append(current.Components, child)
+			stack = append(stack, child)
+		case "END":
+			if len(stack) == 1 || current.Name != strings.ToUpper(prop.Value) {
+				return nil, fmt.Errorf("%w: line %d: unexpected END:%s", ErrMalformed, n+1, prop.Value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 1 {
+				return nil, fmt.Errorf("%w: line %d: property %s outside of a component", ErrMalformed, n+1, prop.Name)
+			}
+			current.Properties = append(current.Properties, prop)
+		}
+	}
+
+	if len(stack) > 1 {
+		return nil, fmt.Errorf("%w: %s is not closed", ErrMalformed, stack[len(stack)-1].Name)
+	}
+	if len(root.Components) == 0 || root.Components[0].Name != "VCALENDAR" {
+		return nil, fmt.Errorf("%w: no VCALENDAR found", ErrMalformed)
+	}
+	return root, nil
+}
+
+// unfold reads content lines, joining folded continuation lines
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineLength)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return lines, nil
+}
+
+// parseLine splits a content line into name, parameters and value
+func parseLine(line string) (property, error) {
+	prop := property{Params: make(map[string]string)}
+
+	end := strings.IndexAny(line, ";:")
+	if end <= 0 {
+		return prop, fmt.Errorf("missing property name")
+	}
+	prop.Name = strings.ToUpper(line[:end])
+	rest := line[end:]
+
+	for strings.HasPrefix(rest, ";") {
+		rest = rest[1:]
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return prop, fmt.Errorf("invalid parameter in %s", prop.Name)
+		}
+		name := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		var value strings.Builder
+		for len(rest) > 0 && rest[0] != ';' && rest[0] != ':' {
+			if rest[0] == '"' {
+				closing := strings.IndexByte(rest[1:], '"')
+				if closing < 0 {
+					return prop, fmt.Errorf("unterminated quoted parameter in %s", prop.Name)
+				}
+				value.WriteString(rest[1 : closing+1])
+				rest = rest[closing+2:]
+				continue
+			}
+			value.WriteByte(rest[0])
+			rest = rest[1:]
+		}
+		prop.Params[name] = value.String()
+	}
+
+	if !strings.HasPrefix(rest, ":") {
+		return prop, fmt.Errorf("missing value of %s", prop.Name)
+	}
+	prop.Value = rest[1:]
+	return prop, nil
+}
+
+// durationPattern matches an iCalendar duration such as P1W, P1DT2H or -PT15M
+var durationPattern = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseDuration parses an iCalendar duration value
+func parseDuration(value string) (time.Duration, error) {
+	m := durationPattern.FindStringSubmatch(strings.ToUpper(value))
+	if m == nil || value == "P" || strings.HasSuffix(value, "T") {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if m[i+2] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(m[i+2])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		d += time.Duration(n) * unit
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
@@ -0,0 +1,491 @@
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the FREQ rule part of a recurrence rule
+type Frequency string
+
+// Supported recurrence frequencies
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// maxPeriods bounds the number of periods a rule is expanded over, so a rule
+// whose parts can never match (BYMONTH=2;BYMONTHDAY=30) still terminates
+const maxPeriods = 100000
+
+// WeekdayNum is an entry of the BYDAY rule part: a weekday, restricted to its
+// Nth occurrence in the month or year when N is set (counting from the end
+// when N is negative)
+type WeekdayNum struct {
+	N   int
+	Day time.Weekday
+}
+
+// Recurrence is a recurrence rule (RRULE). The rule parts calendar
+// applications use for meetings are supported: FREQ from DAILY to YEARLY,
+// INTERVAL, COUNT, UNTIL, BYDAY, BYMONTHDAY, BYMONTH, BYSETPOS and WKST.
+type Recurrence struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []time.Month
+	BySetPos   []int
+	WeekStart  time.Weekday
+
+	// untilFloating is set when UNTIL was given without a time zone; it is then
+	// read in the location of the series
+	untilFloating bool
+	untilDate     bool
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+var weekdayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// ParseRecurrence parses the value of an RRULE property, with or without the
+// "RRULE:" prefix
+func ParseRecurrence(rule string) (Recurrence, error) {
+	r := Recurrence{Interval: 1, WeekStart: time.Monday}
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	if rule == "" {
+		return r, fmt.Errorf("invalid recurrence rule: empty rule")
+	}
+
+	for _, part := range strings.Split(rule, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return r, fmt.Errorf("invalid recurrence rule part %q", part)
+		}
+		var err error
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			r.Freq = Frequency(strings.ToUpper(value))
+			switch r.Freq {
+			case Daily, Weekly, Monthly, Yearly:
+			default:
+				err = fmt.Errorf("unsupported frequency %q", value)
+			}
+		case "INTERVAL":
+			r.Interval, err = positiveInt(value)
+		case "COUNT":
+			r.Count, err = positiveInt(value)
+		case "UNTIL":
+			err = r.parseUntil(value)
+		case "BYDAY":
+			for _, v := range strings.Split(value, ",") {
+				var day WeekdayNum
+				if day, err = parseWeekdayNum(v); err != nil {
+					break
+				}
+				r.ByDay = append(r.ByDay, day)
+			}
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = intList(value, 31)
+		case "BYMONTH":
+			var months []int
+			months, err = intList(value, 12)
+			for _, m := range months {
+				if m < 0 {
+					err = fmt.Errorf("invalid month %d", m)
+				}
+				r.ByMonth = append(r.ByMonth, time.Month(m))
+			}
+		case "BYSETPOS":
+			r.BySetPos, err = intList(value, 366)
+		case "WKST":
+			day, ok := weekdays[strings.ToUpper(value)]
+			if !ok {
+				err = fmt.Errorf("invalid weekday %q", value)
+			}
+			r.WeekStart = day
+		default:
+			err = fmt.Errorf("unsupported rule part %s", strings.ToUpper(name))
+		}
+		if err != nil {
+			return r, fmt.Errorf("invalid recurrence rule: %w", err)
+		}
+	}
+
+	if r.Freq == "" {
+		return r, fmt.Errorf("invalid recurrence rule: FREQ is required")
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return r, fmt.Errorf("invalid recurrence rule: COUNT and UNTIL cannot both be set")
+	}
+	return r, nil
+}
+
+func (r *Recurrence) parseUntil(value string) error {
+	switch {
+	case len(value) == 8:
+		t, err := time.Parse(dateLayout, value)
+		if err != nil {
+			return fmt.Errorf("invalid UNTIL %q", value)
+		}
+		r.Until, r.untilFloating, r.untilDate = t, true, true
+	case strings.HasSuffix(value, "Z"):
+		t, err := time.Parse(utcLayout, value)
+		if err != nil {
+			return fmt.Errorf("invalid UNTIL %q", value)
+		}
+		r.Until = t
+	default:
+		t, err := time.Parse(localLayout, value)
+		if err != nil {
+			return fmt.Errorf("invalid UNTIL %q", value)
+		}
+		r.Until, r.untilFloating = t, true
+	}
+	return nil
+}
+
+func positiveInt(value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid number %q", value)
+	}
+	return n, nil
+}
+
+// intList parses a comma separated list of non-zero integers within ±limit
+func intList(value string, limit int) ([]int, error) {
+	var list []int
+	for _, v := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(v)
+		if err != nil || n == 0 || n > limit || n < -limit {
+			return nil, fmt.Errorf("invalid value %q", v)
+		}
+		list = append(list, n)
+	}
+	return list, nil
+}
+
+func parseWeekdayNum(value string) (WeekdayNum, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if len(value) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid weekday %q", value)
+	}
+	day, ok := weekdays[value[len(value)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("invalid weekday %q", value)
+	}
+	n := 0
+	if prefix := value[:len(value)-2]; prefix != "" {
+		var err error
+		n, err = strconv.Atoi(prefix)
+		if err != nil || n == 0 || n > 53 || n < -53 {
+			return WeekdayNum{}, fmt.Errorf("invalid weekday %q", value)
+		}
+	}
+	return WeekdayNum{N: n, Day: day}, nil
+}
+
+// String formats the rule as the value of an RRULE property
+func (r Recurrence) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		switch {
+		case r.untilDate:
+			parts = append(parts, "UNTIL="+r.Until.Format(dateLayout))
+		case r.untilFloating:
+			parts = append(parts, "UNTIL="+r.Until.Format(localLayout))
+		default:
+			parts = append(parts, "UNTIL="+r.Until.UTC().Format(utcLayout))
+		}
+	}
+	if len(r.ByMonth) > 0 {
+		var months []string
+		for _, m := range r.ByMonth {
+			months = append(months, strconv.Itoa(int(m)))
+		}
+		parts = append(parts, "BYMONTH="+strings.Join(months, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByDay) > 0 {
+		var days []string
+		for _, d := range r.ByDay {
+			day := weekdayNames[d.Day]
+			if d.N != 0 {
+				day = strconv.Itoa(d.N) + day
+			}
+			days = append(days, day)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.BySetPos) > 0 {
+		parts = append(parts, "BYSETPOS="+joinInts(r.BySetPos))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayNames[r.WeekStart])
+	}
+	return strings.Join(parts, ";")
+}
+
+func joinInts(list []int) string {
+	s := make([]string, len(list))
+	for i, n := range list {
+		s[i] = strconv.Itoa(n)
+	}
+	return strings.Join(s, ",")
+}
+
+// Between returns the start times of the occurrences of a series starting at
+// start that begin at or after from and before to. The first occurrence is
+// always start itself. Later occurrences keep the wall clock time of start in
+// its location, so a series does not shift when daylight saving time changes.
+func (r Recurrence) Between(start, from, to time.Time) []time.Time {
+	var occurrences []time.Time
+	r.expand(start, to, func(t time.Time) {
+		if !t.Before(from) {
+			occurrences = append(occurrences, t)
+		}
+	})
+	return occurrences
+}
+
+// expand calls fn for each occurrence before horizon, in order
+func (r Recurrence) expand(start, horizon time.Time, fn func(time.Time)) {
+	if !start.Before(horizon) {
+		return
+	}
+	loc := start.Location()
+	until := r.Until
+	if r.untilFloating {
+		y, m, d := until.Date()
+		hh, mm, ss := until.Clock()
+		if r.untilDate {
+			hh, mm, ss = 23, 59, 59
+		}
+		until = time.Date(y, m, d, hh, mm, ss, 0, loc)
+	}
+
+	fn(start)
+	count := 1
+	if r.Count == 1 {
+		return
+	}
+
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+	hh, mm, ss := start.Clock()
+
+	for p := 0; p < maxPeriods; p++ {
+		days, periodStart := r.periodDays(start, p*interval)
+		if !periodStart.Before(horizon) {
+			return
+		}
+		for _, day := range days {
+			t := time.Date(day.Year(), day.Month(), day.Day(), hh, mm, ss, 0, loc)
+			if !t.After(start) {
+				continue
+			}
+			if !until.IsZero() && t.After(until) {
+				return
+			}
+			if !t.Before(horizon) {
+				return
+			}
+			fn(t)
+			count++
+			if r.Count > 0 && count >= r.Count {
+				return
+			}
+		}
+	}
+}
+
+// periodDays returns the days matched by the rule in the period that lies
+// offset frequency units after the one containing start, as UTC midnights in
+// order, together with the first day of that period in start's location
+func (r Recurrence) periodDays(start time.Time, offset int) ([]time.Time, time.Time) {
+	y, m, d := start.Date()
+	var days []time.Time
+	var first time.Time
+
+	switch r.Freq {
+	case Daily:
+		day := time.Date(y, m, d+offset, 0, 0, 0, 0, time.UTC)
+		first = day
+		if r.matchMonth(day.Month()) && r.matchMonthDay(day) && r.matchWeekday(day.Weekday()) {
+			days = append(days, day)
+		}
+	case Weekly:
+		back := (int(start.Weekday()) - int(r.WeekStart) + 7) % 7
+		first = time.Date(y, m, d-back+7*offset, 0, 0, 0, 0, time.UTC)
+		for i := 0; i < 7; i++ {
+			day := first.AddDate(0, 0, i)
+			if !r.matchMonth(day.Month()) {
+				continue
+			}
+			if len(r.ByDay) == 0 && day.Weekday() != start.Weekday() {
+				continue
+			}
+			if len(r.ByDay) > 0 && !r.matchWeekday(day.Weekday()) {
+				continue
+			}
+			days = append(days, day)
+		}
+	case Monthly:
+		first = time.Date(y, m+time.Month(offset), 1, 0, 0, 0, 0, time.UTC)
+		if r.matchMonth(first.Month()) {
+			days = r.monthDays(first.Year(), first.Month(), d)
+		}
+	case Yearly:
+		first = time.Date(y+offset, time.January, 1, 0, 0, 0, 0, time.UTC)
+		switch {
+		case len(r.ByMonth) > 0:
+			for _, month := range r.ByMonth {
+				days = append(days, r.monthDays(first.Year(), month, d)...)
+			}
+		case len(r.ByDay) > 0 && len(r.ByMonthDay) == 0:
+			days = r.yearWeekdays(first.Year())
+		case len(r.ByMonthDay) > 0:
+			for month := time.January; month <= time.December; month++ {
+				days = append(days, r.monthDays(first.Year(), month, d)...)
+			}
+		default:
+			if d <= daysIn(first.Year(), m) {
+				days = append(days, time.Date(first.Year(), m, d, 0, 0, 0, 0, time.UTC))
+			}
+		}
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return r.setPositions(days), time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, start.Location())
+}
+
+// monthDays returns the days of a month matched by BYMONTHDAY and BYDAY, or
+// the given day of the month when neither is set
+func (r Recurrence) monthDays(year int, month time.Month, defaultDay int) []time.Time {
+	n := daysIn(year, month)
+	var days []time.Time
+	for day := 1; day <= n; day++ {
+		date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		switch {
+		case len(r.ByMonthDay) == 0 && len(r.ByDay) == 0:
+			if day != defaultDay {
+				continue
+			}
+		case len(r.ByMonthDay) > 0 && !r.matchMonthDay(date):
+			continue
+		case len(r.ByDay) > 0 && !r.matchNthWeekday(date, (day-1)/7+1, (n-day)/7+1):
+			continue
+		}
+		days = append(days, date)
+	}
+	return days
+}
+
+// yearWeekdays returns the days of a year matched by BYDAY, with ordinals
+// counted within the year
+func (r Recurrence) yearWeekdays(year int) []time.Time {
+	var days []time.Time
+	first := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	n := time.Date(year+1, time.January, 1, 0, 0, 0, 0, time.UTC).Sub(first).Hours() / 24
+	for i := 0; i < int(n); i++ {
+		date := first.AddDate(0, 0, i)
+		if r.matchNthWeekday(date, i/7+1, (int(n)-1-i)/7+1) {
+			days = append(days, date)
+		}
+	}
+	return days
+}
+
+func (r Recurrence) matchMonth(month time.Month) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, m := range r.ByMonth {
+		if m == month {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Recurrence) matchMonthDay(date time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	n := daysIn(date.Year(), date.Month())
+	for _, d := range r.ByMonthDay {
+		if d == date.Day() || (d < 0 && n+1+d == date.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchWeekday matches BYDAY ignoring ordinals, as used by DAILY and WEEKLY rules
+func (r Recurrence) matchWeekday(day time.Weekday) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, d := range r.ByDay {
+		if d.Day == day {
+			return true
+		}
+	}
+	return false
+}
+
+// matchNthWeekday matches BYDAY for a date that is the nth occurrence of its
+// weekday in the period, and the last-th counting from the end
+func (r Recurrence) matchNthWeekday(date time.Time, nth, last int) bool {
+	for _, d := range r.ByDay {
+		if d.Day != date.Weekday() {
+			continue
+		}
+		if d.N == 0 || d.N == nth || d.N == -last {
+			return true
+		}
+	}
+	return false
+}
+
+// setPositions applies BYSETPOS to the ordered days of a period
+func (r Recurrence) setPositions(days []time.Time) []time.Time {
+	if len(r.BySetPos) == 0 || len(days) == 0 {
+		return days
+	}
+	var selected []time.Time
+	for i, day := range days {
+		for _, pos := range r.BySetPos {
+			if pos == i+1 || pos == i-len(days) {
+				selected = append(selected, day)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
@@ -0,0 +1,121 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	rule, err := ParseRecurrence("RRULE:FREQ=MONTHLY;INTERVAL=2;BYDAY=-1FR,2MO;COUNT=5")
+	require.NoError(t, err)
+
+	assert.Equal(t, Monthly, rule.Freq)
+	assert.Equal(t, 2, rule.Interval)
+	assert.Equal(t, 5, rule.Count)
+	assert.Equal(t, []WeekdayNum{{N: -1, Day: time.Friday}, {N: 2, Day: time.Monday}}, rule.ByDay)
+	assert.Equal(t, "FREQ=MONTHLY;INTERVAL=2;COUNT=5;BYDAY=-1FR,2MO", rule.String())
+}
+
+func TestParseRecurrenceRejectsUnsupportedRules(t *testing.T) {
+	tests := []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;BYHOUR=9",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=DAILY;COUNT=3;UNTIL=20300101T000000Z",
+	}
+
+	for _, rule := range tests {
+		_, err := ParseRecurrence(rule)
+		assert.Error(t, err, rule)
+	}
+}
+
+func TestRecurrenceBetween(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		want  []string
+	}{
+		{
+			name:  "weekly on two days",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4",
+			start: time.Date(2030, 6, 3, 9, 0, 0, 0, time.UTC),
+			want:  []string{"2030-06-03", "2030-06-05", "2030-06-10", "2030-06-12"},
+		},
+		{
+			name:  "every other day until a date",
+			rule:  "FREQ=DAILY;INTERVAL=2;UNTIL=20300609",
+			start: time.Date(2030, 6, 3, 9, 0, 0, 0, time.UTC),
+			want:  []string{"2030-06-03", "2030-06-05", "2030-06-07", "2030-06-09"},
+		},
+		{
+			name:  "last friday of the month",
+			rule:  "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3",
+			start: time.Date(2030, 5, 31, 9, 0, 0, 0, time.UTC),
+			want:  []string{"2030-05-31", "2030-06-28", "2030-07-26"},
+		},
+		{
+			name:  "monthly on the 31st skips short months",
+			rule:  "FREQ=MONTHLY;COUNT=3",
+			start: time.Date(2030, 1, 31, 9, 0, 0, 0, time.UTC),
+			want:  []string{"2030-01-31", "2030-03-31", "2030-05-31"},
+		},
+		{
+			name:  "last weekday of the month",
+			rule:  "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1;COUNT=2",
+			start: time.Date(2030, 8, 30, 9, 0, 0, 0, time.UTC),
+			want:  []string{"2030-08-30", "2030-09-30"},
+		},
+		{
+			name:  "yearly in march on the last sunday",
+			rule:  "FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU;COUNT=2",
+			start: time.Date(2030, 3, 31, 2, 0, 0, 0, time.UTC),
+			want:  []string{"2030-03-31", "2031-03-30"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRecurrence(tt.rule)
+			require.NoError(t, err)
+
+			var got []string
+			for _, occurrence := range rule.Between(tt.start, tt.start, tt.start.AddDate(2, 0, 0)) {
+				got = append(got, occurrence.Format("2006-01-02"))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("keeps wall clock time across daylight saving time", func(t *testing.T) {
+		rule, err := ParseRecurrence("FREQ=WEEKLY;COUNT=2")
+		require.NoError(t, err)
+
+		start := time.Date(2030, 3, 25, 9, 0, 0, 0, berlin)
+		occurrences := rule.Between(start, start, start.AddDate(0, 1, 0))
+
+		require.Len(t, occurrences, 2)
+		assert.Equal(t, 9, occurrences[1].Hour())
+		assert.Equal(t, 7*24*time.Hour-time.Hour, occurrences[1].Sub(occurrences[0]))
+	})
+
+	t.Run("stops at the horizon", func(t *testing.T) {
+		rule, err := ParseRecurrence("FREQ=DAILY")
+		require.NoError(t, err)
+
+		start := time.Date(2030, 6, 3, 9, 0, 0, 0, time.UTC)
+		occurrences := rule.Between(start, start.AddDate(0, 0, 2), start.AddDate(0, 0, 5))
+
+		assert.Len(t, occurrences, 3)
+	})
+}
@@ -0,0 +1,267 @@
+package calendar
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// zoneHorizon is how far ahead the transitions of a VTIMEZONE are computed
+var zoneHorizon = time.Date(2100, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// zones resolves the TZID parameters of a calendar to locations
+type zones struct {
+	defined  map[string]*time.Location
+	floating *time.Location
+}
+
+// newZones collects the time zones defined by the calendar's VTIMEZONE
+// components. IANA names are preferred over the definitions, which are only
+// used for names Go does not know, such as Windows zone names.
+func newZones(root *component, floating *time.Location) (*zones, error) {
+	z := &zones{defined: make(map[string]*time.Location), floating: floating}
+
+	var err error
+	root.walk(func(c *component) {
+		if c.Name != "VTIMEZONE" || err != nil {
+			return
+		}
+		tzid := c.get("TZID")
+		if tzid == nil || tzid.Value == "" {
+			err = fmt.Errorf("%w: VTIMEZONE without TZID", ErrMalformed)
+			return
+		}
+		if _, loadErr := loadLocation(tzid.Value); loadErr == nil {
+			return
+		}
+		var loc *time.Location
+		if loc, err = vtimezoneLocation(tzid.Value, c); err == nil {
+			z.defined[tzid.Value] = loc
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return z, nil
+}
+
+// location resolves a TZID, an empty one meaning floating time
+func (z *zones) location(tzid string) (*time.Location, error) {
+	if tzid == "" {
+		return z.floating, nil
+	}
+	if loc, ok := z.defined[tzid]; ok {
+		return loc, nil
+	}
+	loc, err := loadLocation(tzid)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q", tzid)
+	}
+	return loc, nil
+}
+
+// parseTime parses a single DATE or DATE-TIME value. Dates are read as
+// midnight in the floating location and reported as such.
+func (z *zones) parseTime(value string, params map[string]string) (time.Time, bool, error) {
+	if strings.ToUpper(params["VALUE"]) == "DATE" || len(value) == len(dateLayout) {
+		t, err := time.ParseInLocation(dateLayout, value, z.floating)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date %q", value)
+		}
+		return t, true, nil
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(utcLayout, value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date-time %q", value)
+		}
+		return t, false, nil
+	}
+	loc, err := z.location(params["TZID"])
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	t, err := time.ParseInLocation(localLayout, value, loc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid date-time %q", value)
+	}
+	return t, false, nil
+}
+
+// parseTimes parses a property holding a comma separated list of dates or date-times
+func (z *zones) parseTimes(p property) ([]time.Time, error) {
+	var times []time.Time
+	for _, value := range strings.Split(p.Value, ",") {
+		t, _, err := z.parseTime(value, p.Params)
+		if err != nil {
+			return nil, err
+		}
+		times = append(times, t)
+	}
+	return times, nil
+}
+
+// loadLocation loads an IANA time zone. Unlike time.LoadLocation it does not
+// accept the empty name or "Local", which would depend on the server.
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	return time.LoadLocation(name)
+}
+
+// transition is a change of UTC offset defined by a VTIMEZONE observance
+type transition struct {
+	at     time.Time
+	offset int
+	dst    bool
+	name   string
+}
+
+// vtimezoneLocation builds a location from the STANDARD and DAYLIGHT
+// observances of a VTIMEZONE
+func vtimezoneLocation(tzid string, c *component) (*time.Location, error) {
+	var transitions []transition
+	for _, obs := range c.Components {
+		if obs.Name != "STANDARD" && obs.Name != "DAYLIGHT" {
+			continue
+		}
+		from, err := parseOffset(obs.get("TZOFFSETFROM"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: time zone %q: %v", ErrMalformed, tzid, err)
+		}
+		to, err := parseOffset(obs.get("TZOFFSETTO"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: time zone %q: %v", ErrMalformed, tzid, err)
+		}
+		dtstart := obs.get("DTSTART")
+		if dtstart == nil {
+			return nil, fmt.Errorf("%w: time zone %q: observance without DTSTART", ErrMalformed, tzid)
+		}
+		// Onsets are given in the local time in effect before the transition
+		before := time.FixedZone("", from)
+		start, err := time.ParseInLocation(localLayout, dtstart.Value, before)
+		if err != nil {
+			return nil, fmt.Errorf("%w: time zone %q: invalid DTSTART %q", ErrMalformed, tzid, dtstart.Value)
+		}
+
+		onsets := []time.Time{start}
+		if rrule := obs.get("RRULE"); rrule != nil {
+			rule, err := ParseRecurrence(rrule.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: time zone %q: %v", ErrMalformed, tzid, err)
+			}
+			onsets = rule.Between(start, start, zoneHorizon)
+		}
+		for _, rdate := range obs.all("RDATE") {
+			for _, value := range strings.Split(rdate.Value, ",") {
+				t, err := time.ParseInLocation(localLayout, value, before)
+				if err != nil {
+					return nil, fmt.Errorf("%w: time zone %q: invalid RDATE %q", ErrMalformed, tzid, value)
+				}
+				onsets = append(onsets, t)
+			}
+		}
+
+		name := ""
+		if tzname := obs.get("TZNAME"); tzname != nil {
+			name = tzname.Value
+		}
+		for _, onset := range onsets {
+			transitions = append(transitions, transition{at: onset, offset: to, dst: obs.Name == "DAYLIGHT", name: name})
+		}
+	}
+	if len(transitions) == 0 {
+		return nil, fmt.Errorf("%w: time zone %q has no observances", ErrMalformed, tzid)
+	}
+
+	sort.Slice(transitions, func(i, j int) bool { return transitions[i].at.Before(transitions[j].at) })
+	return time.LoadLocationFromTZData(tzid, tzif(transitions))
+}
+
+// parseOffset parses a UTC offset such as +0100 or -053000 into seconds
+func parseOffset(p *property) (int, error) {
+	if p == nil {
+		return 0, fmt.Errorf("missing UTC offset")
+	}
+	v := p.Value
+	if (len(v) != 5 && len(v) != 7) || (v[0] != '+' && v[0] != '-') {
+		return 0, fmt.Errorf("invalid UTC offset %q", v)
+	}
+	var seconds int
+	for i, unit := range []int{3600, 60, 1} {
+		if 1+2*i >= len(v) {
+			break
+		}
+		n, err := strconv.Atoi(v[1+2*i : 3+2*i])
+		if err != nil {
+			return 0, fmt.Errorf("invalid UTC offset %q", v)
+		}
+		seconds += n * unit
+	}
+	if v[0] == '-' {
+		seconds = -seconds
+	}
+	return seconds, nil
+}
+
+// tzif encodes transitions in the TZif format read by time.LoadLocationFromTZData.
+// The version 1 block is left empty; readers use the 64-bit version 2 block.
+func tzif(transitions []transition) []byte {
+	type zoneType struct {
+		offset int
+		dst    bool
+		name   string
+	}
+	var types []zoneType
+	var abbrevs []byte
+	abbrevIndex := make(map[string]int)
+	typeIndex := make(map[zoneType]int)
+	indices := make([]byte, len(transitions))
+	for i, t := range transitions {
+		zt := zoneType{offset: t.offset, dst: t.dst, name: t.name}
+		idx, ok := typeIndex[zt]
+		if !ok {
+			idx = len(types)
+			typeIndex[zt] = idx
+			types = append(types, zt)
+			if _, ok := abbrevIndex[zt.name]; !ok {
+				abbrevIndex[zt.name] = len(abbrevs)
+				abbrevs = append(append(abbrevs, zt.name...), 0)
+			}
+		}
+		indices[i] = byte(idx)
+	}
+
+	var buf bytes.Buffer
+	header := func(timecnt, typecnt, charcnt int) {
+		buf.WriteString("TZif2")
+		buf.Write(make([]byte, 15))
+		for _, n := range []int{0, 0, 0, timecnt, typecnt, charcnt} {
+			binary.Write(&buf, binary.BigEndian, uint32(n))
+		}
+	}
+
+	header(0, 0, 0)
+	header(len(transitions), len(types), len(abbrevs))
+	for _, t := range transitions {
+		binary.Write(&buf, binary.BigEndian, t.at.Unix())
+	}
+	buf.Write(indices)
+	for _, zt := range types {
+		binary.Write(&buf, binary.BigEndian, int32(zt.offset))
+		dst := byte(0)
+		if zt.dst {
+			dst = 1
+		}
+		buf.WriteByte(dst)
+		buf.WriteByte(byte(abbrevIndex[zt.name]))
+	}
+	buf.Write(abbrevs)
+	buf.WriteString("\n\n")
+	return buf.Bytes()
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strings"
 
@@ -8,21 +9,72 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	Scheduling SchedulingConfig
+	Auth       AuthConfig
+	Notify     NotifyConfig
+	Jobs       JobsConfig
+	Redis      RedisConfig
 }
 
 type ServerConfig struct {
-	Port string
+	Port        string
+	Environment string // development, which allows running without auth.signingkey, or production
 }
 
 type DatabaseConfig struct {
+	Driver   string // postgres, or memory to keep all data in the process and lose it on exit
 	Host     string
 	Port     string
 	User     string
 	Password string
 	DBName   string
 	SSLMode  string
+
+	MigrateOnStart bool // apply pending schema migrations before serving
+}
+
+// DSN returns the connection string of the database
+func (c DatabaseConfig) DSN() string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		c.Host, c.Port, c.User, c.Password, c.DBName, c.SSLMode)
+}
+
+type SchedulingConfig struct {
+	WorkdayStart string // local working hours used to flag inconvenient slots, as HH:MM
+	WorkdayEnd   string
+	HorizonDays  int // how far ahead time slots may be scheduled, 0 for no limit
+}
+
+type AuthConfig struct {
+	SigningKey         string // HMAC key for bearer tokens; required outside development, where a random key is used when empty
+	TokenTTLMinutes    int    // how long issued bearer tokens are valid
+	InvitationTTLHours int    // how long invitation links are valid unless the organizer says otherwise
+}
+
+type NotifyConfig struct {
+	SMTPHost     string // notifications are logged instead of sent when empty
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	From         string // sender address of notification email
+	TemplateDir  string // overrides the built-in message templates, file by file
+
+	ReminderCooldownHours int // how long after a reminder an invitee is not reminded again on request
+}
+
+type JobsConfig struct {
+	Enabled           bool // run background jobs in this process
+	ReminderLeadHours int  // how long before a poll deadline unanswered invitees are reminded
+	RetentionDays     int  // how long soft-deleted rows are kept before they are purged
+}
+
+type RedisConfig struct {
+	Addr                     string // host:port of the server caching recommendations; nothing is cached when empty
+	Password                 string
+	DB                       int
+	RecommendationTTLSeconds int // how long recommendations stay cached unless a change invalidates them first
 }
 
 func Load() (*Config, error) {
@@ -33,12 +85,35 @@ func Load() (*Config, error) {
 
 	// Set defaults
 	viper.SetDefault("server.port", "8080")
+	viper.SetDefault("server.environment", "development")
+	viper.SetDefault("database.driver", "postgres")
 	viper.SetDefault("database.host", "postgres")
 	viper.SetDefault("database.port", "5432")
 	viper.SetDefault("database.user", "postgres")
 	viper.SetDefault("database.password", "postgres")
 	viper.SetDefault("database.dbname", "scheduler")
 	viper.SetDefault("database.sslmode", "disable")
+	viper.SetDefault("database.migrateonstart", true)
+	viper.SetDefault("scheduling.workdaystart", "08:00")
+	viper.SetDefault("scheduling.workdayend", "18:00")
+	viper.SetDefault("scheduling.horizondays", 365)
+	viper.SetDefault("auth.signingkey", "")
+	viper.SetDefault("auth.tokenttlminutes", 60)
+	viper.SetDefault("auth.invitationttlhours", 14*24)
+	viper.SetDefault("notify.smtphost", "")
+	viper.SetDefault("notify.smtpport", 587)
+	viper.SetDefault("notify.smtpusername", "")
+	viper.SetDefault("notify.smtppassword", "")
+	viper.SetDefault("notify.from", "Meeting Scheduler <scheduler@localhost>")
+	viper.SetDefault("notify.templatedir", "")
+	viper.SetDefault("notify.remindercooldownhours", 24)
+	viper.SetDefault("jobs.enabled", true)
+	viper.SetDefault("jobs.reminderleadhours", 24)
+	viper.SetDefault("jobs.retentiondays", 30)
+	viper.SetDefault("redis.addr", "")
+	viper.SetDefault("redis.password", "")
+	viper.SetDefault("redis.db", 0)
+	viper.SetDefault("redis.recommendationttlseconds", 600)
 
 	// Environment variables
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
@@ -0,0 +1,124 @@
+// Package jobs runs periodic background work. Jobs are recorded in the
+// database and leased to one server at a time, so however many servers run
+// the same jobs, each run happens once.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tusharsingune/meeting-scheduler/internal/logger"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"go.uber.org/zap"
+)
+
+// Store is the part of the repository the Runner works with
+type Store interface {
+	ClaimJob(ctx context.Context, name, owner string, now time.Time, lease time.Duration) (*models.Job, error)
+	FinishJob(context.Context, *models.Job) error
+}
+
+// Job is a task run every Interval
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Runner runs jobs when they are due
+type Runner struct {
+	Store        Store
+	Log          *zap.Logger
+	Owner        string        // identifies this server on the jobs it leases
+	Lease        time.Duration // longest a run may take; another server may take the job over after it
+	PollInterval time.Duration // how often to look for due jobs
+	Now          func() time.Time
+	jobs         []Job
+}
+
+// NewRunner creates a Runner that checks for due jobs every 15 seconds and
+// gives each run up to five minutes
+func NewRunner(store Store, log *zap.Logger) *Runner {
+	return &Runner{
+		Store:        store,
+		Log:          log,
+		Owner:        owner(),
+		Lease:        5 * time.Minute,
+		PollInterval: 15 * time.Second,
+		Now:          time.Now,
+	}
+}
+
+// owner names this process, unique even among processes on the same host
+func owner() string {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
+
+// Register adds jobs to the ones the Runner runs
+func (r *Runner) Register(jobs ...Job) {
+	r.jobs = append(r.jobs, jobs...)
+}
+
+// Run runs jobs as they become due until ctx is done. A run in progress is
+// cancelled through its context.
+func (r *Runner) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.PollInterval)
+	defer ticker.Stop()
+	for {
+		r.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce runs every job that is due and not leased by another server
+func (r *Runner) RunOnce(ctx context.Context) {
+	for _, job := range r.jobs {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := r.run(ctx, job); err != nil {
+			r.Log.Error("Failed to run job", zap.Error(err), zap.String("job", job.Name))
+		}
+	}
+}
+
+// run leases a job and runs it if it is due, then records the outcome
+func (r *Runner) run(ctx context.Context, job Job) error {
+	start := r.Now()
+	leased, err := r.Store.ClaimJob(ctx, job.Name, r.Owner, start, r.Lease)
+	if err != nil || leased == nil {
+		return err
+	}
+
+	runCtx, cancel := context.WithTimeout(logger.WithFields(ctx, zap.String("job", job.Name)), r.Lease)
+	runErr := job.Run(runCtx)
+	cancel()
+
+	leased.LastRunAt = &start
+	leased.LastError = ""
+	if runErr != nil {
+		leased.LastError = runErr.Error()
+		r.Log.Warn("Job failed", zap.Error(runErr), zap.String("job", job.Name))
+	} else {
+		r.Log.Info("Job completed",
+			zap.String("job", job.Name),
+			zap.Duration("duration", r.Now().Sub(start)))
+	}
+	// A run cut short by shutting down stays due, for the next server to pick
+	// up, and its lease is released even though ctx is done
+	if ctx.Err() == nil {
+		leased.NextRunAt = start.Add(job.Interval)
+	}
+	return r.Store.FinishJob(context.WithoutCancel(ctx), leased)
+}
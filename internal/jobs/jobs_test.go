@@ -0,0 +1,316 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tusharsingune/meeting-scheduler/internal/config"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"github.com/tusharsingune/meeting-scheduler/internal/notify"
+	"github.com/tusharsingune/meeting-scheduler/internal/repository"
+	"go.uber.org/zap"
+)
+
+// leaseStore keeps jobs in a map and leases them like the database does
+type leaseStore struct {
+	jobs     map[string]*models.Job
+	finished []models.Job
+}
+
+func newLeaseStore() *leaseStore {
+	return &leaseStore{jobs: make(map[string]*models.Job)}
+}
+
+func (s *leaseStore) ClaimJob(ctx context.Context, name, owner string, now time.Time, lease time.Duration) (*models.Job, error) {
+	job, ok := s.jobs[name]
+	if !ok {
+		job = &models.Job{Name: name, NextRunAt: now}
+		s.jobs[name] = job
+	}
+	if job.NextRunAt.After(now) || (job.LockedUntil != nil && job.LockedUntil.After(now)) {
+		return nil, nil
+	}
+	until := now.Add(lease)
+	job.LockedBy = owner
+	job.LockedUntil = &until
+	leased := *job
+	return &leased, nil
+}
+
+func (s *leaseStore) FinishJob(ctx context.Context, job *models.Job) error {
+	stored := s.jobs[job.Name]
+	if stored.LockedBy != job.LockedBy {
+		return nil
+	}
+	stored.NextRunAt = job.NextRunAt
+	stored.LastRunAt = job.LastRunAt
+	stored.LastError = job.LastError
+	stored.LockedBy = ""
+	stored.LockedUntil = nil
+	s.finished = append(s.finished, *stored)
+	return nil
+}
+
+func testRunner(store Store, now *time.Time, owner string) *Runner {
+	r := NewRunner(store, zap.NewNop())
+	r.Owner = owner
+	r.Now = func() time.Time { return *now }
+	return r
+}
+
+func TestRunnerRunsDueJobsOnce(t *testing.T) {
+	now := time.Date(2030, 6, 3, 12, 0, 0, 0, time.UTC)
+	store := newLeaseStore()
+	runs := 0
+	job := Job{Name: "count", Interval: time.Minute, Run: func(ctx context.Context) error {
+		runs++
+		return nil
+	}}
+
+	first := testRunner(store, &now, "first")
+	second := testRunner(store, &now, "second")
+	first.Register(job)
+	second.Register(job)
+
+	first.RunOnce(context.Background())
+	second.RunOnce(context.Background())
+	assert.Equal(t, 1, runs, "the job is not due again until its interval has passed")
+	assert.Equal(t, now.Add(time.Minute), store.jobs["count"].NextRunAt)
+
+	now = now.Add(time.Minute)
+	second.RunOnce(context.Background())
+	assert.Equal(t, 2, runs)
+}
+
+func TestRunnerSkipsLeasedJobs(t *testing.T) {
+	now := time.Date(2030, 6, 3, 12, 0, 0, 0, time.UTC)
+	store := newLeaseStore()
+	until := now.Add(time.Minute)
+	store.jobs["count"] = &models.Job{Name: "count", NextRunAt: now, LockedBy: "other", LockedUntil: &until}
+
+	runs := 0
+	runner := testRunner(store, &now, "mine")
+	runner.Register(Job{Name: "count", Interval: time.Minute, Run: func(ctx context.Context) error {
+		runs++
+		return nil
+	}})
+
+	runner.RunOnce(context.Background())
+	assert.Equal(t, 0, runs)
+
+	now = until
+	runner.RunOnce(context.Background())
+	assert.Equal(t, 1, runs, "an expired lease is taken over")
+}
+
+func TestRunnerRecordsFailures(t *testing.T) {
+	now := time.Date(2030, 6, 3, 12, 0, 0, 0, time.UTC)
+	store := newLeaseStore()
+	runner := testRunner(store, &now, "mine")
+	runner.Register(Job{Name: "fail", Interval: time.Hour, Run: func(ctx context.Context) error {
+		return errors.New("database down")
+	}})
+
+	runner.RunOnce(context.Background())
+
+	require.Len(t, store.finished, 1)
+	assert.Equal(t, "database down", store.finished[0].LastError)
+	assert.Equal(t, now.Add(time.Hour), store.finished[0].NextRunAt, "failed runs wait for their next turn")
+	assert.Empty(t, store.finished[0].LockedBy)
+}
+
+func TestRunnerLeavesInterruptedJobsDue(t *testing.T) {
+	now := time.Date(2030, 6, 3, 12, 0, 0, 0, time.UTC)
+	store := newLeaseStore()
+	ctx, cancel := context.WithCancel(context.Background())
+	runner := testRunner(store, &now, "mine")
+	runner.Register(Job{Name: "slow", Interval: time.Hour, Run: func(ctx context.Context) error {
+		cancel()
+		return ctx.Err()
+	}})
+
+	runner.RunOnce(ctx)
+
+	require.Len(t, store.finished, 1)
+	assert.Equal(t, now, store.finished[0].NextRunAt)
+	assert.Nil(t, store.finished[0].LockedUntil, "the lease is released for another server")
+}
+
+// taskRepo implements the repository methods the tasks use. Calling any
+// other method panics.
+type taskRepo struct {
+	repository.Repository
+	expired         []models.Event
+	toRemind        []models.Event
+	closed          []models.Event
+	recommendations []models.TimeSlotRecommendation
+	reminded        []uint
+	remindedBefore  []uint // events another server has reminded of
+	unreadable      []uint // events whose availabilities cannot be read
+	outbox          []models.OutboxEvent
+	notifications   []models.Notification
+	purgedBefore    time.Time
+}
+
+func (r *taskRepo) Transaction(ctx context.Context, fn func(repository.Repository) error) error {
+	return fn(r)
+}
+
+func (r *taskRepo) GetExpiredPolls(ctx context.Context, now time.Time, limit int) ([]models.Event, error) {
+	return r.expired, nil
+}
+
+func (r *taskRepo) ClosePoll(ctx context.Context, event *models.Event, now time.Time) (bool, error) {
+	r.closed = append(r.closed, *event)
+	return event.ID != 2, nil // event 2 was confirmed meanwhile
+}
+
+func (r *taskRepo) GetTimeSlotRecommendations(ctx context.Context, eventID uint) ([]models.TimeSlotRecommendation, error) {
+	return r.recommendations, nil
+}
+
+func (r *taskRepo) CreateOutboxEvent(ctx context.Context, event *models.OutboxEvent) error {
+	r.outbox = append(r.outbox, *event)
+	return nil
+}
+
+func (r *taskRepo) GetPollsToRemind(ctx context.Context, now, deadlineBefore time.Time, limit int) ([]models.Event, error) {
+	return r.toRemind, nil
+}
+
+func (r *taskRepo) GetEventAvailabilities(ctx context.Context, eventID uint) ([]models.Availability, error) {
+	if slices.Contains(r.unreadable, eventID) {
+		return nil, errors.New("database down")
+	}
+	return []models.Availability{{ParticipantID: 2, TimeSlotID: 5}}, nil
+}
+
+func (r *taskRepo) MarkReminded(ctx context.Context, eventID uint, at time.Time) (bool, error) {
+	if slices.Contains(r.remindedBefore, eventID) {
+		return false, nil
+	}
+	r.reminded = append(r.reminded, eventID)
+	return true, nil
+}
+
+func (r *taskRepo) CreateNotification(ctx context.Context, n *models.Notification) error {
+	r.notifications = append(r.notifications, *n)
+	return nil
+}
+
+func (r *taskRepo) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	r.purgedBefore = before
+	return 3, nil
+}
+
+func testTasks(repo *taskRepo, now time.Time) (*Tasks, *notify.Recorder) {
+	recorder := notify.NewRecorder()
+	tasks := NewTasks(repo, notify.NewService(recorder, notify.DefaultTemplates(), repo, zap.NewNop()), config.JobsConfig{}, zap.NewNop())
+	tasks.Now = func() time.Time { return now }
+	return tasks, recorder
+}
+
+func pollEvent(id uint) models.Event {
+	deadline := time.Date(2030, 6, 3, 12, 0, 0, 0, time.UTC)
+	return models.Event{
+		ID:           id,
+		Title:        "Planning",
+		OrganizerId:  1,
+		Status:       models.EventPolling,
+		PollDeadline: &deadline,
+		Invitations: []models.EventInvitation{
+			{ParticipantID: 2, Participant: &models.Participant{ID: 2, Name: "Grace", Email: "grace@example.com"}},
+			{ParticipantID: 3, Participant: &models.Participant{ID: 3, Name: "Alan", Email: "alan@example.com"}},
+		},
+	}
+}
+
+func TestClosePolls(t *testing.T) {
+	repo := &taskRepo{expired: []models.Event{pollEvent(1), pollEvent(2)}}
+	tasks, recorder := testTasks(repo, time.Date(2030, 6, 3, 12, 1, 0, 0, time.UTC))
+
+	require.NoError(t, tasks.ClosePolls(context.Background()))
+
+	require.Len(t, repo.closed, 2)
+	assert.Equal(t, models.EventClosed, repo.closed[0].Status, "the manual policy leaves the choice to the organizer")
+	assert.Equal(t, models.DecisionNeedsAttention, repo.closed[0].DecisionOutcome)
+	assert.NotEmpty(t, repo.closed[0].DecisionReason)
+	require.Len(t, repo.outbox, 1, "only polls that were actually closed are published")
+	assert.Equal(t, "event.closed", repo.outbox[0].Type)
+	assert.Contains(t, repo.outbox[0].Payload, `"status":"closed"`)
+	assert.Empty(t, recorder.Messages())
+}
+
+func TestClosePollsConfirmsByPolicy(t *testing.T) {
+	start := time.Date(2030, 6, 10, 9, 0, 0, 0, time.UTC)
+	event := pollEvent(1)
+	event.DecisionPolicy = models.DecisionTopRecommendation
+	repo := &taskRepo{
+		expired: []models.Event{event},
+		recommendations: []models.TimeSlotRecommendation{
+			{TimeSlot: models.TimeSlot{ID: 5, EventID: 1, StartTime: start, EndTime: start.Add(time.Hour)}, Score: 1, AvailableCount: 2},
+		},
+	}
+	now := time.Date(2030, 6, 3, 12, 1, 0, 0, time.UTC)
+	tasks, recorder := testTasks(repo, now)
+
+	require.NoError(t, tasks.ClosePolls(context.Background()))
+
+	require.Len(t, repo.closed, 1)
+	closed := repo.closed[0]
+	assert.Equal(t, models.EventConfirmed, closed.Status)
+	assert.Equal(t, models.DecisionConfirmed, closed.DecisionOutcome)
+	require.NotNil(t, closed.ConfirmedTimeSlotID)
+	assert.Equal(t, uint(5), *closed.ConfirmedTimeSlotID)
+	assert.Equal(t, now, *closed.DecidedAt)
+	assert.Contains(t, closed.DecisionReason, "time slot 5")
+	require.Len(t, repo.outbox, 1)
+	assert.Equal(t, "event.confirmed", repo.outbox[0].Type)
+	assert.Empty(t, recorder.Messages(), "confirmations are sent in the background")
+	require.Len(t, repo.notifications, 2, "invitees are told about the confirmation")
+	for _, n := range repo.notifications {
+		assert.Equal(t, "confirmation", n.Kind)
+		assert.Equal(t, models.NotificationPending, n.Status)
+	}
+}
+
+func TestSendReminders(t *testing.T) {
+	repo := &taskRepo{toRemind: []models.Event{pollEvent(1)}}
+	tasks, recorder := testTasks(repo, time.Date(2030, 6, 2, 18, 0, 0, 0, time.UTC))
+
+	require.NoError(t, tasks.SendReminders(context.Background()))
+
+	require.Len(t, recorder.Messages(), 1, "only invitees who have not answered are reminded")
+	assert.Equal(t, "alan@example.com", recorder.Messages()[0].To)
+	assert.Equal(t, []uint{1}, repo.reminded)
+}
+
+func TestSendRemindersOncePerEvent(t *testing.T) {
+	repo := &taskRepo{
+		toRemind:       []models.Event{pollEvent(1), pollEvent(2), pollEvent(3)},
+		remindedBefore: []uint{1},
+		unreadable:     []uint{2},
+	}
+	tasks, recorder := testTasks(repo, time.Date(2030, 6, 2, 18, 0, 0, 0, time.UTC))
+
+	err := tasks.SendReminders(context.Background())
+
+	assert.ErrorContains(t, err, "database down")
+	assert.Equal(t, []uint{3}, repo.reminded, "a failing event does not hold up the others")
+	require.Len(t, recorder.Messages(), 1, "an event another server reminded of is skipped")
+}
+
+func TestPurgeDeleted(t *testing.T) {
+	repo := &taskRepo{}
+	now := time.Date(2030, 6, 3, 12, 0, 0, 0, time.UTC)
+	tasks, _ := testTasks(repo, now)
+
+	require.NoError(t, tasks.PurgeDeleted(context.Background()))
+
+	assert.Equal(t, now.Add(-30*24*time.Hour), repo.purgedBefore)
+}
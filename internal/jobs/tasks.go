@@ -0,0 +1,189 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tusharsingune/meeting-scheduler/internal/config"
+	"github.com/tusharsingune/meeting-scheduler/internal/logger"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"github.com/tusharsingune/meeting-scheduler/internal/notify"
+	"github.com/tusharsingune/meeting-scheduler/internal/repository"
+	"github.com/tusharsingune/meeting-scheduler/internal/scheduling"
+	"github.com/tusharsingune/meeting-scheduler/internal/webhook"
+	"go.uber.org/zap"
+)
+
+// Tasks is the scheduler's own background work: deciding polls at their
+// deadline, reminding invitees before it and purging deleted rows
+type Tasks struct {
+	Repo          repository.Repository
+	Notifications *notify.Service
+	Log           *zap.Logger
+	ReminderLead  time.Duration // how long before a poll deadline unanswered invitees are reminded
+	Retention     time.Duration // how long soft-deleted rows are kept
+	BatchSize     int           // events handled per run
+	Now           func() time.Time
+}
+
+// NewTasks creates the background tasks from the configuration
+func NewTasks(repo repository.Repository, notifications *notify.Service, cfg config.JobsConfig, log *zap.Logger) *Tasks {
+	reminderLead := time.Duration(cfg.ReminderLeadHours) * time.Hour
+	if reminderLead <= 0 {
+		reminderLead = 24 * time.Hour
+	}
+	retention := time.Duration(cfg.RetentionDays) * 24 * time.Hour
+	if retention <= 0 {
+		retention = 30 * 24 * time.Hour
+	}
+	return &Tasks{
+		Repo:          repo,
+		Notifications: notifications,
+		Log:           log,
+		ReminderLead:  reminderLead,
+		Retention:     retention,
+		BatchSize:     100,
+		Now:           time.Now,
+	}
+}
+
+// Jobs returns the tasks as jobs with their schedules
+func (t *Tasks) Jobs() []Job {
+	return []Job{
+		{Name: "close-polls", Interval: time.Minute, Run: t.ClosePolls},
+		{Name: "send-reminders", Interval: 5 * time.Minute, Run: t.SendReminders},
+		{Name: "purge-deleted", Interval: time.Hour, Run: t.PurgeDeleted},
+	}
+}
+
+// ClosePolls applies the decision policy of events whose poll deadline has
+// passed: each either has its best time slot confirmed or is closed for the
+// organizer to decide. The outcome is stored on the event, published to the
+// organizer's webhooks and, for confirmations, emailed to the invitees.
+func (t *Tasks) ClosePolls(ctx context.Context) error {
+	now := t.Now()
+	events, err := t.Repo.GetExpiredPolls(ctx, now, t.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	// One event failing does not hold up the others
+	var errs []error
+	for i := range events {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := t.closePoll(ctx, &events[i], now); err != nil {
+			logger.FromContext(ctx, t.Log).Error("Failed to close poll", zap.Error(err), zap.Uint("event_id", events[i].ID))
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// closePoll decides a single expired poll
+func (t *Tasks) closePoll(ctx context.Context, event *models.Event, now time.Time) error {
+	recommendations, err := t.Repo.GetTimeSlotRecommendations(ctx, event.ID)
+	if err != nil {
+		return err
+	}
+
+	slot, reason := scheduling.Decide(*event, recommendations)
+	eventType := webhook.EventClosed
+	event.Status = models.EventClosed
+	event.DecisionOutcome = models.DecisionNeedsAttention
+	if slot != nil {
+		event.Confirm(*slot, now)
+		event.DecisionOutcome = models.DecisionConfirmed
+		eventType = webhook.EventConfirmed
+	}
+	event.DecisionReason = reason
+	event.DecidedAt = &now
+
+	closed := false
+	err = t.Repo.Transaction(ctx, func(tx repository.Repository) error {
+		ok, err := tx.ClosePoll(ctx, event, now)
+		if err != nil || !ok {
+			return err
+		}
+		closed = true
+		if slot != nil {
+			if err := t.Notifications.QueueConfirmed(ctx, tx, event); err != nil {
+				return err
+			}
+		}
+		outbox, err := webhook.NewOutboxEvent(eventType, event.OrganizerId, event, now)
+		if err != nil {
+			return err
+		}
+		return tx.CreateOutboxEvent(ctx, outbox)
+	})
+	if err != nil || !closed {
+		return err
+	}
+
+	logger.FromContext(ctx, t.Log).Info("Poll closed",
+		zap.Uint("event_id", event.ID),
+		zap.String("outcome", string(event.DecisionOutcome)),
+		zap.String("reason", reason))
+	return nil
+}
+
+// SendReminders reminds the invitees who have not answered an event's poll
+// once, ReminderLead before its deadline
+func (t *Tasks) SendReminders(ctx context.Context) error {
+	now := t.Now()
+	events, err := t.Repo.GetPollsToRemind(ctx, now, now.Add(t.ReminderLead), t.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	// One event failing does not hold up the others
+	var errs []error
+	for i := range events {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := t.remind(ctx, &events[i], now); err != nil {
+			logger.FromContext(ctx, t.Log).Error("Failed to send reminders", zap.Error(err), zap.Uint("event_id", events[i].ID))
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// remind reminds the invitees of one event who have not answered. The event
+// is marked as reminded first, so that a server that finds it already marked
+// by another sends nothing.
+func (t *Tasks) remind(ctx context.Context, event *models.Event, now time.Time) error {
+	availabilities, err := t.Repo.GetEventAvailabilities(ctx, event.ID)
+	if err != nil {
+		return err
+	}
+	marked, err := t.Repo.MarkReminded(ctx, event.ID, now)
+	if err != nil || !marked {
+		return err
+	}
+	notifications, err := t.Notifications.Remind(ctx, event, notify.Unanswered(event, availabilities))
+	if err != nil {
+		return err
+	}
+	logger.FromContext(ctx, t.Log).Info("Reminders sent",
+		zap.Uint("event_id", event.ID),
+		zap.Int("count", len(notifications)))
+	return nil
+}
+
+// PurgeDeleted permanently removes rows deleted, and webhook deliveries and
+// outbox events finished, longer than Retention ago
+func (t *Tasks) PurgeDeleted(ctx context.Context) error {
+	purged, err := t.Repo.PurgeDeleted(ctx, t.Now().Add(-t.Retention))
+	if err != nil {
+		return err
+	}
+	if purged > 0 {
+		logger.FromContext(ctx, t.Log).Info("Deleted rows purged", zap.Int64("count", purged))
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// fieldsKey is the key the fields of a context's log lines are stored under
+type fieldsKey struct{}
+
+// WithFields returns a copy of ctx whose log lines carry the fields, on top
+// of those ctx carries already
+func WithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	existing, _ := ctx.Value(fieldsKey{}).([]zap.Field)
+	combined := make([]zap.Field, 0, len(existing)+len(fields))
+	combined = append(append(combined, existing...), fields...)
+	return context.WithValue(ctx, fieldsKey{}, combined)
+}
+
+// FromContext returns log with the fields ctx carries, such as the ID of the
+// request being served
+func FromContext(ctx context.Context, log *zap.Logger) *zap.Logger {
+	fields, _ := ctx.Value(fieldsKey{}).([]zap.Field)
+	if len(fields) == 0 {
+		return log
+	}
+	return log.With(fields...)
+}
+
+// Ctx returns the application logger with the fields ctx carries
+func Ctx(ctx context.Context) *zap.Logger {
+	return FromContext(ctx, GetLogger())
+}
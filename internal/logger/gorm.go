@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+)
+
+// GormLogger writes GORM's logs, including every SQL statement, through zap.
+// Statements run with a request's context are logged with its request ID.
+type GormLogger struct {
+	log           *zap.Logger
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+}
+
+// NewGormLogger creates a GormLogger that logs statements at debug level and
+// those slower than 200ms as warnings
+func NewGormLogger(log *zap.Logger) *GormLogger {
+	return &GormLogger{log: log, level: gormlogger.Info, slowThreshold: 200 * time.Millisecond}
+}
+
+// LogMode returns a copy of the logger that logs at the level
+func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+// Info logs a message from GORM at info level
+func (l *GormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		FromContext(ctx, l.log).Sugar().Infof(msg, args...)
+	}
+}
+
+// Warn logs a message from GORM at warn level
+func (l *GormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		FromContext(ctx, l.log).Sugar().Warnf(msg, args...)
+	}
+}
+
+// Error logs a message from GORM at error level
+func (l *GormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		FromContext(ctx, l.log).Sugar().Errorf(msg, args...)
+	}
+}
+
+// Trace logs a statement once it has run. Missing records are left to the
+// caller, which usually expects them.
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	sql, rows := fc()
+	fields := []zap.Field{
+		zap.String("sql", sql),
+		zap.Int64("rows", rows),
+		zap.Duration("duration", elapsed),
+		zap.String("source", utils.FileWithLineNum()),
+	}
+	log := FromContext(ctx, l.log)
+
+	switch {
+	case err != nil && l.level >= gormlogger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		log.Error("SQL statement failed", append(fields, zap.Error(err))...)
+	case elapsed > l.slowThreshold && l.level >= gormlogger.Warn:
+		log.Warn("Slow SQL statement", fields...)
+	case l.level >= gormlogger.Info:
+		log.Debug("SQL statement", fields...)
+	}
+}
@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestGormLoggerTrace(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	log := NewGormLogger(zap.New(core))
+	ctx := WithFields(context.Background(), zap.String("request_id", "req-7"))
+	statement := func() (string, int64) { return `SELECT * FROM "events" WHERE id = 1`, 1 }
+
+	log.Trace(ctx, time.Now(), statement, nil)
+	log.Trace(ctx, time.Now().Add(-time.Second), statement, nil)
+	log.Trace(ctx, time.Now(), statement, errors.New("connection reset"))
+	log.Trace(ctx, time.Now(), statement, gorm.ErrRecordNotFound)
+	log.LogMode(gormlogger.Silent).Trace(ctx, time.Now(), statement, errors.New("connection reset"))
+
+	entries := logs.All()
+	require.Len(t, entries, 4)
+	levels := []zapcore.Level{zapcore.DebugLevel, zapcore.WarnLevel, zapcore.ErrorLevel, zapcore.DebugLevel}
+	for i, entry := range entries {
+		assert.Equal(t, levels[i], entry.Level, entry.Message)
+		assert.Equal(t, "req-7", entry.ContextMap()["request_id"])
+		assert.Equal(t, `SELECT * FROM "events" WHERE id = 1`, entry.ContextMap()["sql"])
+	}
+}
+
+func TestWithFieldsKeepsEarlierFields(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	parent := WithFields(context.Background(), zap.String("request_id", "req-7"))
+	child := WithFields(parent, zap.String("job", "close-polls"))
+
+	FromContext(child, zap.New(core)).Info("child")
+	FromContext(parent, zap.New(core)).Info("parent")
+
+	entries := logs.All()
+	require.Len(t, entries, 2)
+	assert.Equal(t, map[string]interface{}{"request_id": "req-7", "job": "close-polls"}, entries[0].ContextMap())
+	assert.Equal(t, map[string]interface{}{"request_id": "req-7"}, entries[1].ContextMap())
+}
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tusharsingune/meeting-scheduler/internal/auth"
+	"github.com/tusharsingune/meeting-scheduler/internal/logger"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"github.com/tusharsingune/meeting-scheduler/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ParticipantContextKey is the key the authenticated participant is stored under in the context
+const ParticipantContextKey contextKey = "participant"
+
+// ErrUnauthenticated is returned by an Authenticator for requests without valid credentials
+var ErrUnauthenticated = errors.New("missing or invalid credentials")
+
+// Authenticator resolves the participant making a request
+type Authenticator interface {
+	Authenticate(r *http.Request) (*models.Participant, error)
+}
+
+// Authenticate rejects requests an Authenticator cannot resolve to a
+// participant and stores the participant in the context of the others
+func Authenticate(a Authenticator) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			participant, err := a.Authenticate(r)
+			if err != nil {
+				if errors.Is(err, ErrUnauthenticated) {
+					w.Header().Set("WWW-Authenticate", `Bearer realm="meeting-scheduler"`)
+					writeError(w, http.StatusUnauthorized, "Authentication required")
+					return
+				}
+				logger.Ctx(r.Context()).Error("Failed to authenticate request", zap.Error(err))
+				writeError(w, http.StatusInternalServerError, "")
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(WithParticipant(r.Context(), participant)))
+		})
+	}
+}
+
+// WithParticipant returns a copy of ctx carrying the authenticated participant
+func WithParticipant(ctx context.Context, participant *models.Participant) context.Context {
+	return context.WithValue(ctx, ParticipantContextKey, participant)
+}
+
+// ParticipantFromContext returns the authenticated participant, if any
+func ParticipantFromContext(ctx context.Context) (*models.Participant, bool) {
+	participant, ok := ctx.Value(ParticipantContextKey).(*models.Participant)
+	return participant, ok && participant != nil
+}
+
+// CredentialAuthenticator accepts signed bearer tokens and API keys. Tokens
+// are sent as "Authorization: Bearer <token>"; API keys either the same way
+// or in the X-API-Key header.
+type CredentialAuthenticator struct {
+	Tokens *auth.Signer
+	Repo   repository.Repository
+	Now    func() time.Time // defaults to time.Now
+}
+
+// Authenticate resolves the participant a request's credentials belong to
+func (a *CredentialAuthenticator) Authenticate(r *http.Request) (*models.Participant, error) {
+	now := time.Now()
+	if a.Now != nil {
+		now = a.Now()
+	}
+
+	credential := r.Header.Get("X-API-Key")
+	if credential == "" {
+		scheme, value, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !strings.EqualFold(scheme, "Bearer") {
+			return nil, ErrUnauthenticated
+		}
+		credential = strings.TrimSpace(value)
+	}
+	if credential == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	if auth.IsAPIKey(credential) {
+		return a.apiKeyParticipant(r.Context(), credential, now)
+	}
+
+	claims, err := a.Tokens.Verify(credential, now)
+	if err != nil {
+		return nil, ErrUnauthenticated
+	}
+	participant, err := a.Repo.GetParticipant(r.Context(), claims.ParticipantID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrUnauthenticated
+	}
+	return participant, err
+}
+
+// apiKeyParticipant returns the participant an unexpired API key belongs to
+func (a *CredentialAuthenticator) apiKeyParticipant(ctx context.Context, key string, now time.Time) (*models.Participant, error) {
+	apiKey, err := a.Repo.GetAPIKeyByHash(ctx, auth.HashAPIKey(key))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrUnauthenticated
+	}
+	if err != nil {
+		return nil, err
+	}
+	if apiKey.Expired(now) || apiKey.Participant == nil {
+		return nil, ErrUnauthenticated
+	}
+	return apiKey.Participant, nil
+}
+
+// writeError responds with a problem like the API handlers do
+func writeError(w http.ResponseWriter, code int, message string) {
+	WriteProblem(w, NewProblem(code, message))
+}
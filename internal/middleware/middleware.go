@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/tusharsingune/meeting-scheduler/internal/logger"
@@ -34,9 +36,9 @@ func Logger(next http.Handler) http.Handler {
 
 		duration := time.Since(start)
 
-		logger.GetLogger().Info("request completed",
+		logger.Ctx(r.Context()).Info("request completed",
 			zap.String("method", r.Method),
-			zap.String("path", r.URL.Path),
+			zap.String("path", loggedPath(r.URL.Path)),
 			zap.String("remote_addr", r.RemoteAddr),
 			zap.Int("status", rw.status),
 			zap.Duration("duration", duration),
@@ -44,12 +46,30 @@ func Logger(next http.Handler) http.Handler {
 	})
 }
 
+// credentialSegments are the path segments followed by a credential, such as
+// the guest invitation token in /api/v1/guest/{token}
+var credentialSegments = []string{"guest"}
+
+// loggedPath returns a request path with the credentials in it redacted, so
+// they stay out of the logs
+func loggedPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i := 0; i < len(segments)-1; i++ {
+		for _, segment := range credentialSegments {
+			if segments[i] == segment && segments[i+1] != "" {
+				segments[i+1] = "REDACTED"
+			}
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
 // CORS handles Cross-Origin Resource Sharing
 func CORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -65,29 +85,48 @@ func Recovery(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
-				logger.GetLogger().Error("panic recovered",
+				logger.Ctx(r.Context()).Error("panic recovered",
 					zap.Any("error", err),
-					zap.String("path", r.URL.Path),
+					zap.String("path", loggedPath(r.URL.Path)),
 				)
-				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				WriteProblem(w, NewProblem(http.StatusInternalServerError, ""))
 			}
 		}()
 		next.ServeHTTP(w, r)
 	})
 }
 
+// RequestIDHeader is the header a request ID is read from and returned in
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDContextKey is the key the request ID is stored under in the context
+const RequestIDContextKey contextKey = "request_id"
+
 // RequestID adds a request ID to the context
 func RequestID(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		requestID := r.Header.Get("X-Request-ID")
+		requestID := r.Header.Get(RequestIDHeader)
 		if requestID == "" {
 			requestID = generateRequestID()
 		}
-		w.Header().Set("X-Request-ID", requestID)
-		next.ServeHTTP(w, r)
+		w.Header().Set(RequestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(WithRequestID(r.Context(), requestID)))
 	})
 }
 
+// WithRequestID returns a copy of ctx carrying the request ID, which every
+// line logged with it through logger.FromContext includes
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	ctx = logger.WithFields(ctx, zap.String("request_id", requestID))
+	return context.WithValue(ctx, RequestIDContextKey, requestID)
+}
+
+// RequestIDFromContext returns the ID of the request, or "" outside of one
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(RequestIDContextKey).(string)
+	return requestID
+}
+
 // Custom response writer to capture status code
 type responseWriter struct {
 	http.ResponseWriter
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggedPathRedactsCredentials(t *testing.T) {
+	tests := map[string]string{
+		"/api/v1/guest/msi_secret":              "/api/v1/guest/REDACTED",
+		"/api/v1/guest/msi_secret/availability": "/api/v1/guest/REDACTED/availability",
+		"/api/v1/guest/":                        "/api/v1/guest/",
+		"/api/v1/events/1/timeslots":            "/api/v1/events/1/timeslots",
+	}
+	for path, want := range tests {
+		assert.Equal(t, want, loggedPath(path), path)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"reflect"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -16,7 +18,20 @@ const (
 	ValidatedContextKey contextKey = "validated"
 )
 
-var validate = validator.New()
+var validate = newValidator()
+
+// newValidator creates a validator that reports fields by their JSON names
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return v
+}
 
 // ValidationError represents a validation error
 type ValidationError struct {
@@ -35,7 +50,7 @@ func ValidateRequest(next http.HandlerFunc, model interface{}) http.HandlerFunc
 
 		// Decode request body
 		if err := json.NewDecoder(r.Body).Decode(&val); err != nil {
-			http.Error(w, "Invalid request payload", http.StatusBadRequest)
+			WriteProblem(w, NewProblem(http.StatusBadRequest, "Invalid request payload"))
 			return
 		}
 
@@ -50,11 +65,9 @@ func ValidateRequest(next http.HandlerFunc, model interface{}) http.HandlerFunc
 				})
 			}
 
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"errors": errors,
-			})
+			problem := NewProblem(http.StatusBadRequest, "The request has invalid fields")
+			problem.Errors = errors
+			WriteProblem(w, problem)
 			return
 		}
 
@@ -64,10 +77,37 @@ func ValidateRequest(next http.HandlerFunc, model interface{}) http.HandlerFunc
 	}
 }
 
+// ValidateStruct validates a struct and returns its field errors, if any.
+// Nested fields are reported with their full path, e.g. "availabilities[0].time_slot_id".
+func ValidateStruct(s interface{}) ValidationErrors {
+	err := validate.Struct(s)
+	if err == nil {
+		return nil
+	}
+
+	fieldErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return ValidationErrors{{Field: "", Message: err.Error()}}
+	}
+
+	var errors ValidationErrors
+	for _, fe := range fieldErrors {
+		field := fe.Namespace()
+		if i := strings.Index(field, "."); i >= 0 {
+			field = field[i+1:]
+		}
+		errors = append(errors, ValidationError{
+			Field:   field,
+			Message: getErrorMsg(fe),
+		})
+	}
+	return errors
+}
+
 // getErrorMsg returns a human-readable error message for validation errors
 func getErrorMsg(err validator.FieldError) string {
 	switch err.Tag() {
-	case "required":
+	case "required", "required_without":
 		return "This field is required"
 	case "email":
 		return "Invalid email format"
@@ -0,0 +1,140 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // registers the pgx driver with database/sql
+	"github.com/tusharsingune/meeting-scheduler/internal/config"
+	"go.uber.org/zap"
+)
+
+// Usage describes the arguments of the migrate command
+const Usage = `usage: migrate [command]
+
+commands:
+  up            apply every pending migration (the default)
+  down [N]      revert the N most recently applied migrations, 1 unless given
+  status        list the migrations and whether they are applied
+  to VERSION    apply or revert migrations until VERSION is the latest applied;
+                0 reverts every migration`
+
+// command is a parsed invocation of the migrate command
+type command struct {
+	name    string // up, down, status or to
+	steps   int    // migrations to revert with down
+	version int64  // target of to
+}
+
+// parseCommand reads the arguments of the migrate command
+func parseCommand(args []string) (command, error) {
+	if len(args) == 0 {
+		return command{name: "up"}, nil
+	}
+
+	cmd := command{name: args[0]}
+	switch cmd.name {
+	case "up", "status":
+		if len(args) > 1 {
+			return command{}, fmt.Errorf("%s takes no arguments", cmd.name)
+		}
+	case "down":
+		cmd.steps = 1
+		if len(args) > 2 {
+			return command{}, errors.New("down takes at most one argument")
+		}
+		if len(args) == 2 {
+			steps, err := strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return command{}, fmt.Errorf("down needs a positive number of migrations, not %q", args[1])
+			}
+			cmd.steps = steps
+		}
+	case "to":
+		if len(args) != 2 {
+			return command{}, errors.New("to needs a version")
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil || version < 0 {
+			return command{}, fmt.Errorf("to needs a migration version, not %q", args[1])
+		}
+		cmd.version = version
+	default:
+		return command{}, fmt.Errorf("unknown command %q", cmd.name)
+	}
+	return cmd, nil
+}
+
+// Run connects to the database and runs the migrate command with the
+// arguments, writing its report to out
+func Run(ctx context.Context, cfg config.DatabaseConfig, args []string, out io.Writer, log *zap.Logger) error {
+	cmd, err := parseCommand(args)
+	if err != nil {
+		return fmt.Errorf("%w\n\n%s", err, Usage)
+	}
+
+	db, err := sql.Open("pgx", cfg.DSN())
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	m, err := New(db, log)
+	if err != nil {
+		return err
+	}
+	return m.run(ctx, cmd, out)
+}
+
+// run executes a parsed command
+func (m *Migrator) run(ctx context.Context, cmd command, out io.Writer) error {
+	var (
+		count int
+		err   error
+	)
+	switch cmd.name {
+	case "up":
+		count, err = m.Up(ctx)
+	case "down":
+		count, err = m.Down(ctx, cmd.steps)
+	case "to":
+		count, err = m.To(ctx, cmd.version)
+	case "status":
+		return m.writeStatus(ctx, out)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "%d migrations run\n", count)
+	return nil
+}
+
+// writeStatus prints a table of the migrations and their state
+func (m *Migrator) writeStatus(ctx context.Context, out io.Writer) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT\tNOTE")
+	for _, status := range statuses {
+		appliedAt, note := "pending", ""
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.UTC().Format("2006-01-02 15:04:05")
+		}
+		switch {
+		case status.Missing:
+			note = "not in this binary"
+		case status.Modified:
+			note = "modified since applied"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", status.Version, status.Name, appliedAt, note)
+	}
+	return w.Flush()
+}
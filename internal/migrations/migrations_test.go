@@ -0,0 +1,160 @@
+package migrations
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func file(sql string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(sql)}
+}
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load(fstest.MapFS{
+		"0002_add_index.up.sql":      file("CREATE INDEX idx ON t (c);"),
+		"0002_add_index.down.sql":    file("DROP INDEX idx;"),
+		"0001_create_table.up.sql":   file("CREATE TABLE t (c int);"),
+		"0001_create_table.down.sql": file("DROP TABLE t;"),
+		"README.md":                  file("not a migration"),
+	})
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+
+	assert.Equal(t, int64(1), migrations[0].Version)
+	assert.Equal(t, "create_table", migrations[0].Name)
+	assert.Equal(t, "CREATE TABLE t (c int);", migrations[0].Up)
+	assert.Equal(t, "DROP TABLE t;", migrations[0].Down)
+	assert.Len(t, migrations[0].Checksum, 64)
+	assert.Equal(t, int64(2), migrations[1].Version)
+	assert.NotEqual(t, migrations[0].Checksum, migrations[1].Checksum)
+}
+
+func TestLoadRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		fsys  fstest.MapFS
+		error string
+	}{
+		{
+			"missing down file",
+			fstest.MapFS{"0001_create_table.up.sql": file("CREATE TABLE t (c int);")},
+			"has no down file",
+		},
+		{
+			"missing up file",
+			fstest.MapFS{"0001_create_table.down.sql": file("DROP TABLE t;")},
+			"has no up file",
+		},
+		{
+			"badly named file",
+			fstest.MapFS{"create_table.sql": file("CREATE TABLE t (c int);")},
+			"is not named like",
+		},
+		{
+			"version zero",
+			fstest.MapFS{
+				"0000_create_table.up.sql":   file("CREATE TABLE t (c int);"),
+				"0000_create_table.down.sql": file("DROP TABLE t;"),
+			},
+			"invalid version",
+		},
+		{
+			"names differ between up and down",
+			fstest.MapFS{
+				"0001_create_table.up.sql": file("CREATE TABLE t (c int);"),
+				"0001_create_t.down.sql":   file("DROP TABLE t;"),
+			},
+			"is named both",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.fsys)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.error)
+		})
+	}
+}
+
+func TestEmbedded(t *testing.T) {
+	migrations, err := Embedded()
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+
+	for i, m := range migrations {
+		assert.Equal(t, int64(i+1), m.Version, "versions are consecutive")
+		assert.NotEmpty(t, m.Up)
+		assert.NotEmpty(t, m.Down)
+	}
+	assert.Equal(t, "initial", migrations[0].Name)
+}
+
+func TestPlan(t *testing.T) {
+	migrations := []Migration{{Version: 1}, {Version: 2}, {Version: 3}, {Version: 4}}
+	versionsOf := func(ms []Migration) []int64 {
+		var vs []int64
+		for _, m := range ms {
+			vs = append(vs, m.Version)
+		}
+		return vs
+	}
+
+	tests := []struct {
+		name     string
+		applied  map[int64]bool
+		target   int64
+		wantDown []int64
+		wantUp   []int64
+	}{
+		{"all pending", nil, 4, nil, []int64{1, 2, 3, 4}},
+		{"up to date", map[int64]bool{1: true, 2: true, 3: true, 4: true}, 4, nil, nil},
+		{"forward to a version", map[int64]bool{1: true}, 3, nil, []int64{2, 3}},
+		{"back to a version", map[int64]bool{1: true, 2: true, 3: true, 4: true}, 2, []int64{4, 3}, nil},
+		{"back to nothing", map[int64]bool{1: true, 2: true}, 0, []int64{2, 1}, nil},
+		{"fills a gap", map[int64]bool{1: true, 3: true}, 4, nil, []int64{2, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			down, up := plan(migrations, tt.applied, tt.target)
+			assert.Equal(t, tt.wantDown, versionsOf(down))
+			assert.Equal(t, tt.wantUp, versionsOf(up))
+		})
+	}
+}
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		args []string
+		want command
+	}{
+		{nil, command{name: "up"}},
+		{[]string{"up"}, command{name: "up"}},
+		{[]string{"status"}, command{name: "status"}},
+		{[]string{"down"}, command{name: "down", steps: 1}},
+		{[]string{"down", "3"}, command{name: "down", steps: 3}},
+		{[]string{"to", "2"}, command{name: "to", version: 2}},
+		{[]string{"to", "0"}, command{name: "to"}},
+	}
+	for _, tt := range tests {
+		cmd, err := parseCommand(tt.args)
+		require.NoError(t, err, tt.args)
+		assert.Equal(t, tt.want, cmd, tt.args)
+	}
+
+	for _, args := range [][]string{
+		{"sideways"},
+		{"up", "2"},
+		{"down", "0"},
+		{"down", "many"},
+		{"to"},
+		{"to", "-1"},
+	} {
+		_, err := parseCommand(args)
+		assert.Error(t, err, args)
+	}
+}
@@ -0,0 +1,342 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// lockKey identifies the advisory lock migration runs hold, so servers
+// starting together apply each migration once
+const lockKey int64 = 0x6d6967726174 // "migrat"
+
+// ErrChecksumMismatch means a migration was edited after it was applied
+var ErrChecksumMismatch = errors.New("applied migration was modified")
+
+// ErrUnknownVersion means the database has a migration applied that this
+// binary does not have, or a target version that does not exist
+var ErrUnknownVersion = errors.New("unknown migration version")
+
+// Status is a migration and whether it has been applied
+type Status struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time // nil while pending
+	Modified  bool       // the file changed since the migration was applied
+	Missing   bool       // applied, but not built into this binary
+}
+
+// Migrator applies migrations to a PostgreSQL database, recording them in
+// the schema_migrations table
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	log        *zap.Logger
+}
+
+// New returns a migrator for the migrations built into the binary
+func New(db *sql.DB, log *zap.Logger) (*Migrator, error) {
+	migrations, err := Embedded()
+	if err != nil {
+		return nil, err
+	}
+	return NewWithMigrations(db, migrations, log), nil
+}
+
+// NewWithMigrations returns a migrator for the given migrations, which must
+// be ordered by version
+func NewWithMigrations(db *sql.DB, migrations []Migration, log *zap.Logger) *Migrator {
+	return &Migrator{db: db, migrations: migrations, log: log}
+}
+
+// Latest returns the version of the newest migration, or 0 when there are none
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// appliedMigration is a row of schema_migrations
+type appliedMigration struct {
+	Version   int64
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// Up applies every pending migration and returns how many it applied
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	var count int
+	err := m.locked(ctx, func(conn *sql.Conn, applied map[int64]appliedMigration) error {
+		// A newer release may have migrated the database already; its
+		// migrations keep the schema compatible with this one
+		if newer := m.missing(applied, 0); len(newer) > 0 {
+			m.log.Warn("The database has migrations applied that this binary does not have",
+				zap.Strings("migrations", newer))
+		}
+		_, up := plan(m.migrations, versions(applied), m.Latest())
+		for _, migration := range up {
+			if err := m.apply(ctx, conn, migration, true); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Down reverts the given number of most recently applied migrations
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	var count int
+	err := m.locked(ctx, func(conn *sql.Conn, applied map[int64]appliedMigration) error {
+		appliedVersions := make([]int64, 0, len(applied))
+		for version := range applied {
+			appliedVersions = append(appliedVersions, version)
+		}
+		sort.Slice(appliedVersions, func(i, j int) bool { return appliedVersions[i] > appliedVersions[j] })
+
+		if len(appliedVersions) > steps {
+			appliedVersions = appliedVersions[:steps]
+		}
+		if len(appliedVersions) > 0 {
+			if missing := m.missing(applied, appliedVersions[len(appliedVersions)-1]-1); len(missing) > 0 {
+				return errMissing(missing)
+			}
+		}
+
+		for _, version := range appliedVersions {
+			migration, _ := m.find(version)
+			if err := m.apply(ctx, conn, migration, false); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// To applies or reverts migrations until the schema is at the target
+// version. Version 0 reverts every migration.
+func (m *Migrator) To(ctx context.Context, target int64) (int, error) {
+	if _, ok := m.find(target); !ok && target != 0 {
+		return 0, fmt.Errorf("%w: %d", ErrUnknownVersion, target)
+	}
+
+	var count int
+	err := m.locked(ctx, func(conn *sql.Conn, applied map[int64]appliedMigration) error {
+		if missing := m.missing(applied, target); len(missing) > 0 {
+			return errMissing(missing)
+		}
+		down, up := plan(m.migrations, versions(applied), target)
+		for _, migration := range down {
+			if err := m.apply(ctx, conn, migration, false); err != nil {
+				return err
+			}
+			count++
+		}
+		for _, migration := range up {
+			if err := m.apply(ctx, conn, migration, true); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Status lists the migrations of the binary and any applied migrations it
+// does not have, ordered by version
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// Status only reads, so it neither takes the lock nor creates the table
+	applied := make(map[int64]appliedMigration)
+	var exists bool
+	if err := conn.QueryRowContext(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists); err != nil {
+		return nil, err
+	}
+	if exists {
+		if applied, err = loadApplied(ctx, conn); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if row, ok := applied[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.AppliedAt = &appliedAt
+			status.Modified = row.Checksum != migration.Checksum
+		}
+		statuses = append(statuses, status)
+	}
+	for version, row := range applied {
+		if _, ok := m.find(version); !ok {
+			appliedAt := row.AppliedAt
+			statuses = append(statuses, Status{Version: version, Name: row.Name, AppliedAt: &appliedAt, Missing: true})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// locked runs fn on a connection holding the migration lock, with the
+// migrations applied so far. It refuses to run when an applied migration was
+// modified or is missing from the binary, since the schema is then not the
+// one the migrations describe.
+func (m *Migrator) locked(ctx context.Context, fn func(*sql.Conn, map[int64]appliedMigration) error) error {
+	// Advisory locks belong to a session, so everything runs on one connection
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	m.log.Debug("Waiting for the migration lock")
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		return fmt.Errorf("failed to take the migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", lockKey)
+
+	if err := createTable(ctx, conn); err != nil {
+		return err
+	}
+	applied, err := loadApplied(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	var modified []string
+	for version, row := range applied {
+		if migration, ok := m.find(version); ok && migration.Checksum != row.Checksum {
+			modified = append(modified, fmt.Sprintf("%d_%s", version, row.Name))
+		}
+	}
+	if len(modified) > 0 {
+		sort.Strings(modified)
+		return fmt.Errorf("%w: %s", ErrChecksumMismatch, strings.Join(modified, ", "))
+	}
+
+	return fn(conn, applied)
+}
+
+// missing returns the applied migrations above a version that this binary
+// does not have, such as those of a newer release
+func (m *Migrator) missing(applied map[int64]appliedMigration, above int64) []string {
+	var names []string
+	for version, row := range applied {
+		if _, ok := m.find(version); !ok && version > above {
+			names = append(names, fmt.Sprintf("%d_%s", version, row.Name))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// errMissing reports applied migrations that cannot be reverted because this
+// binary does not have them
+func errMissing(names []string) error {
+	return fmt.Errorf("%w: the database has %s applied, which this binary cannot revert", ErrUnknownVersion, strings.Join(names, ", "))
+}
+
+// apply runs a migration up or down in a transaction that also records it
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration, up bool) error {
+	direction, statements := "up", migration.Up
+	if !up {
+		direction, statements = "down", migration.Down
+	}
+	started := time.Now()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, statements); err != nil {
+		return fmt.Errorf("migration %d_%s %s failed: %w", migration.Version, migration.Name, direction, err)
+	}
+	if up {
+		_, err = tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)",
+			migration.Version, migration.Name, migration.Checksum)
+	} else {
+		_, err = tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = $1", migration.Version)
+	}
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	m.log.Info("Migration applied",
+		zap.Int64("version", migration.Version),
+		zap.String("name", migration.Name),
+		zap.String("direction", direction),
+		zap.Duration("duration", time.Since(started)))
+	return nil
+}
+
+// find returns the migration with the version
+func (m *Migrator) find(version int64) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// createTable creates the table recording applied migrations
+func createTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version bigint PRIMARY KEY,
+		name text NOT NULL,
+		checksum char(64) NOT NULL,
+		applied_at timestamptz NOT NULL DEFAULT now()
+	)`)
+	return err
+}
+
+// loadApplied returns the applied migrations by version
+func loadApplied(ctx context.Context, conn *sql.Conn) (map[int64]appliedMigration, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, name, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]appliedMigration)
+	for rows.Next() {
+		var row appliedMigration
+		if err := rows.Scan(&row.Version, &row.Name, &row.Checksum, &row.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied[row.Version] = row
+	}
+	return applied, rows.Err()
+}
+
+// versions returns the set of applied versions
+func versions(applied map[int64]appliedMigration) map[int64]bool {
+	set := make(map[int64]bool, len(applied))
+	for version := range applied {
+		set[version] = true
+	}
+	return set
+}
@@ -0,0 +1,45 @@
+-- The schema of the first release, which the server created with AutoMigrate.
+-- Databases it created already have these tables, so every statement is
+-- skipped where its table exists, and the next migration upgrades them.
+
+CREATE TABLE IF NOT EXISTS events (
+    id bigserial PRIMARY KEY,
+    title text NOT NULL,
+    description text,
+    organizer_id bigint NOT NULL,
+    duration bigint NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS time_slots (
+    id bigserial PRIMARY KEY,
+    event_id bigint NOT NULL,
+    start_time timestamptz NOT NULL,
+    end_time timestamptz NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    CONSTRAINT fk_events_time_slots FOREIGN KEY (event_id) REFERENCES events (id)
+);
+
+CREATE TABLE IF NOT EXISTS participants (
+    id bigserial PRIMARY KEY,
+    name text NOT NULL,
+    email text NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    CONSTRAINT uni_participants_email UNIQUE (email)
+);
+
+CREATE TABLE IF NOT EXISTS availabilities (
+    id bigserial PRIMARY KEY,
+    participant_id bigint NOT NULL,
+    time_slot_id bigint NOT NULL,
+    is_available boolean NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz
+);
@@ -0,0 +1,28 @@
+-- Answers merged into the history and converted statuses stay as they are
+DROP TABLE IF EXISTS jobs;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS outbox_events;
+DROP TABLE IF EXISTS webhooks;
+DROP TABLE IF EXISTS notifications;
+DROP INDEX IF EXISTS idx_availability_participant_slot;
+DROP TABLE IF EXISTS availability_histories;
+ALTER TABLE availabilities
+    DROP COLUMN IF EXISTS weight,
+    DROP COLUMN IF EXISTS status;
+DROP TABLE IF EXISTS occurrence_exceptions;
+DROP TABLE IF EXISTS invitation_links;
+DROP TABLE IF EXISTS event_invitations;
+ALTER TABLE events
+    DROP COLUMN IF EXISTS decided_at,
+    DROP COLUMN IF EXISTS decision_reason,
+    DROP COLUMN IF EXISTS decision_outcome,
+    DROP COLUMN IF EXISTS decision_policy,
+    DROP COLUMN IF EXISTS reminded_at,
+    DROP COLUMN IF EXISTS poll_deadline,
+    DROP COLUMN IF EXISTS confirmed_at,
+    DROP COLUMN IF EXISTS confirmed_time_slot_id,
+    DROP COLUMN IF EXISTS time_zone,
+    DROP COLUMN IF EXISTS recurrence,
+    DROP COLUMN IF EXISTS status;
+DROP TABLE IF EXISTS api_keys;
+ALTER TABLE participants DROP COLUMN IF EXISTS time_zone;
@@ -0,0 +1,212 @@
+-- Brings the first release's schema up to the one migrations were introduced
+-- with. Releases in between created their changes with AutoMigrate, so each
+-- change is skipped where it was already made, and data is only converted
+-- where it still has the older shape.
+
+-- Participants
+
+ALTER TABLE participants ADD COLUMN IF NOT EXISTS time_zone varchar(64) NOT NULL DEFAULT 'UTC';
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id bigserial PRIMARY KEY,
+    participant_id bigint NOT NULL,
+    name text,
+    prefix varchar(16) NOT NULL,
+    hash char(64) NOT NULL,
+    expires_at timestamptz,
+    created_at timestamptz,
+    deleted_at timestamptz,
+    CONSTRAINT fk_api_keys_participant FOREIGN KEY (participant_id) REFERENCES participants (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_hash ON api_keys (hash);
+CREATE INDEX IF NOT EXISTS idx_api_keys_participant_id ON api_keys (participant_id);
+
+-- Events
+
+-- Events from before the lifecycle are polling once they have time slots
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'events' AND column_name = 'status'
+    ) THEN
+        ALTER TABLE events ADD COLUMN status varchar(16) NOT NULL DEFAULT 'draft';
+        UPDATE events SET status = 'polling'
+        WHERE EXISTS (SELECT 1 FROM time_slots WHERE time_slots.event_id = events.id);
+    END IF;
+END
+$$;
+
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS recurrence varchar(255),
+    ADD COLUMN IF NOT EXISTS time_zone varchar(64),
+    ADD COLUMN IF NOT EXISTS confirmed_time_slot_id bigint,
+    ADD COLUMN IF NOT EXISTS confirmed_at timestamptz,
+    ADD COLUMN IF NOT EXISTS poll_deadline timestamptz,
+    ADD COLUMN IF NOT EXISTS reminded_at timestamptz,
+    ADD COLUMN IF NOT EXISTS decision_policy varchar(32) NOT NULL DEFAULT 'manual',
+    ADD COLUMN IF NOT EXISTS decision_outcome varchar(32),
+    ADD COLUMN IF NOT EXISTS decision_reason text,
+    ADD COLUMN IF NOT EXISTS decided_at timestamptz;
+
+CREATE TABLE IF NOT EXISTS event_invitations (
+    id bigserial PRIMARY KEY,
+    event_id bigint NOT NULL,
+    participant_id bigint NOT NULL,
+    role varchar(16) NOT NULL DEFAULT 'required',
+    co_organizer boolean NOT NULL DEFAULT false,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    CONSTRAINT fk_event_invitations_participant FOREIGN KEY (participant_id) REFERENCES participants (id),
+    CONSTRAINT fk_events_invitations FOREIGN KEY (event_id) REFERENCES events (id)
+);
+ALTER TABLE event_invitations ADD COLUMN IF NOT EXISTS co_organizer boolean NOT NULL DEFAULT false;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invitation_event_participant ON event_invitations (event_id, participant_id);
+
+CREATE TABLE IF NOT EXISTS invitation_links (
+    id bigserial PRIMARY KEY,
+    event_id bigint NOT NULL,
+    email text NOT NULL,
+    name text,
+    role varchar(16) NOT NULL DEFAULT 'required',
+    participant_id bigint,
+    expires_at timestamptz NOT NULL,
+    used_at timestamptz,
+    revoked_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_invitation_links_event_id ON invitation_links (event_id);
+
+CREATE TABLE IF NOT EXISTS occurrence_exceptions (
+    id bigserial PRIMARY KEY,
+    event_id bigint NOT NULL,
+    occurrence_start timestamptz NOT NULL,
+    cancelled boolean NOT NULL,
+    start_time timestamptz,
+    end_time timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    CONSTRAINT fk_events_exceptions FOREIGN KEY (event_id) REFERENCES events (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_exception_event_occurrence ON occurrence_exceptions (event_id, occurrence_start);
+
+-- Availability
+
+-- Answers from before three-state availability only had is_available, which
+-- has been kept in step with status since, so only those can disagree
+ALTER TABLE availabilities
+    ADD COLUMN IF NOT EXISTS status varchar(16) NOT NULL DEFAULT 'yes',
+    ADD COLUMN IF NOT EXISTS weight decimal;
+UPDATE availabilities SET status = 'no' WHERE is_available = false AND status = 'yes';
+
+CREATE TABLE IF NOT EXISTS availability_histories (
+    id bigserial PRIMARY KEY,
+    availability_id bigint NOT NULL,
+    participant_id bigint NOT NULL,
+    time_slot_id bigint NOT NULL,
+    status varchar(16),
+    weight decimal,
+    is_available boolean NOT NULL,
+    answered_at timestamptz,
+    created_at timestamptz
+);
+ALTER TABLE availability_histories
+    ADD COLUMN IF NOT EXISTS status varchar(16),
+    ADD COLUMN IF NOT EXISTS weight decimal;
+UPDATE availability_histories
+SET status = CASE WHEN is_available THEN 'yes' ELSE 'no' END
+WHERE status IS NULL;
+CREATE INDEX IF NOT EXISTS idx_availability_histories_availability_id ON availability_histories (availability_id);
+CREATE INDEX IF NOT EXISTS idx_availability_histories_participant_id ON availability_histories (participant_id);
+CREATE INDEX IF NOT EXISTS idx_availability_histories_time_slot_id ON availability_histories (time_slot_id);
+
+-- The first release allowed several answers per participant and time slot.
+-- All but the latest move into the history so the unique index can be created.
+CREATE TEMPORARY TABLE superseded_availabilities ON COMMIT DROP AS
+SELECT a.* FROM availabilities a
+WHERE EXISTS (
+    SELECT 1 FROM availabilities b
+    WHERE b.participant_id = a.participant_id
+        AND b.time_slot_id = a.time_slot_id
+        AND b.id > a.id
+);
+INSERT INTO availability_histories
+    (availability_id, participant_id, time_slot_id, status, weight, is_available, answered_at, created_at)
+SELECT id, participant_id, time_slot_id, status, weight, is_available, updated_at, now()
+FROM superseded_availabilities;
+DELETE FROM availabilities WHERE id IN (SELECT id FROM superseded_availabilities);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_availability_participant_slot ON availabilities (participant_id, time_slot_id);
+
+-- Notifications and webhooks
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id bigserial PRIMARY KEY,
+    event_id bigint NOT NULL,
+    participant_id bigint NOT NULL,
+    kind varchar(32) NOT NULL,
+    channel varchar(16) NOT NULL,
+    recipient text NOT NULL,
+    subject text,
+    status varchar(16) NOT NULL,
+    error text,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_notifications_event_id ON notifications (event_id);
+CREATE INDEX IF NOT EXISTS idx_notifications_participant_id ON notifications (participant_id);
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id bigserial PRIMARY KEY,
+    participant_id bigint NOT NULL,
+    url text NOT NULL,
+    secret text NOT NULL,
+    event_types text,
+    created_at timestamptz,
+    deleted_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_webhooks_participant_id ON webhooks (participant_id);
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id bigserial PRIMARY KEY,
+    type varchar(64) NOT NULL,
+    organizer_id bigint NOT NULL,
+    payload text NOT NULL,
+    created_at timestamptz,
+    dispatched_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_dispatched_at ON outbox_events (dispatched_at);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id bigserial PRIMARY KEY,
+    webhook_id bigint NOT NULL,
+    outbox_event_id bigint NOT NULL,
+    event_type varchar(64) NOT NULL,
+    payload text NOT NULL,
+    status varchar(16) NOT NULL,
+    attempts bigint NOT NULL DEFAULT 0,
+    next_attempt_at timestamptz NOT NULL,
+    last_attempt_at timestamptz,
+    response_status bigint,
+    error text,
+    delivered_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz,
+    CONSTRAINT fk_webhook_deliveries_webhook FOREIGN KEY (webhook_id) REFERENCES webhooks (id)
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_next_attempt_at ON webhook_deliveries (next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_status ON webhook_deliveries (status);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id);
+
+-- Background jobs
+
+CREATE TABLE IF NOT EXISTS jobs (
+    name varchar(64) PRIMARY KEY,
+    next_run_at timestamptz NOT NULL,
+    locked_by varchar(128),
+    locked_until timestamptz,
+    last_run_at timestamptz,
+    last_error text,
+    updated_at timestamptz
+);
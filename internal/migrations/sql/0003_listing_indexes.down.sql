@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_participants_email_prefix;
+DROP INDEX IF EXISTS idx_time_slots_event_start;
+DROP INDEX IF EXISTS idx_event_invitations_participant_id;
+DROP INDEX IF EXISTS idx_events_organizer_id;
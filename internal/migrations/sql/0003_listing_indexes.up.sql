@@ -0,0 +1,5 @@
+-- Indexes for listing events, time slots and participants
+CREATE INDEX IF NOT EXISTS idx_events_organizer_id ON events (organizer_id);
+CREATE INDEX IF NOT EXISTS idx_event_invitations_participant_id ON event_invitations (participant_id);
+CREATE INDEX IF NOT EXISTS idx_time_slots_event_start ON time_slots (event_id, start_time);
+CREATE INDEX IF NOT EXISTS idx_participants_email_prefix ON participants (lower(email) text_pattern_ops);
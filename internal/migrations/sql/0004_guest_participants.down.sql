@@ -0,0 +1,4 @@
+-- Fails while a guest shares an email with another participant
+DROP INDEX IF EXISTS idx_participants_email;
+ALTER TABLE participants ADD CONSTRAINT uni_participants_email UNIQUE (email);
+ALTER TABLE participants DROP COLUMN IF EXISTS guest;
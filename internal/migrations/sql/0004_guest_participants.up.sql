@@ -0,0 +1,5 @@
+-- Invitation links answer as guest participants of their own, who may share
+-- their email with an account or with other guests
+ALTER TABLE participants ADD COLUMN IF NOT EXISTS guest boolean NOT NULL DEFAULT false;
+ALTER TABLE participants DROP CONSTRAINT IF EXISTS uni_participants_email;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_participants_email ON participants (email) WHERE NOT guest;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_invitation_links_token_hash;
+ALTER TABLE invitation_links DROP COLUMN IF EXISTS token_hash;
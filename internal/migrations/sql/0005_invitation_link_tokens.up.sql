@@ -0,0 +1,5 @@
+-- Invitation links are looked up by the hash of a random token instead of
+-- verifying a token signed with the server's key. Links created before have
+-- no hash and can no longer be used; organizers create them again.
+ALTER TABLE invitation_links ADD COLUMN IF NOT EXISTS token_hash char(64);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invitation_links_token_hash ON invitation_links (token_hash);
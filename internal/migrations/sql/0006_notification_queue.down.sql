@@ -0,0 +1,3 @@
+DELETE FROM notifications WHERE status = 'pending';
+DROP INDEX IF EXISTS idx_notifications_next_attempt_at;
+ALTER TABLE notifications DROP COLUMN IF EXISTS next_attempt_at;
@@ -0,0 +1,4 @@
+-- Invitations and confirmations are queued as pending notifications with the
+-- change they are about and sent in the background
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS next_attempt_at timestamptz;
+CREATE INDEX IF NOT EXISTS idx_notifications_next_attempt_at ON notifications (next_attempt_at);
@@ -0,0 +1,101 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tusharsingune/meeting-scheduler/internal/config"
+	"go.uber.org/zap"
+)
+
+// upgradeSchema keeps the test's tables apart from those of other tests
+// sharing the database
+const upgradeSchema = "upgrade_test"
+
+// TestUpgradeFromInitial migrates a database the first release created with
+// AutoMigrate, holding data in the shape that release wrote. It is skipped
+// unless TEST_DB_HOST is set.
+func TestUpgradeFromInitial(t *testing.T) {
+	if testing.Short() || os.Getenv("TEST_DB_HOST") == "" {
+		t.Skip("set TEST_DB_HOST and the other TEST_DB_* variables to run against PostgreSQL")
+	}
+	ctx := context.Background()
+	cfg := config.DatabaseConfig{
+		Host:     os.Getenv("TEST_DB_HOST"),
+		Port:     envOr("TEST_DB_PORT", "5432"),
+		User:     envOr("TEST_DB_USER", "postgres"),
+		Password: os.Getenv("TEST_DB_PASSWORD"),
+		DBName:   envOr("TEST_DB_NAME", "scheduler_test"),
+		SSLMode:  "disable",
+	}
+	admin, err := sql.Open("pgx", cfg.DSN())
+	require.NoError(t, err)
+	t.Cleanup(func() { admin.Close() })
+	_, err = admin.Exec(`DROP SCHEMA IF EXISTS ` + upgradeSchema + ` CASCADE; CREATE SCHEMA ` + upgradeSchema)
+	require.NoError(t, err)
+	t.Cleanup(func() { admin.Exec(`DROP SCHEMA IF EXISTS ` + upgradeSchema + ` CASCADE`) })
+
+	db, err := sql.Open("pgx", cfg.DSN()+" search_path="+upgradeSchema)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	migrations, err := Embedded()
+	require.NoError(t, err)
+	// The first release had no schema_migrations table, only its own
+	_, err = db.Exec(migrations[0].Up)
+	require.NoError(t, err)
+	_, err = db.Exec(`
+		INSERT INTO participants (name, email) VALUES ('Ada', 'ada@example.com');
+		INSERT INTO events (title, organizer_id, duration) VALUES ('Planning', 1, 60), ('Retrospective', 1, 30);
+		INSERT INTO time_slots (event_id, start_time, end_time)
+			VALUES (1, '2030-06-03 09:00+00', '2030-06-03 10:00+00');
+		INSERT INTO availabilities (participant_id, time_slot_id, is_available, updated_at)
+			VALUES (1, 1, true, '2030-01-01 00:00+00'), (1, 1, false, '2030-01-02 00:00+00');`)
+	require.NoError(t, err)
+
+	migrator := NewWithMigrations(db, migrations, zap.NewNop())
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, len(migrations), applied, "the first migration is recorded even though its tables exist")
+
+	var statuses []string
+	rows, err := db.Query(`SELECT status FROM events ORDER BY id`)
+	require.NoError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var status string
+		require.NoError(t, rows.Scan(&status))
+		statuses = append(statuses, status)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []string{"polling", "draft"}, statuses, "an event with time slots is polling")
+
+	var count int
+	var status string
+	require.NoError(t, db.QueryRow(`SELECT count(*), min(status) FROM availabilities`).Scan(&count, &status))
+	assert.Equal(t, 1, count, "one answer per participant and time slot")
+	assert.Equal(t, "no", status, "the latest answer is kept, with its status")
+	require.NoError(t, db.QueryRow(`SELECT count(*), min(status) FROM availability_histories`).Scan(&count, &status))
+	assert.Equal(t, 1, count, "the earlier answer is kept in the history")
+	assert.Equal(t, "yes", status)
+
+	// Going back to the first release's schema and up again keeps the data
+	_, err = migrator.To(ctx, 1)
+	require.NoError(t, err)
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	require.NoError(t, db.QueryRow(`SELECT count(*) FROM availabilities WHERE status = 'no'`).Scan(&count))
+	assert.Equal(t, 1, count, "statuses are converted again from is_available")
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -6,17 +6,88 @@ import (
 	"gorm.io/gorm"
 )
 
+// EventStatus is the lifecycle stage of an event
+type EventStatus string
+
+const (
+	EventDraft     EventStatus = "draft"     // created, no time slots yet
+	EventPolling   EventStatus = "polling"   // collecting availability for its time slots
+	EventClosed    EventStatus = "closed"    // past its poll deadline, waiting for a time slot to be chosen
+	EventConfirmed EventStatus = "confirmed" // one time slot has been chosen
+	EventCancelled EventStatus = "cancelled"
+)
+
+// DecisionPolicy is how an event's time slot is chosen when its poll deadline passes
+type DecisionPolicy string
+
+const (
+	DecisionManual               DecisionPolicy = "manual"                 // the organizer chooses
+	DecisionTopRecommendation    DecisionPolicy = "top_recommendation"     // the best recommended slot is confirmed
+	DecisionAllRequiredAvailable DecisionPolicy = "all_required_available" // the best slot every required attendee can make is confirmed, if there is one
+)
+
+// DecisionOutcome is what happened to an event when its poll deadline passed
+type DecisionOutcome string
+
+const (
+	DecisionConfirmed      DecisionOutcome = "confirmed"       // a time slot was confirmed by the decision policy
+	DecisionNeedsAttention DecisionOutcome = "needs_attention" // the poll was closed for the organizer to decide
+)
+
 // Event represents a scheduled event
 type Event struct {
-	ID          uint           `json:"id" gorm:"primaryKey"`
-	Title       string         `json:"title" gorm:"not null"`
-	Description string         `json:"description"`
-	OrganizerId uint           `json:"organizer_id" gorm:"not null"`
-	Duration    int            `json:"duration" gorm:"not null"` // in minutes
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"-"`
-	TimeSlots   []TimeSlot     `json:"time_slots,omitempty" gorm:"foreignKey:EventID"`
+	ID                  uint                  `json:"id" gorm:"primaryKey"`
+	Title               string                `json:"title" gorm:"not null"`
+	Description         string                `json:"description"`
+	OrganizerId         uint                  `json:"organizer_id" gorm:"not null"`
+	Duration            int                   `json:"duration" gorm:"not null"` // in minutes
+	Status              EventStatus           `json:"status" gorm:"type:varchar(16);not null;default:'draft'"`
+	Recurrence          string                `json:"recurrence,omitempty" gorm:"type:varchar(255)"`                             // RRULE value, e.g. FREQ=WEEKLY;BYDAY=TU
+	TimeZone            string                `json:"time_zone,omitempty" gorm:"type:varchar(64)" validate:"omitempty,timezone"` // zone whose wall clock a recurring event follows
+	ConfirmedTimeSlotID *uint                 `json:"confirmed_time_slot_id,omitempty"`
+	ConfirmedAt         *time.Time            `json:"confirmed_at,omitempty"`
+	PollDeadline        *time.Time            `json:"poll_deadline,omitempty"` // when invitees should have answered by
+	RemindedAt          *time.Time            `json:"reminded_at,omitempty"`   // when unanswered invitees were reminded of the deadline
+	DecisionPolicy      DecisionPolicy        `json:"decision_policy" gorm:"type:varchar(32);not null;default:'manual'"`
+	DecisionOutcome     DecisionOutcome       `json:"decision_outcome,omitempty" gorm:"type:varchar(32)"` // set when the poll deadline passes
+	DecisionReason      string                `json:"decision_reason,omitempty"`                          // why the policy reached its outcome
+	DecidedAt           *time.Time            `json:"decided_at,omitempty"`                               // when the decision policy was applied
+	CreatedAt           time.Time             `json:"created_at"`
+	UpdatedAt           time.Time             `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt        `json:"-"`
+	TimeSlots           []TimeSlot            `json:"time_slots,omitempty" gorm:"foreignKey:EventID"`
+	Invitations         []EventInvitation     `json:"invitations,omitempty" gorm:"foreignKey:EventID"`
+	Exceptions          []OccurrenceException `json:"exceptions,omitempty" gorm:"foreignKey:EventID"`
+
+	// ConfirmedTimeSlot is filled in from TimeSlots when the event is loaded
+	ConfirmedTimeSlot *TimeSlot `json:"confirmed_time_slot,omitempty" gorm:"-"`
+	// Occurrences lists upcoming occurrences of a confirmed recurring event on responses
+	Occurrences []Occurrence `json:"occurrences,omitempty" gorm:"-"`
+}
+
+// IsRecurring reports whether the event repeats. The time slots of a
+// recurring event are the first occurrences of candidate series, and
+// confirming one of them confirms that series.
+func (e Event) IsRecurring() bool {
+	return e.Recurrence != ""
+}
+
+// AcceptsAvailability reports whether participants can still answer the event's poll
+func (e Event) AcceptsAvailability() bool {
+	return !e.Decided() && e.Status != EventClosed
+}
+
+// Decided reports whether the event has been confirmed or cancelled
+func (e Event) Decided() bool {
+	return e.Status == EventConfirmed || e.Status == EventCancelled
+}
+
+// Confirm locks in one of the event's time slots
+func (e *Event) Confirm(slot TimeSlot, at time.Time) {
+	e.Status = EventConfirmed
+	e.ConfirmedTimeSlotID = &slot.ID
+	e.ConfirmedTimeSlot = &slot
+	e.ConfirmedAt = &at
 }
 
 // TimeSlot represents a potential time for an event
@@ -28,34 +99,412 @@ type TimeSlot struct {
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-"`
+
+	// Local is set on responses when the viewer asked for a time zone
+	Local *LocalTimeRange `json:"local,omitempty" gorm:"-"`
+}
+
+// Overlaps reports whether two time slots share any period of time
+func (s TimeSlot) Overlaps(other TimeSlot) bool {
+	return s.StartTime.Before(other.EndTime) && other.StartTime.Before(s.EndTime)
+}
+
+// OccurrenceException cancels or moves a single occurrence of a recurring event
+type OccurrenceException struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	EventID         uint           `json:"event_id" gorm:"not null;uniqueIndex:idx_exception_event_occurrence"`
+	OccurrenceStart time.Time      `json:"occurrence_start" gorm:"not null;uniqueIndex:idx_exception_event_occurrence" validate:"required"` // scheduled start of the occurrence
+	Cancelled       bool           `json:"cancelled" gorm:"not null"`
+	StartTime       *time.Time     `json:"start_time,omitempty" validate:"required_without=Cancelled"` // new time of a moved occurrence
+	EndTime         *time.Time     `json:"end_time,omitempty" validate:"required_without=Cancelled"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-"`
+}
+
+// Occurrence is a single meeting of a recurring event
+type Occurrence struct {
+	OccurrenceStart time.Time `json:"occurrence_start"` // scheduled start, identifies the occurrence
+	StartTime       time.Time `json:"start_time"`
+	EndTime         time.Time `json:"end_time"`
+	Cancelled       bool      `json:"cancelled,omitempty"`
+	Moved           bool      `json:"moved,omitempty"`
+
+	// Local is set on responses when the viewer asked for a time zone
+	Local *LocalTimeRange `json:"local,omitempty"`
+}
+
+// LocalTimeRange is a time slot as seen from a particular time zone
+type LocalTimeRange struct {
+	TimeZone  string    `json:"time_zone"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
 }
 
 // Participant represents a user who can participate in events
 type Participant struct {
 	ID        uint           `json:"id" gorm:"primaryKey"`
 	Name      string         `json:"name" gorm:"not null"`
-	Email     string         `json:"email" gorm:"not null;unique"`
+	Email     string         `json:"email" gorm:"not null;uniqueIndex:idx_participants_email,where:NOT guest"`
+	TimeZone  string         `json:"time_zone" gorm:"type:varchar(64);not null;default:'UTC'" validate:"omitempty,timezone"` // IANA name
+	Guest     bool           `json:"guest" gorm:"not null;default:false"`                                                    // answers through an invitation link; shares its email with others
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-"`
+
+	// APIKey is set only on the response that creates the participant
+	APIKey string `json:"api_key,omitempty" gorm:"-"`
 }
 
-// Availability represents a participant's availability for a time slot
-type Availability struct {
+// APIKey is a long-lived credential a participant calls the API with. Only a
+// hash of the key is stored; the key itself is shown once, when it is created.
+type APIKey struct {
 	ID            uint           `json:"id" gorm:"primaryKey"`
-	ParticipantID uint           `json:"participant_id" gorm:"not null"`
-	TimeSlotID    uint           `json:"time_slot_id" gorm:"not null"`
-	IsAvailable   bool           `json:"is_available" gorm:"not null"`
+	ParticipantID uint           `json:"participant_id" gorm:"not null;index"`
+	Name          string         `json:"name" validate:"max=100"`
+	Prefix        string         `json:"prefix" gorm:"type:varchar(16);not null"` // start of the key, to tell keys apart
+	Hash          string         `json:"-" gorm:"type:char(64);not null;uniqueIndex"`
+	ExpiresAt     *time.Time     `json:"expires_at,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	DeletedAt     gorm.DeletedAt `json:"-"`
+	Participant   *Participant   `json:"-" gorm:"foreignKey:ParticipantID"`
+
+	// Key is set only on the response that creates the key
+	Key string `json:"key,omitempty" gorm:"-"`
+}
+
+// Expired reports whether the key can no longer be used at the given time
+func (k APIKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// AccessToken is a short-lived bearer token issued to a participant
+type AccessToken struct {
+	Token     string    `json:"token"`
+	TokenType string    `json:"token_type"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AttendeeRole tells whether an invitee must attend an event
+type AttendeeRole string
+
+const (
+	AttendeeRequired AttendeeRole = "required"
+	AttendeeOptional AttendeeRole = "optional"
+)
+
+// EventInvitation links a participant to an event they are invited to
+type EventInvitation struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	EventID       uint           `json:"event_id" gorm:"not null;uniqueIndex:idx_invitation_event_participant"`
+	ParticipantID uint           `json:"participant_id" gorm:"not null;uniqueIndex:idx_invitation_event_participant" validate:"required"`
+	Role          AttendeeRole   `json:"role" gorm:"type:varchar(16);not null;default:'required'" validate:"omitempty,oneof=required optional"`
+	CoOrganizer   bool           `json:"co_organizer" gorm:"not null;default:false"` // may manage the event like its organizer
+	Participant   *Participant   `json:"participant,omitempty" gorm:"foreignKey:ParticipantID"`
 	CreatedAt     time.Time      `json:"created_at"`
 	UpdatedAt     time.Time      `json:"updated_at"`
 	DeletedAt     gorm.DeletedAt `json:"-"`
 }
 
+// InvitationLink lets a guest answer an event without an account. The guest
+// is identified by email and linked to a participant when the link is first
+// used. The token for a link is shown once, when the link is created.
+type InvitationLink struct {
+	ID            uint         `json:"id" gorm:"primaryKey"`
+	EventID       uint         `json:"event_id" gorm:"not null;index"`
+	Email         string       `json:"email" gorm:"not null" validate:"required,email"`
+	Name          string       `json:"name" validate:"max=100"`
+	Role          AttendeeRole `json:"role" gorm:"type:varchar(16);not null;default:'required'" validate:"omitempty,oneof=required optional"`
+	ParticipantID *uint        `json:"participant_id,omitempty"` // set when the link is first used
+	ExpiresAt     time.Time    `json:"expires_at" gorm:"not null"`
+	UsedAt        *time.Time   `json:"used_at,omitempty"` // when the link was first used
+	RevokedAt     *time.Time   `json:"revoked_at,omitempty"`
+	TokenHash     string       `json:"-" gorm:"type:char(64);uniqueIndex"` // the link is looked up by; the token itself is not stored
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+
+	// Token is set only on the response that creates the link
+	Token string `json:"token,omitempty" gorm:"-"`
+}
+
+// Usable reports whether the link still admits its guest at the given time
+func (l InvitationLink) Usable(now time.Time) bool {
+	return l.RevokedAt == nil && now.Before(l.ExpiresAt)
+}
+
+// GuestInvitation is what a guest sees through an invitation link: the event
+// with its time slots, who they answer as and their answers so far
+type GuestInvitation struct {
+	Event          *Event         `json:"event"`
+	Participant    *Participant   `json:"participant"`
+	Availabilities []Availability `json:"availabilities"`
+}
+
+// GuestAvailabilityRequest is the payload for a guest answering through an
+// invitation link
+type GuestAvailabilityRequest struct {
+	Availabilities []SlotAvailability `json:"availabilities" validate:"required,min=1,dive"`
+}
+
+// AvailabilityStatus is a participant's answer for a time slot
+type AvailabilityStatus string
+
+const (
+	AvailabilityYes      AvailabilityStatus = "yes"
+	AvailabilityIfNeedBe AvailabilityStatus = "if_need_be"
+	AvailabilityNo       AvailabilityStatus = "no"
+)
+
+// Availability represents a participant's availability for a time slot.
+// A participant has at most one answer per time slot; resubmitting replaces it.
+type Availability struct {
+	ID            uint               `json:"id" gorm:"primaryKey"`
+	ParticipantID uint               `json:"participant_id" gorm:"not null;uniqueIndex:idx_availability_participant_slot"`
+	TimeSlotID    uint               `json:"time_slot_id" gorm:"not null;uniqueIndex:idx_availability_participant_slot"`
+	Status        AvailabilityStatus `json:"status" gorm:"type:varchar(16);not null;default:'yes'" validate:"omitempty,oneof=yes if_need_be no"`
+	Weight        *float64           `json:"weight,omitempty" validate:"omitempty,gt=0,lte=1"` // preference strength, 1 when omitted
+	IsAvailable   bool               `json:"is_available" gorm:"not null"`                     // kept in sync with Status
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt     `json:"-"`
+}
+
+// Normalize reconciles Status and IsAvailable. Clients that only send
+// is_available get a yes/no status; otherwise IsAvailable follows Status.
+func (a *Availability) Normalize() {
+	if a.Status == "" {
+		if a.IsAvailable {
+			a.Status = AvailabilityYes
+		} else {
+			a.Status = AvailabilityNo
+		}
+	}
+	a.IsAvailable = a.Status != AvailabilityNo
+}
+
+// EffectiveWeight returns the preference weight, defaulting to 1
+func (a Availability) EffectiveWeight() float64 {
+	if a.Weight == nil {
+		return 1
+	}
+	return *a.Weight
+}
+
+// AvailabilityHistory records an availability answer that was superseded by a resubmission
+type AvailabilityHistory struct {
+	ID             uint               `json:"id" gorm:"primaryKey"`
+	AvailabilityID uint               `json:"availability_id" gorm:"not null;index"`
+	ParticipantID  uint               `json:"participant_id" gorm:"not null;index"`
+	TimeSlotID     uint               `json:"time_slot_id" gorm:"not null;index"`
+	Status         AvailabilityStatus `json:"status" gorm:"type:varchar(16)"`
+	Weight         *float64           `json:"weight,omitempty"`
+	IsAvailable    bool               `json:"is_available" gorm:"not null"`
+	AnsweredAt     time.Time          `json:"answered_at"` // when the superseded answer was given
+	CreatedAt      time.Time          `json:"superseded_at"`
+}
+
 // TimeSlotRecommendation represents a recommended time slot with availability information
 type TimeSlotRecommendation struct {
-	TimeSlot         TimeSlot      `json:"time_slot"`
-	AvailableCount   int           `json:"available_count"`
-	UnavailableCount int           `json:"unavailable_count"`
-	AvailableUsers   []Participant `json:"available_users,omitempty"`
-	UnavailableUsers []Participant `json:"unavailable_users,omitempty"`
+	TimeSlot         TimeSlot            `json:"time_slot"`
+	Score            float64             `json:"score"`
+	AvailableCount   int                 `json:"available_count"`
+	IfNeedBeCount    int                 `json:"if_need_be_count"`
+	UnavailableCount int                 `json:"unavailable_count"`
+	AvailableUsers   []Participant       `json:"available_users,omitempty"`
+	IfNeedBeUsers    []Participant       `json:"if_need_be_users,omitempty"`
+	UnavailableUsers []Participant       `json:"unavailable_users,omitempty"`
+	PendingUsers     []Participant       `json:"pending_users,omitempty"` // invitees who have not answered
+	Breakdown        []ScoreContribution `json:"breakdown,omitempty"`
+
+	OutsideWorkingHours []WorkingHoursConflict `json:"outside_working_hours,omitempty"`
+}
+
+// WorkingHoursConflict flags an attendee for whom a time slot falls outside
+// their local working hours
+type WorkingHoursConflict struct {
+	ParticipantID uint      `json:"participant_id"`
+	Name          string    `json:"name"`
+	TimeZone      string    `json:"time_zone"`
+	StartTime     time.Time `json:"local_start_time"`
+	EndTime       time.Time `json:"local_end_time"`
+}
+
+// ScoreContribution explains how one participant's answer added to a slot's score.
+// Required attendees are a precondition for a slot rather than part of its score.
+type ScoreContribution struct {
+	ParticipantID uint               `json:"participant_id"`
+	Name          string             `json:"name"`
+	Role          AttendeeRole       `json:"role,omitempty"`
+	Status        AvailabilityStatus `json:"status"`
+	Weight        float64            `json:"weight"`
+	Points        float64            `json:"points"`
+}
+
+// NotificationStatus is the outcome of an attempt to deliver a notification
+type NotificationStatus string
+
+const (
+	NotificationPending NotificationStatus = "pending" // queued, waiting to be sent
+	NotificationSent    NotificationStatus = "sent"
+	NotificationFailed  NotificationStatus = "failed"
+)
+
+// Notification records one attempt to deliver a notification about an event
+// to a participant
+type Notification struct {
+	ID            uint               `json:"id" gorm:"primaryKey"`
+	EventID       uint               `json:"event_id" gorm:"not null;index"`
+	ParticipantID uint               `json:"participant_id" gorm:"not null;index"`
+	Kind          string             `json:"kind" gorm:"type:varchar(32);not null"`    // invitation, confirmation or reminder
+	Channel       string             `json:"channel" gorm:"type:varchar(16);not null"` // how it was delivered, e.g. email
+	Recipient     string             `json:"recipient" gorm:"not null"`
+	Subject       string             `json:"subject"`
+	Status        NotificationStatus `json:"status" gorm:"type:varchar(16);not null"`
+	Error         string             `json:"error,omitempty"`
+	NextAttemptAt *time.Time         `json:"-" gorm:"index"` // when a pending notification may be sent; claims push it back
+	CreatedAt     time.Time          `json:"created_at"`
+}
+
+// Webhook is an endpoint a participant registers to be told about changes to
+// the events they organize
+type Webhook struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	ParticipantID uint           `json:"participant_id" gorm:"not null;index"`
+	URL           string         `json:"url" gorm:"not null"`
+	Secret        string         `json:"-" gorm:"not null"`                            // signs payloads; never shown again
+	EventTypes    []string       `json:"event_types" gorm:"serializer:json;type:text"` // all types when empty
+	CreatedAt     time.Time      `json:"created_at"`
+	DeletedAt     gorm.DeletedAt `json:"-"`
+}
+
+// Subscribes reports whether the webhook wants payloads of the given type
+func (w Webhook) Subscribes(eventType string) bool {
+	if len(w.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range w.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookRequest is the payload for registering a webhook
+type WebhookRequest struct {
+	URL        string   `json:"url" validate:"required,url"`
+	Secret     string   `json:"secret" validate:"required,min=16"`
+	EventTypes []string `json:"event_types" validate:"omitempty,dive,oneof=event.created event.updated event.deleted event.closed event.confirmed event.cancelled availability.submitted"`
+}
+
+// OutboxEvent is a change to an event waiting to be handed to webhooks. It is
+// written in the same transaction as the change itself, so a change is never
+// stored without it.
+type OutboxEvent struct {
+	ID           uint   `gorm:"primaryKey"`
+	Type         string `gorm:"type:varchar(64);not null"`
+	OrganizerID  uint   `gorm:"not null"` // whose webhooks receive it
+	Payload      string `gorm:"type:text;not null"`
+	CreatedAt    time.Time
+	DispatchedAt *time.Time `gorm:"index"` // when deliveries were created for it
+}
+
+// WebhookDeliveryStatus is where a webhook delivery stands
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"   // waiting for its next attempt
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered" // the endpoint answered with 2xx
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"    // out of attempts
+)
+
+// WebhookDelivery is one payload on its way to one webhook, with the outcome
+// of its latest attempt
+type WebhookDelivery struct {
+	ID             uint                  `json:"id" gorm:"primaryKey"`
+	WebhookID      uint                  `json:"webhook_id" gorm:"not null;index"`
+	OutboxEventID  uint                  `json:"-" gorm:"not null"`
+	EventType      string                `json:"event_type" gorm:"type:varchar(64);not null"`
+	Payload        string                `json:"-" gorm:"type:text;not null"`
+	Status         WebhookDeliveryStatus `json:"status" gorm:"type:varchar(16);not null;index"`
+	Attempts       int                   `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at" gorm:"not null;index"`
+	LastAttemptAt  *time.Time            `json:"last_attempt_at,omitempty"`
+	ResponseStatus int                   `json:"response_status,omitempty"`
+	Error          string                `json:"error,omitempty"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+	Webhook        *Webhook              `json:"-" gorm:"foreignKey:WebhookID"`
+}
+
+// Job is a periodic background task. Every server runs the same jobs; the
+// one that leases a job's row when it is due runs it.
+type Job struct {
+	Name        string     `json:"name" gorm:"primaryKey;type:varchar(64)"`
+	NextRunAt   time.Time  `json:"next_run_at" gorm:"not null"`
+	LockedBy    string     `json:"locked_by,omitempty" gorm:"type:varchar(128)"` // the server running the job
+	LockedUntil *time.Time `json:"locked_until,omitempty"`                       // when its lease ends
+	LastRunAt   *time.Time `json:"last_run_at,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// BulkAvailabilityRequest is the payload for submitting a participant's answers
+// for several time slots of an event at once
+type BulkAvailabilityRequest struct {
+	ParticipantID  uint               `json:"participant_id" validate:"required"`
+	Availabilities []SlotAvailability `json:"availabilities" validate:"required,min=1,dive"`
+}
+
+// SlotAvailability is a single answer within a BulkAvailabilityRequest
+type SlotAvailability struct {
+	TimeSlotID  uint               `json:"time_slot_id" validate:"required"`
+	Status      AvailabilityStatus `json:"status" validate:"omitempty,oneof=yes if_need_be no"`
+	Weight      *float64           `json:"weight,omitempty" validate:"omitempty,gt=0,lte=1"`
+	IsAvailable bool               `json:"is_available"`
+}
+
+// TimeSlotGenerationRequest describes the candidate time slots to generate for
+// an event: every step within the date range at which a slot of the event's
+// duration fits inside all of the working-hours windows
+type TimeSlotGenerationRequest struct {
+	StartDate   string          `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate     string          `json:"end_date" validate:"required,datetime=2006-01-02"`
+	TimeZone    string          `json:"time_zone" validate:"omitempty,timezone"` // for the date range and excluded days; defaults to the first window's
+	Windows     []WorkingWindow `json:"windows" validate:"required,min=1,max=10,dive"`
+	StepMinutes int             `json:"step_minutes" validate:"omitempty,min=5"` // defaults to the event's duration
+	ExcludeDays []string        `json:"exclude_days"`                            // weekday names or YYYY-MM-DD dates
+}
+
+// WorkingWindow is a daily working-hours window in a time zone
+type WorkingWindow struct {
+	TimeZone string `json:"time_zone" validate:"required,timezone"`
+	Start    string `json:"start" validate:"required,datetime=15:04"`
+	End      string `json:"end" validate:"required,datetime=15:04"`
+}
+
+// TimeSlotGenerationResult reports the time slots created by a generation request
+type TimeSlotGenerationResult struct {
+	Created []TimeSlot `json:"created"`
+	Skipped int        `json:"skipped"` // candidates overlapping existing slots or outside the scheduling window
+}
+
+// BusyPeriod is a span of time in which a participant is busy
+type BusyPeriod struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// AvailabilityImport reports the answers derived from an imported calendar:
+// "no" for time slots overlapping a busy period and "yes" for the others
+type AvailabilityImport struct {
+	Availabilities []Availability `json:"availabilities"`
+	BusyPeriods    []BusyPeriod   `json:"busy_periods"`
+	Saved          bool           `json:"saved"` // false for a dry run
+}
+
+// ConfirmEventRequest is the payload for confirming an event's time slot
+type ConfirmEventRequest struct {
+	TimeSlotID uint `json:"time_slot_id" validate:"required"`
 }
@@ -0,0 +1,88 @@
+// Package notify tells participants about the events they take part in
+package notify
+
+import (
+	"context"
+	"sync"
+
+	"github.com/tusharsingune/meeting-scheduler/internal/logger"
+	"go.uber.org/zap"
+)
+
+// Message is a rendered notification for one recipient
+type Message struct {
+	To      string // email address
+	ToName  string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Notifier delivers messages over one channel
+type Notifier interface {
+	// Channel names the channel, as recorded on deliveries
+	Channel() string
+	Send(context.Context, Message) error
+}
+
+// Recorder is a Notifier that keeps messages in memory instead of delivering
+// them, for tests. It is safe for concurrent use.
+type Recorder struct {
+	mu       sync.Mutex
+	messages []Message
+	err      error
+}
+
+// NewRecorder creates an empty Recorder
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Channel implements Notifier
+func (r *Recorder) Channel() string {
+	return "memory"
+}
+
+// Send records a message, or fails with the error set by FailWith
+func (r *Recorder) Send(ctx context.Context, msg Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	r.messages = append(r.messages, msg)
+	return nil
+}
+
+// FailWith makes every following Send fail with err, or succeed again when err is nil
+func (r *Recorder) FailWith(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.err = err
+}
+
+// Messages returns the messages recorded so far
+func (r *Recorder) Messages() []Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Message(nil), r.messages...)
+}
+
+// LogNotifier is a Notifier that only logs messages, for servers without a
+// mail server configured
+type LogNotifier struct {
+	Log *zap.Logger
+}
+
+// Channel implements Notifier
+func (n LogNotifier) Channel() string {
+	return "log"
+}
+
+// Send logs who the message is for and what it is about
+func (n LogNotifier) Send(ctx context.Context, msg Message) error {
+	logger.FromContext(ctx, n.Log).Info("Notification not sent, no mail server configured",
+		zap.String("to", msg.To),
+		zap.String("subject", msg.Subject))
+	return nil
+}
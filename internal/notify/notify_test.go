@@ -0,0 +1,245 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"mime"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"go.uber.org/zap"
+)
+
+// memoryStore records deliveries in a slice and serves queued ones from it
+// to a Sender
+type memoryStore struct {
+	notifications []models.Notification
+	event         *models.Event
+	err           error
+}
+
+func (s *memoryStore) CreateNotification(ctx context.Context, n *models.Notification) error {
+	if s.err != nil {
+		return s.err
+	}
+	n.ID = uint(len(s.notifications) + 1)
+	s.notifications = append(s.notifications, *n)
+	return nil
+}
+
+func (s *memoryStore) ClaimNotifications(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.Notification, error) {
+	var claimed []models.Notification
+	for i, n := range s.notifications {
+		if n.Status != models.NotificationPending || n.NextAttemptAt.After(now) || len(claimed) == limit {
+			continue
+		}
+		next := now.Add(lease)
+		s.notifications[i].NextAttemptAt = &next
+		claimed = append(claimed, n)
+	}
+	return claimed, nil
+}
+
+func (s *memoryStore) UpdateNotification(ctx context.Context, n *models.Notification) error {
+	s.notifications[n.ID-1] = *n
+	return nil
+}
+
+func (s *memoryStore) GetEvent(ctx context.Context, id uint) (*models.Event, error) {
+	if s.event == nil || s.event.ID != id {
+		return nil, errors.New("event not found")
+	}
+	return s.event, nil
+}
+
+func (s *memoryStore) GetParticipant(ctx context.Context, id uint) (*models.Participant, error) {
+	for _, invitation := range s.event.Invitations {
+		if invitation.ParticipantID == id {
+			return invitation.Participant, nil
+		}
+	}
+	return nil, errors.New("participant not found")
+}
+
+func testEvent() *models.Event {
+	start := time.Date(2030, 6, 3, 14, 0, 0, 0, time.UTC)
+	deadline := time.Date(2030, 6, 1, 17, 0, 0, 0, time.UTC)
+	return &models.Event{
+		ID:           1,
+		Title:        "Planning <Q3>",
+		Duration:     60,
+		PollDeadline: &deadline,
+		TimeSlots:    []models.TimeSlot{{ID: 5, EventID: 1, StartTime: start, EndTime: start.Add(time.Hour)}},
+		Invitations: []models.EventInvitation{
+			{ParticipantID: 2, Participant: &models.Participant{ID: 2, Name: "Grace", Email: "grace@example.com", TimeZone: "Europe/Berlin"}},
+			{ParticipantID: 3, Participant: &models.Participant{ID: 3, Name: "Alan", Email: "alan@example.com"}},
+		},
+	}
+}
+
+func TestDefaultTemplates(t *testing.T) {
+	templates := DefaultTemplates()
+	event := testEvent()
+	grace := event.Invitations[0].Participant
+
+	msg, err := templates.Render(KindInvitation, Data{Event: event, Participant: grace})
+	require.NoError(t, err)
+	assert.Equal(t, "grace@example.com", msg.To)
+	assert.Equal(t, "Invitation: Planning <Q3>", msg.Subject)
+	assert.Contains(t, msg.Text, "Mon 3 Jun 2030, 16:00 CEST", "times are shown in the recipient's zone")
+	assert.Contains(t, msg.Text, "Please answer by Sat 1 Jun 2030, 19:00 CEST")
+	assert.Contains(t, msg.HTML, "Planning &lt;Q3&gt;", "HTML is escaped")
+
+	event.Confirm(event.TimeSlots[0], time.Now())
+	msg, err = templates.Render(KindConfirmation, Data{Event: event, Participant: event.Invitations[1].Participant, TimeSlot: event.ConfirmedTimeSlot})
+	require.NoError(t, err)
+	assert.Equal(t, "Confirmed: Planning <Q3>", msg.Subject)
+	assert.Contains(t, msg.Text, "Mon 3 Jun 2030, 14:00 UTC until Mon 3 Jun 2030, 15:00 UTC")
+
+	msg, err = templates.Render(KindReminder, Data{Event: event, Participant: grace})
+	require.NoError(t, err)
+	assert.Equal(t, "Reminder: Planning <Q3> is waiting for your answer", msg.Subject)
+}
+
+func TestLoadTemplatesOverridesSingleFiles(t *testing.T) {
+	dir := t.TempDir()
+	override := `{{define "subject"}}Please vote: {{.Event.Title}}{{end}}Vote now, {{.Participant.Name}}!`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "reminder.txt.tmpl"), []byte(override), 0o644))
+
+	templates, err := LoadTemplates(dir)
+	require.NoError(t, err)
+
+	event := testEvent()
+	msg, err := templates.Render(KindReminder, Data{Event: event, Participant: event.Invitations[0].Participant})
+	require.NoError(t, err)
+	assert.Equal(t, "Please vote: Planning <Q3>", msg.Subject)
+	assert.Equal(t, "Vote now, Grace!\n", msg.Text)
+	assert.Contains(t, msg.HTML, "You have not yet said", "the built-in HTML template is kept")
+
+	_, err = LoadTemplates(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestLoadTemplatesRequiresSubject(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "invitation.txt.tmpl"), []byte("Hello"), 0o644))
+
+	_, err := LoadTemplates(dir)
+	assert.ErrorContains(t, err, "subject")
+}
+
+func TestSenderRecordsDeliveries(t *testing.T) {
+	ctx := context.Background()
+	recorder := NewRecorder()
+	event := testEvent()
+	store := &memoryStore{event: event}
+	service := NewService(recorder, DefaultTemplates(), store, zap.NewNop())
+	sender := NewSender(service, store, zap.NewNop())
+
+	require.NoError(t, service.QueueInvited(ctx, store, event, event.Invitations[0].Participant))
+	require.Len(t, store.notifications, 1)
+	assert.Equal(t, models.NotificationPending, store.notifications[0].Status)
+	assert.Empty(t, recorder.Messages(), "queueing sends nothing")
+
+	sent, err := sender.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	recorder.FailWith(errors.New("mailbox unavailable"))
+	require.NoError(t, service.QueueInvited(ctx, store, event, event.Invitations[1].Participant))
+	_, err = sender.RunOnce(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, recorder.Messages(), 1)
+	assert.Equal(t, "grace@example.com", recorder.Messages()[0].To)
+
+	require.Len(t, store.notifications, 2)
+	assert.Equal(t, models.NotificationSent, store.notifications[0].Status)
+	assert.Equal(t, "invitation", store.notifications[0].Kind)
+	assert.Equal(t, "memory", store.notifications[0].Channel)
+	assert.Equal(t, "Invitation: Planning <Q3>", store.notifications[0].Subject)
+	assert.Nil(t, store.notifications[0].NextAttemptAt)
+	assert.Equal(t, models.NotificationFailed, store.notifications[1].Status)
+	assert.Equal(t, "mailbox unavailable", store.notifications[1].Error)
+	assert.Equal(t, uint(3), store.notifications[1].ParticipantID)
+
+	sent, err = sender.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, sent, "each notification is sent once")
+}
+
+func TestSenderSendsConfirmations(t *testing.T) {
+	ctx := context.Background()
+	recorder := NewRecorder()
+	event := testEvent()
+	event.Confirm(event.TimeSlots[0], time.Now())
+	store := &memoryStore{event: event}
+	service := NewService(recorder, DefaultTemplates(), store, zap.NewNop())
+
+	require.NoError(t, service.QueueConfirmed(ctx, store, event))
+	_, err := NewSender(service, store, zap.NewNop()).RunOnce(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, recorder.Messages(), 2)
+	for _, msg := range recorder.Messages() {
+		assert.True(t, strings.HasPrefix(msg.Subject, "Confirmed: "))
+		assert.Contains(t, msg.Text, "Mon 3 Jun 2030")
+	}
+}
+
+func TestSenderFailsNotificationsOfDeletedEvents(t *testing.T) {
+	ctx := context.Background()
+	recorder := NewRecorder()
+	event := testEvent()
+	store := &memoryStore{}
+	service := NewService(recorder, DefaultTemplates(), store, zap.NewNop())
+
+	require.NoError(t, service.QueueInvited(ctx, store, event, event.Invitations[0].Participant))
+	_, err := NewSender(service, store, zap.NewNop()).RunOnce(ctx)
+	require.NoError(t, err)
+
+	assert.Empty(t, recorder.Messages())
+	assert.Equal(t, models.NotificationFailed, store.notifications[0].Status)
+	assert.Equal(t, "event not found", store.notifications[0].Error)
+}
+
+func TestRemindReturnsStoreErrors(t *testing.T) {
+	service := NewService(NewRecorder(), DefaultTemplates(), &memoryStore{err: errors.New("database down")}, zap.NewNop())
+	event := testEvent()
+
+	_, err := service.Remind(context.Background(), event, Unanswered(event, nil))
+	assert.Error(t, err)
+}
+
+func TestUnanswered(t *testing.T) {
+	event := testEvent()
+
+	participants := Unanswered(event, []models.Availability{{ParticipantID: 2, TimeSlotID: 5}})
+
+	require.Len(t, participants, 1)
+	assert.Equal(t, uint(3), participants[0].ID)
+}
+
+func TestBuildEmail(t *testing.T) {
+	from := mail.Address{Name: "Meeting Scheduler", Address: "scheduler@example.com"}
+	msg := Message{To: "grace@example.com", ToName: "Grace", Subject: "Einladung: Größe", Text: "Hallo", HTML: "<p>Hallo</p>"}
+
+	body, err := buildEmail(from, msg, time.Date(2030, 6, 3, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(body)))
+	require.NoError(t, err)
+	assert.Equal(t, `"Grace" <grace@example.com>`, parsed.Header.Get("To"))
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Einladung: Größe", subject)
+	assert.Contains(t, parsed.Header.Get("Content-Type"), "multipart/alternative")
+	assert.Contains(t, string(body), "Content-Type: text/plain; charset=utf-8")
+	assert.Contains(t, string(body), "Content-Type: text/html; charset=utf-8")
+}
@@ -0,0 +1,98 @@
+package notify
+
+import (
+	"context"
+	"time"
+
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"go.uber.org/zap"
+)
+
+// Outbox is the part of the repository a Sender works with
+type Outbox interface {
+	ClaimNotifications(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.Notification, error)
+	UpdateNotification(context.Context, *models.Notification) error
+	GetEvent(context.Context, uint) (*models.Event, error)
+	GetParticipant(context.Context, uint) (*models.Participant, error)
+}
+
+// Sender delivers queued notifications in the background. Each is rendered
+// from the event and participant as they are when it is sent.
+type Sender struct {
+	Service   *Service
+	Store     Outbox
+	Log       *zap.Logger
+	Interval  time.Duration // how often to look for queued notifications
+	BatchSize int           // notifications sent per round
+	Lease     time.Duration // how long a claimed notification is hidden from other servers
+	Now       func() time.Time
+}
+
+// NewSender creates a Sender that looks for queued notifications every five
+// seconds
+func NewSender(service *Service, store Outbox, log *zap.Logger) *Sender {
+	return &Sender{
+		Service:   service,
+		Store:     store,
+		Log:       log,
+		Interval:  5 * time.Second,
+		BatchSize: 20,
+		Lease:     time.Minute,
+		Now:       time.Now,
+	}
+}
+
+// Run sends queued notifications until ctx is done
+func (s *Sender) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.RunOnce(ctx); err != nil {
+			s.Log.Error("Failed to send queued notifications", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce makes one attempt at every queued notification that is due and
+// returns how many it attempted
+func (s *Sender) RunOnce(ctx context.Context) (int, error) {
+	notifications, err := s.Store.ClaimNotifications(ctx, s.Now(), s.Lease, s.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	for i := range notifications {
+		s.send(ctx, &notifications[i])
+		if err := s.Store.UpdateNotification(ctx, &notifications[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(notifications), nil
+}
+
+// send delivers a claimed notification, failing it when its event or
+// participant is gone
+func (s *Sender) send(ctx context.Context, notification *models.Notification) {
+	event, err := s.Store.GetEvent(ctx, notification.EventID)
+	var participant *models.Participant
+	if err == nil {
+		participant, err = s.Store.GetParticipant(ctx, notification.ParticipantID)
+	}
+	if err != nil {
+		notification.Status = models.NotificationFailed
+		notification.Error = err.Error()
+		notification.NextAttemptAt = nil
+		s.Log.Warn("Dropped queued notification", zap.Error(err), zap.Uint("notification_id", notification.ID))
+		return
+	}
+
+	data := Data{Event: event, Participant: participant}
+	if Kind(notification.Kind) == KindConfirmation {
+		data.TimeSlot = event.ConfirmedTimeSlot
+	}
+	s.Service.deliver(ctx, notification, data)
+}
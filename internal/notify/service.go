@@ -0,0 +1,203 @@
+package notify
+
+import (
+	"context"
+	"time"
+
+	"github.com/tusharsingune/meeting-scheduler/internal/config"
+	"github.com/tusharsingune/meeting-scheduler/internal/logger"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"go.uber.org/zap"
+)
+
+// Store records notifications, either queued or as delivery attempts
+type Store interface {
+	CreateNotification(context.Context, *models.Notification) error
+}
+
+// Service renders notifications from templates, delivers them and records
+// every delivery attempt. Notifications about a change a request makes are
+// queued with the change and sent later by a Sender.
+type Service struct {
+	notifier  Notifier
+	templates *Templates
+	store     Store
+	log       *zap.Logger
+}
+
+// NewService creates a Service
+func NewService(notifier Notifier, templates *Templates, store Store, log *zap.Logger) *Service {
+	return &Service{notifier: notifier, templates: templates, store: store, log: log}
+}
+
+// NewServiceFromConfig creates a Service that emails through the configured
+// SMTP server, or only logs notifications when there is none. Invalid
+// templates or SMTP settings are logged and replaced by the defaults.
+func NewServiceFromConfig(cfg config.NotifyConfig, store Store, log *zap.Logger) *Service {
+	templates, err := LoadTemplates(cfg.TemplateDir)
+	if err != nil {
+		log.Warn("Invalid notification templates, using the built-in ones", zap.Error(err))
+		templates = DefaultTemplates()
+	}
+
+	var notifier Notifier = LogNotifier{Log: log}
+	if cfg.SMTPHost != "" {
+		smtpNotifier, err := NewSMTPNotifier(cfg)
+		if err != nil {
+			log.Warn("Invalid SMTP configuration, notifications will only be logged", zap.Error(err))
+		} else {
+			notifier = smtpNotifier
+		}
+	}
+
+	return NewService(notifier, templates, store, log)
+}
+
+// Notify renders a kind of notification for a participant, delivers it and
+// records the attempt. A failed delivery is recorded and logged rather than
+// returned; the error is for attempts that could not be recorded.
+func (s *Service) Notify(ctx context.Context, kind Kind, data Data) (*models.Notification, error) {
+	notification := &models.Notification{
+		EventID:       data.Event.ID,
+		ParticipantID: data.Participant.ID,
+		Kind:          string(kind),
+		Channel:       s.notifier.Channel(),
+	}
+	s.deliver(ctx, notification, data)
+
+	if err := s.store.CreateNotification(ctx, notification); err != nil {
+		logger.FromContext(ctx, s.log).Error("Failed to record notification", zap.Error(err))
+		return nil, err
+	}
+	return notification, nil
+}
+
+// deliver renders a notification and sends it, setting its recipient,
+// subject and status from the outcome
+func (s *Service) deliver(ctx context.Context, notification *models.Notification, data Data) {
+	notification.Recipient = data.Participant.Email
+	notification.Status = models.NotificationSent
+	notification.NextAttemptAt = nil
+
+	msg, err := s.templates.Render(Kind(notification.Kind), data)
+	if err == nil {
+		notification.Subject = msg.Subject
+		err = s.notifier.Send(ctx, msg)
+	}
+	if err != nil {
+		notification.Status = models.NotificationFailed
+		notification.Error = err.Error()
+		logger.FromContext(ctx, s.log).Error("Failed to send notification",
+			zap.Error(err),
+			zap.String("kind", notification.Kind),
+			zap.Uint("event_id", notification.EventID),
+			zap.Uint("participant_id", notification.ParticipantID))
+	}
+}
+
+// Queue records a notification for a participant as pending, for a Sender to
+// deliver. Queue through the repository of the transaction that stores the
+// change the notification is about, so it is sent once the change commits and
+// the request does not wait for the mail server.
+func (s *Service) Queue(ctx context.Context, store Store, kind Kind, eventID uint, participant *models.Participant) error {
+	return store.CreateNotification(ctx, s.pending(kind, eventID, participant))
+}
+
+// pending returns a notification for a participant that is due to be sent
+func (s *Service) pending(kind Kind, eventID uint, participant *models.Participant) *models.Notification {
+	now := time.Now()
+	return &models.Notification{
+		EventID:       eventID,
+		ParticipantID: participant.ID,
+		Kind:          string(kind),
+		Channel:       s.notifier.Channel(),
+		Recipient:     participant.Email,
+		Status:        models.NotificationPending,
+		NextAttemptAt: &now,
+	}
+}
+
+// QueueInvited queues telling a participant they were invited to an event
+func (s *Service) QueueInvited(ctx context.Context, store Store, event *models.Event, participant *models.Participant) error {
+	return s.Queue(ctx, store, KindInvitation, event.ID, participant)
+}
+
+// QueueConfirmed queues telling the invitees of an event which time was
+// confirmed. The event's invitations' participants must be loaded.
+func (s *Service) QueueConfirmed(ctx context.Context, store Store, event *models.Event) error {
+	for _, invitation := range event.Invitations {
+		if invitation.Participant == nil {
+			continue
+		}
+		if err := s.Queue(ctx, store, KindConfirmation, event.ID, invitation.Participant); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// QueueReminders queues reminding participants to answer an event's poll and
+// returns the queued notifications
+func (s *Service) QueueReminders(ctx context.Context, store Store, event *models.Event, participants []models.Participant) ([]models.Notification, error) {
+	notifications := make([]models.Notification, 0, len(participants))
+	for i := range participants {
+		notification := s.pending(KindReminder, event.ID, &participants[i])
+		if err := store.CreateNotification(ctx, notification); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, *notification)
+	}
+	return notifications, nil
+}
+
+// Remind reminds participants to answer an event's poll and returns the
+// recorded delivery attempts
+func (s *Service) Remind(ctx context.Context, event *models.Event, participants []models.Participant) ([]models.Notification, error) {
+	notifications := make([]models.Notification, 0, len(participants))
+	for i := range participants {
+		notification, err := s.Notify(ctx, KindReminder, Data{Event: event, Participant: &participants[i]})
+		if err != nil {
+			return notifications, err
+		}
+		notifications = append(notifications, *notification)
+	}
+	return notifications, nil
+}
+
+// Unanswered returns the invitees of an event that have not answered any of
+// its time slots. The event's invitations' participants must be loaded.
+func Unanswered(event *models.Event, availabilities []models.Availability) []models.Participant {
+	answered := make(map[uint]bool, len(availabilities))
+	for _, availability := range availabilities {
+		answered[availability.ParticipantID] = true
+	}
+
+	var participants []models.Participant
+	for _, invitation := range event.Invitations {
+		if invitation.Participant != nil && !answered[invitation.ParticipantID] {
+			participants = append(participants, *invitation.Participant)
+		}
+	}
+	return participants
+}
+
+// NotRemindedSince returns the participants without a reminder queued or sent
+// since the given time, among notifications about the same event. Reminders
+// that failed do not count.
+func NotRemindedSince(participants []models.Participant, notifications []models.Notification, since time.Time) []models.Participant {
+	reminded := make(map[uint]bool)
+	for _, notification := range notifications {
+		if notification.Kind == string(KindReminder) && notification.Status != models.NotificationFailed &&
+			!notification.CreatedAt.Before(since) {
+			reminded[notification.ParticipantID] = true
+		}
+	}
+
+	var due []models.Participant
+	for _, participant := range participants {
+		if !reminded[participant.ID] {
+			due = append(due, participant)
+		}
+	}
+	return due
+}
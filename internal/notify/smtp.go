@@ -0,0 +1,148 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+
+	"github.com/tusharsingune/meeting-scheduler/internal/config"
+)
+
+// defaultSMTPTimeout bounds a whole SMTP conversation
+const defaultSMTPTimeout = 10 * time.Second
+
+// SMTPNotifier delivers messages as email through an SMTP server. It upgrades
+// the connection with STARTTLS whenever the server offers it.
+type SMTPNotifier struct {
+	Addr    string    // host:port of the server
+	Auth    smtp.Auth // nil to send without authenticating
+	From    mail.Address
+	Timeout time.Duration
+}
+
+// NewSMTPNotifier creates an SMTPNotifier from the configuration
+func NewSMTPNotifier(cfg config.NotifyConfig) (*SMTPNotifier, error) {
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address %q: %w", cfg.From, err)
+	}
+
+	n := &SMTPNotifier{
+		Addr:    net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort)),
+		From:    *from,
+		Timeout: defaultSMTPTimeout,
+	}
+	if cfg.SMTPUsername != "" {
+		n.Auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+	return n, nil
+}
+
+// Channel implements Notifier
+func (n *SMTPNotifier) Channel() string {
+	return "email"
+}
+
+// Send delivers a message as a multipart email with text and HTML parts
+func (n *SMTPNotifier) Send(ctx context.Context, msg Message) error {
+	body, err := buildEmail(n.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	dialer := net.Dialer{Timeout: n.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", n.Addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(n.Timeout)); err != nil {
+		return err
+	}
+
+	host, _, _ := net.SplitHostPort(n.Addr)
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if n.Auth != nil {
+		if err := client.Auth(n.Auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(n.From.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// buildEmail renders a message as a MIME email with a plain text and an HTML
+// alternative
+func buildEmail(from mail.Address, msg Message, date time.Time) ([]byte, error) {
+	var parts bytes.Buffer
+	mw := multipart.NewWriter(&parts)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qw := quotedprintable.NewWriter(pw)
+		if _, err := qw.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	to := mail.Address{Name: msg.ToName, Address: msg.To}
+	var email bytes.Buffer
+	fmt.Fprintf(&email, "From: %s\r\n", from.String())
+	fmt.Fprintf(&email, "To: %s\r\n", to.String())
+	fmt.Fprintf(&email, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&email, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	fmt.Fprintf(&email, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&email, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", mw.Boundary())
+	email.Write(parts.Bytes())
+	return email.Bytes(), nil
+}
@@ -0,0 +1,149 @@
+package notify
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+)
+
+// Kind is what a notification is about
+type Kind string
+
+const (
+	KindInvitation   Kind = "invitation"   // a participant was invited to an event
+	KindConfirmation Kind = "confirmation" // an event's time was confirmed
+	KindReminder     Kind = "reminder"     // a participant has yet to answer an event's poll
+)
+
+// kinds lists every kind of notification there is a template for
+var kinds = []Kind{KindInvitation, KindConfirmation, KindReminder}
+
+// Data is what templates are rendered with
+type Data struct {
+	Event       *models.Event
+	Participant *models.Participant // the recipient
+	TimeSlot    *models.TimeSlot    // the confirmed time slot, for confirmations
+}
+
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+// Templates renders each kind of notification. For every kind there is a
+// text template, <kind>.txt.tmpl, which also defines the "subject" template,
+// and an HTML template, <kind>.html.tmpl.
+type Templates struct {
+	text map[Kind]*texttemplate.Template
+	html map[Kind]*htmltemplate.Template
+}
+
+// funcs are the functions available to templates
+var funcs = map[string]interface{}{
+	// when formats a time in an IANA time zone, UTC when the zone is unknown
+	"when": func(t time.Time, zone string) string {
+		loc, err := time.LoadLocation(zone)
+		if err != nil || zone == "" {
+			loc = time.UTC
+		}
+		return t.In(loc).Format("Mon 2 Jan 2006, 15:04 MST")
+	},
+}
+
+// DefaultTemplates returns the built-in templates
+func DefaultTemplates() *Templates {
+	templates, err := parseTemplates(builtinTemplates)
+	if err != nil {
+		panic(err)
+	}
+	return templates
+}
+
+// LoadTemplates parses the templates in dir. Any template dir lacks is taken
+// from the built-in ones, so a single message can be changed on its own. An
+// empty dir gives the built-in templates.
+func LoadTemplates(dir string) (*Templates, error) {
+	if dir == "" {
+		return DefaultTemplates(), nil
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+	return parseTemplates(overlayFS{os.DirFS(dir), builtinTemplates})
+}
+
+func parseTemplates(fsys fs.FS) (*Templates, error) {
+	t := &Templates{
+		text: make(map[Kind]*texttemplate.Template),
+		html: make(map[Kind]*htmltemplate.Template),
+	}
+	for _, kind := range kinds {
+		text, err := texttemplate.New(string(kind)+".txt.tmpl").Funcs(funcs).ParseFS(fsys, templatePath(kind, "txt"))
+		if err != nil {
+			return nil, err
+		}
+		if text.Lookup("subject") == nil {
+			return nil, errors.New(templatePath(kind, "txt") + ` does not define a "subject" template`)
+		}
+		html, err := htmltemplate.New(string(kind)+".html.tmpl").Funcs(funcs).ParseFS(fsys, templatePath(kind, "html"))
+		if err != nil {
+			return nil, err
+		}
+		t.text[kind] = text
+		t.html[kind] = html
+	}
+	return t, nil
+}
+
+// templatePath is where the template of a kind and format is found
+func templatePath(kind Kind, format string) string {
+	return "templates/" + string(kind) + "." + format + ".tmpl"
+}
+
+// Render renders a kind of notification for its recipient
+func (t *Templates) Render(kind Kind, data Data) (Message, error) {
+	text, ok := t.text[kind]
+	if !ok {
+		return Message{}, errors.New("no template for notification kind " + string(kind))
+	}
+
+	var subject, body, html bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, err
+	}
+	if err := text.Execute(&body, data); err != nil {
+		return Message{}, err
+	}
+	if err := t.html[kind].Execute(&html, data); err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		To:      data.Participant.Email,
+		ToName:  data.Participant.Name,
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		Text:    strings.TrimSpace(body.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
+
+// overlayFS serves files from the first file system that has them. Templates
+// in an override directory sit at its top level, built-in ones under
+// templates/.
+type overlayFS struct {
+	override fs.FS
+	base     fs.FS
+}
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	if f, err := o.override.Open(strings.TrimPrefix(name, "templates/")); err == nil {
+		return f, nil
+	}
+	return o.base.Open(name)
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/tusharsingune/meeting-scheduler/internal/logger"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"go.uber.org/zap"
+)
+
+// Cache stores encoded values by key for a limited time
+type Cache interface {
+	// Get returns the value stored under key, and false when there is none
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	// Incr increments the integer stored under key, which starts at zero and
+	// does not expire, and returns the result
+	Incr(ctx context.Context, key string) (int64, error)
+}
+
+// CachedRepository is a Repository that keeps the time slot recommendations
+// of events in a cache. The recommendations of an event are dropped from the
+// cache whenever its time slots, invitations or answers change through it.
+//
+// Recommendations are cached under the event's generation, a counter bumped
+// whenever they are dropped. Recommendations loaded before a change but
+// cached after it go under the old generation, so they are never read.
+//
+// A failing cache is logged and otherwise ignored, so the repository keeps
+// working from the database alone.
+type CachedRepository struct {
+	Repository
+	cache Cache
+	ttl   time.Duration
+
+	// pending collects the events changed within a transaction, whose cached
+	// recommendations are dropped once it commits. It is nil outside one.
+	pending *[]uint
+}
+
+// NewCachedRepository returns repo with recommendations cached for ttl
+func NewCachedRepository(repo Repository, cache Cache, ttl time.Duration) *CachedRepository {
+	return &CachedRepository{Repository: repo, cache: cache, ttl: ttl}
+}
+
+// recommendationsKey is the cache key of an event's recommendations in a
+// generation
+func recommendationsKey(eventID uint, generation int64) string {
+	return fmt.Sprintf("recommendations:event:%d:%d", eventID, generation)
+}
+
+// generationKey is the cache key of the generation of an event's
+// recommendations
+func generationKey(eventID uint) string {
+	return fmt.Sprintf("recommendations:event:%d:generation", eventID)
+}
+
+// generation returns the current generation of an event's recommendations
+func (r *CachedRepository) generation(ctx context.Context, eventID uint) (int64, error) {
+	value, ok, err := r.cache.Get(ctx, generationKey(eventID))
+	if err != nil || !ok {
+		return 0, err
+	}
+	return strconv.ParseInt(string(value), 10, 64)
+}
+
+// Transaction runs fn with a cached repository bound to a transaction. The
+// changes it makes are only dropped from the cache after they are committed,
+// so a concurrent request cannot cache the recommendations from before them.
+func (r *CachedRepository) Transaction(ctx context.Context, fn func(Repository) error) error {
+	var changed []uint
+	err := r.Repository.Transaction(ctx, func(tx Repository) error {
+		changed = changed[:0]
+		return fn(&CachedRepository{Repository: tx, cache: r.cache, ttl: r.ttl, pending: &changed})
+	})
+	if err != nil {
+		return err
+	}
+	r.invalidate(ctx, changed...)
+	return nil
+}
+
+// GetTimeSlotRecommendations returns the cached recommendations of an event,
+// loading and caching them on a miss. Within a transaction the cache is
+// bypassed, as it may hold recommendations from before the transaction's
+// changes.
+func (r *CachedRepository) GetTimeSlotRecommendations(ctx context.Context, eventID uint) ([]models.TimeSlotRecommendation, error) {
+	if r.pending != nil {
+		return r.Repository.GetTimeSlotRecommendations(ctx, eventID)
+	}
+
+	// The generation is read before the recommendations are loaded, so a
+	// change committed meanwhile leaves what is cached here unread
+	generation, err := r.generation(ctx, eventID)
+	if err != nil {
+		logger.Ctx(ctx).Warn("Failed to read recommendations generation from cache", zap.Uint("event_id", eventID), zap.Error(err))
+		return r.Repository.GetTimeSlotRecommendations(ctx, eventID)
+	}
+	key := recommendationsKey(eventID, generation)
+	cached, ok, err := r.cache.Get(ctx, key)
+	if err != nil {
+		logger.Ctx(ctx).Warn("Failed to read recommendations from cache", zap.String("key", key), zap.Error(err))
+	}
+	if ok {
+		var recommendations []models.TimeSlotRecommendation
+		if err := json.Unmarshal(cached, &recommendations); err == nil {
+			return recommendations, nil
+		}
+		logger.Ctx(ctx).Warn("Discarding unreadable cached recommendations", zap.String("key", key), zap.Error(err))
+	}
+
+	recommendations, err := r.Repository.GetTimeSlotRecommendations(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(recommendations)
+	if err == nil {
+		err = r.cache.Set(ctx, key, encoded, r.ttl)
+	}
+	if err != nil {
+		logger.Ctx(ctx).Warn("Failed to cache recommendations", zap.String("key", key), zap.Error(err))
+	}
+	return recommendations, nil
+}
+
+// changed drops the cached recommendations of events after a successful
+// change, or once the transaction making it commits
+func (r *CachedRepository) changed(ctx context.Context, err error, eventIDs ...uint) error {
+	if err != nil {
+		return err
+	}
+	if r.pending != nil {
+		*r.pending = append(*r.pending, eventIDs...)
+		return nil
+	}
+	r.invalidate(ctx, eventIDs...)
+	return nil
+}
+
+// invalidate drops the cached recommendations of events by bumping their
+// generations, then deletes the recommendations of the previous generations
+// to free their space early
+func (r *CachedRepository) invalidate(ctx context.Context, eventIDs ...uint) {
+	var stale []string
+	for _, id := range eventIDs {
+		generation, err := r.cache.Incr(ctx, generationKey(id))
+		if err != nil {
+			logger.Ctx(ctx).Error("Failed to invalidate cached recommendations", zap.Uint("event_id", id), zap.Error(err))
+			continue
+		}
+		stale = append(stale, recommendationsKey(id, generation-1))
+	}
+	if len(stale) == 0 {
+		return
+	}
+	if err := r.cache.Delete(ctx, stale...); err != nil {
+		logger.Ctx(ctx).Warn("Failed to delete stale cached recommendations", zap.Strings("keys", stale), zap.Error(err))
+	}
+}
+
+// DeleteEvent deletes an event and its cached recommendations
+func (r *CachedRepository) DeleteEvent(ctx context.Context, id uint) error {
+	return r.changed(ctx, r.Repository.DeleteEvent(ctx, id), id)
+}
+
+// CreateTimeSlot creates a time slot and invalidates its event's recommendations
+func (r *CachedRepository) CreateTimeSlot(ctx context.Context, slot *models.TimeSlot) error {
+	return r.changed(ctx, r.Repository.CreateTimeSlot(ctx, slot), slot.EventID)
+}
+
+// CreateTimeSlots adds time slots to an event and invalidates its recommendations
+func (r *CachedRepository) CreateTimeSlots(ctx context.Context, eventID uint, slots []models.TimeSlot) ([]models.TimeSlot, error) {
+	created, err := r.Repository.CreateTimeSlots(ctx, eventID, slots)
+	return created, r.changed(ctx, err, eventID)
+}
+
+// InviteParticipant invites a participant and invalidates the event's recommendations
+func (r *CachedRepository) InviteParticipant(ctx context.Context, invitation *models.EventInvitation) error {
+	return r.changed(ctx, r.Repository.InviteParticipant(ctx, invitation), invitation.EventID)
+}
+
+// RemoveInvitation removes an invitation and invalidates the event's recommendations
+func (r *CachedRepository) RemoveInvitation(ctx context.Context, eventID, participantID uint) error {
+	return r.changed(ctx, r.Repository.RemoveInvitation(ctx, eventID, participantID), eventID)
+}
+
+// ClaimInvitationLink claims a link and invalidates the event's recommendations
+func (r *CachedRepository) ClaimInvitationLink(ctx context.Context, link *models.InvitationLink) (*models.Participant, error) {
+	participant, err := r.Repository.ClaimInvitationLink(ctx, link)
+	return participant, r.changed(ctx, err, link.EventID)
+}
+
+// UpsertAvailability stores an answer and invalidates the event's recommendations
+func (r *CachedRepository) UpsertAvailability(ctx context.Context, eventID uint, availability *models.Availability) error {
+	return r.changed(ctx, r.Repository.UpsertAvailability(ctx, eventID, availability), eventID)
+}
+
+// UpsertAvailabilities stores answers and invalidates the event's recommendations
+func (r *CachedRepository) UpsertAvailabilities(ctx context.Context, eventID uint, availabilities []models.Availability) error {
+	return r.changed(ctx, r.Repository.UpsertAvailabilities(ctx, eventID, availabilities), eventID)
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+)
+
+// memoryCache is a Cache kept in a map
+type memoryCache struct {
+	values  map[string][]byte
+	deleted []string
+	err     error
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{values: make(map[string][]byte)}
+}
+
+func (c *memoryCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	if c.err != nil {
+		return nil, false, c.err
+	}
+	value, ok := c.values[key]
+	return value, ok, nil
+}
+
+func (c *memoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.values[key] = value
+	return nil
+}
+
+func (c *memoryCache) Delete(ctx context.Context, keys ...string) error {
+	c.deleted = append(c.deleted, keys...)
+	for _, key := range keys {
+		delete(c.values, key)
+	}
+	return c.err
+}
+
+func (c *memoryCache) Incr(ctx context.Context, key string) (int64, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	value, _ := strconv.ParseInt(string(c.values[key]), 10, 64)
+	value++
+	c.values[key] = []byte(strconv.FormatInt(value, 10))
+	return value, nil
+}
+
+// countingRepository answers recommendation queries and counts them. The
+// operations the tests do not use are left to the nil embedded Repository.
+type countingRepository struct {
+	Repository
+	loads     int
+	answers   int
+	upsertErr error
+	onLoad    func() // runs after the recommendations are loaded
+}
+
+func (r *countingRepository) Transaction(ctx context.Context, fn func(Repository) error) error {
+	return fn(r)
+}
+
+func (r *countingRepository) GetTimeSlotRecommendations(ctx context.Context, eventID uint) ([]models.TimeSlotRecommendation, error) {
+	r.loads++
+	recommendations := []models.TimeSlotRecommendation{{
+		TimeSlot:       models.TimeSlot{ID: 1, EventID: eventID},
+		Score:          float64(r.answers),
+		AvailableCount: r.answers,
+	}}
+	if r.onLoad != nil {
+		r.onLoad()
+	}
+	return recommendations, nil
+}
+
+func (r *countingRepository) UpsertAvailability(ctx context.Context, eventID uint, availability *models.Availability) error {
+	if r.upsertErr != nil {
+		return r.upsertErr
+	}
+	r.answers++
+	return nil
+}
+
+func TestCachedRecommendations(t *testing.T) {
+	ctx := context.Background()
+	repo := &countingRepository{}
+	cache := newMemoryCache()
+	cached := NewCachedRepository(repo, cache, time.Minute)
+
+	first, err := cached.GetTimeSlotRecommendations(ctx, 7)
+	require.NoError(t, err)
+	second, err := cached.GetTimeSlotRecommendations(ctx, 7)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, repo.loads, "the second request is answered from the cache")
+	assert.Equal(t, first[0].TimeSlot.ID, second[0].TimeSlot.ID)
+	assert.Contains(t, cache.values, "recommendations:event:7:0")
+
+	require.NoError(t, cached.UpsertAvailability(ctx, 7, &models.Availability{TimeSlotID: 1}))
+	assert.NotContains(t, cache.values, "recommendations:event:7:0", "an answer invalidates the event's recommendations")
+
+	fresh, err := cached.GetTimeSlotRecommendations(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, 2, repo.loads)
+	assert.Equal(t, 1, fresh[0].AvailableCount)
+}
+
+func TestCachedRecommendationsInvalidatedAfterCommit(t *testing.T) {
+	ctx := context.Background()
+	repo := &countingRepository{}
+	cache := newMemoryCache()
+	cached := NewCachedRepository(repo, cache, time.Minute)
+	_, err := cached.GetTimeSlotRecommendations(ctx, 7)
+	require.NoError(t, err)
+
+	err = cached.Transaction(ctx, func(tx Repository) error {
+		if err := tx.UpsertAvailability(ctx, 7, &models.Availability{TimeSlotID: 1}); err != nil {
+			return err
+		}
+		assert.Contains(t, cache.values, "recommendations:event:7:0", "the cache is kept until the transaction commits")
+
+		recommendations, err := tx.GetTimeSlotRecommendations(ctx, 7)
+		require.NoError(t, err)
+		assert.Equal(t, 1, recommendations[0].AvailableCount, "the transaction sees its own changes")
+		return nil
+	})
+	require.NoError(t, err)
+	assert.NotContains(t, cache.values, "recommendations:event:7:0")
+	assert.Equal(t, 2, repo.loads)
+
+	_, err = cached.GetTimeSlotRecommendations(ctx, 7)
+	require.NoError(t, err)
+	failed := errors.New("publishing failed")
+	err = cached.Transaction(ctx, func(tx Repository) error {
+		require.NoError(t, tx.UpsertAvailability(ctx, 7, &models.Availability{TimeSlotID: 1}))
+		return failed
+	})
+	assert.ErrorIs(t, err, failed)
+	assert.Contains(t, cache.values, "recommendations:event:7:1", "a rolled back change leaves the cache alone")
+}
+
+func TestCachedRecommendationsLoadedBeforeChangeAreNotServed(t *testing.T) {
+	ctx := context.Background()
+	repo := &countingRepository{}
+	cache := newMemoryCache()
+	cached := NewCachedRepository(repo, cache, time.Minute)
+
+	// An answer is stored while the recommendations are loaded, so those
+	// cached on the miss are already out of date
+	repo.onLoad = func() {
+		repo.onLoad = nil
+		require.NoError(t, cached.UpsertAvailability(ctx, 7, &models.Availability{TimeSlotID: 1}))
+	}
+	stale, err := cached.GetTimeSlotRecommendations(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, 0, stale[0].AvailableCount)
+
+	fresh, err := cached.GetTimeSlotRecommendations(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, 2, repo.loads, "the recommendations cached after the change are not read")
+	assert.Equal(t, 1, fresh[0].AvailableCount)
+}
+
+func TestCachedRepositoryFailedChangeKeepsCache(t *testing.T) {
+	ctx := context.Background()
+	repo := &countingRepository{upsertErr: notFound("Time slot")}
+	cache := newMemoryCache()
+	cached := NewCachedRepository(repo, cache, time.Minute)
+	_, err := cached.GetTimeSlotRecommendations(ctx, 7)
+	require.NoError(t, err)
+
+	err = cached.UpsertAvailability(ctx, 7, &models.Availability{TimeSlotID: 9})
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Empty(t, cache.deleted)
+}
+
+func TestCachedRepositoryWorksWithoutCache(t *testing.T) {
+	ctx := context.Background()
+	repo := &countingRepository{}
+	cache := newMemoryCache()
+	cache.err = errors.New("connection refused")
+	cached := NewCachedRepository(repo, cache, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		recommendations, err := cached.GetTimeSlotRecommendations(ctx, 7)
+		require.NoError(t, err)
+		assert.Len(t, recommendations, 1)
+	}
+	assert.Equal(t, 2, repo.loads, "every request goes to the database")
+	assert.NoError(t, cached.UpsertAvailability(ctx, 7, &models.Availability{TimeSlotID: 1}), "a failed invalidation does not fail the change")
+}
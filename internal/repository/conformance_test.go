@@ -0,0 +1,57 @@
+package repository_test
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/require"
+	"github.com/tusharsingune/meeting-scheduler/internal/config"
+	"github.com/tusharsingune/meeting-scheduler/internal/repository"
+	"github.com/tusharsingune/meeting-scheduler/internal/repository/repositorytest"
+)
+
+func TestMemoryConformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repository.Repository {
+		return repository.NewMemoryRepository()
+	})
+}
+
+// TestPostgresConformance runs the suite against the database that
+// scripts/setup_test_db.sh creates. It is skipped unless TEST_DB_HOST is set.
+func TestPostgresConformance(t *testing.T) {
+	if testing.Short() || os.Getenv("TEST_DB_HOST") == "" {
+		t.Skip("set TEST_DB_HOST and the other TEST_DB_* variables to run against PostgreSQL")
+	}
+	cfg := config.DatabaseConfig{
+		Driver:         "postgres",
+		Host:           os.Getenv("TEST_DB_HOST"),
+		Port:           envOr("TEST_DB_PORT", "5432"),
+		User:           envOr("TEST_DB_USER", "postgres"),
+		Password:       os.Getenv("TEST_DB_PASSWORD"),
+		DBName:         envOr("TEST_DB_NAME", "scheduler_test"),
+		SSLMode:        "disable",
+		MigrateOnStart: true,
+	}
+	repo, err := repository.NewPostgresDB(cfg)
+	require.NoError(t, err)
+	db, err := sql.Open("pgx", cfg.DSN())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	repositorytest.Run(t, func(t *testing.T) repository.Repository {
+		_, err := db.Exec(`TRUNCATE participants, api_keys, events, occurrence_exceptions, time_slots,
+			event_invitations, invitation_links, availabilities, availability_histories, notifications,
+			webhooks, outbox_events, webhook_deliveries, jobs RESTART IDENTITY CASCADE`)
+		require.NoError(t, err)
+		return repo
+	})
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// Errors a Repository returns for failures the caller can act on. Match them
+// with errors.Is; any other error is a failure of the database itself.
+var (
+	// ErrNotFound means the record does not exist or was deleted
+	ErrNotFound = errors.New("not found")
+	// ErrConflict means the write clashes with an existing record, such as a
+	// participant with the same email
+	ErrConflict = errors.New("conflict")
+	// ErrValidation means the database rejected the values written, such as
+	// a reference to a record that does not exist
+	ErrValidation = errors.New("validation failed")
+)
+
+// Error is an error of one of the kinds above with a message that is safe to
+// show to API clients. The database error behind it stays available to
+// errors.Is and errors.As but out of the message.
+type Error struct {
+	Kind    error
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the kind of the error and the error behind it
+func (e *Error) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// notFound returns an ErrNotFound for the named kind of record
+func notFound(entity string) error {
+	return &Error{Kind: ErrNotFound, Message: entity + " not found", Err: gorm.ErrRecordNotFound}
+}
+
+// PostgreSQL error codes translated to repository errors
+const (
+	uniqueViolation           = "23505"
+	foreignKeyViolation       = "23503"
+	notNullViolation          = "23502"
+	checkViolation            = "23514"
+	invalidTextRepresentation = "22P02"
+	stringDataRightTruncation = "22001"
+	numericValueOutOfRange    = "22003"
+)
+
+// keyColumns picks the column names out of a constraint violation's detail,
+// which reads like "Key (event_id, participant_id)=(1, 2) already exists."
+var keyColumns = regexp.MustCompile(`^Key \(([^)]+)\)=`)
+
+// translate turns an error from the database into a repository error. entity
+// names the kind of record the statement was about, such as "Participant".
+// Errors with no translation are returned unchanged.
+func translate(err error, entity string) error {
+	if err == nil {
+		return nil
+	}
+	var repoErr *Error
+	if errors.As(err, &repoErr) {
+		return err
+	}
+	if entity == "" {
+		entity = "Record"
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &Error{Kind: ErrNotFound, Message: entity + " not found", Err: err}
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	switch pgErr.Code {
+	case uniqueViolation:
+		message := fmt.Sprintf("%s already exists", article(entity))
+		if columns := violatedColumns(pgErr); columns != "" {
+			message = fmt.Sprintf("%s with this %s already exists", article(entity), columns)
+		}
+		return &Error{Kind: ErrConflict, Message: message, Err: err}
+	case foreignKeyViolation:
+		message := entity + " refers to a record that does not exist"
+		if columns := violatedColumns(pgErr); columns != "" {
+			message = fmt.Sprintf("%s has no matching record for its %s", entity, columns)
+		}
+		return &Error{Kind: ErrValidation, Message: message, Err: err}
+	case notNullViolation:
+		column := strings.ReplaceAll(pgErr.ColumnName, "_", " ")
+		return &Error{Kind: ErrValidation, Message: fmt.Sprintf("%s is missing its %s", entity, column), Err: err}
+	case checkViolation, invalidTextRepresentation, stringDataRightTruncation, numericValueOutOfRange:
+		return &Error{Kind: ErrValidation, Message: entity + " has a value the database does not accept", Err: err}
+	}
+	return err
+}
+
+// violatedColumns names the columns of a violated key in words, or returns
+// "" when the error does not say which they are
+func violatedColumns(pgErr *pgconn.PgError) string {
+	match := keyColumns.FindStringSubmatch(pgErr.Detail)
+	if match == nil {
+		return ""
+	}
+	var names []string
+	for _, column := range strings.Split(match[1], ",") {
+		column = strings.TrimSpace(column)
+		column = strings.TrimPrefix(strings.TrimSuffix(column, ")"), "lower(")
+		names = append(names, strings.ReplaceAll(column, "_", " "))
+	}
+	return strings.Join(names, " and ")
+}
+
+// article prefixes an entity name with "A" or "An"
+func article(entity string) string {
+	lower := strings.ToLower(entity[:1]) + entity[1:]
+	if strings.ContainsRune("aeiou", rune(lower[0])) {
+		return "An " + lower
+	}
+	return "A " + lower
+}
+
+// entityName turns a model name such as "EventInvitation" into the words
+// "Event invitation" used in error messages
+func entityName(model string) string {
+	var b strings.Builder
+	for i, r := range model {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteByte(' ')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// registerErrorTranslation makes every statement run through db return
+// repository errors
+func registerErrorTranslation(db *gorm.DB) error {
+	translateErrors := func(tx *gorm.DB) {
+		if tx.Error == nil {
+			return
+		}
+		entity := ""
+		if tx.Statement.Schema != nil {
+			entity = entityName(tx.Statement.Schema.Name)
+		}
+		tx.Error = translate(tx.Error, entity)
+	}
+
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().Register("repository:translate_errors", translateErrors),
+		callbacks.Query().Register("repository:translate_errors", translateErrors),
+		callbacks.Update().Register("repository:translate_errors", translateErrors),
+		callbacks.Delete().Register("repository:translate_errors", translateErrors),
+		callbacks.Row().Register("repository:translate_errors", translateErrors),
+		callbacks.Raw().Register("repository:translate_errors", translateErrors),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
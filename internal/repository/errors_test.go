@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		entity      string
+		wantKind    error
+		wantMessage string
+	}{
+		{"record not found", gorm.ErrRecordNotFound, "Event", ErrNotFound, "Event not found"},
+		{"wrapped record not found", fmt.Errorf("loading: %w", gorm.ErrRecordNotFound), "", ErrNotFound, "Record not found"},
+		{
+			"duplicate email",
+			&pgconn.PgError{Code: "23505", Detail: "Key (email)=(ada@example.com) already exists."},
+			"Participant", ErrConflict, "A participant with this email already exists",
+		},
+		{
+			"duplicate invitation",
+			&pgconn.PgError{Code: "23505", Detail: "Key (event_id, participant_id)=(1, 2) already exists."},
+			"Event invitation", ErrConflict, "An event invitation with this event id and participant id already exists",
+		},
+		{
+			"unknown reference",
+			&pgconn.PgError{Code: "23503", Detail: `Key (organizer_id)=(9) is not present in table "participants".`},
+			"Event", ErrValidation, "Event has no matching record for its organizer id",
+		},
+		{"missing value", &pgconn.PgError{Code: "23502", ColumnName: "time_zone"}, "Participant", ErrValidation, "Participant is missing its time zone"},
+		{"value too long", &pgconn.PgError{Code: "22001"}, "Event", ErrValidation, "Event has a value the database does not accept"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := translate(tt.err, tt.entity)
+
+			assert.ErrorIs(t, err, tt.wantKind)
+			assert.ErrorIs(t, err, tt.err, "the database error stays reachable")
+			assert.Equal(t, tt.wantMessage, err.Error())
+		})
+	}
+}
+
+func TestTranslateLeavesOtherErrors(t *testing.T) {
+	connErr := errors.New("connection refused")
+	assert.Equal(t, connErr, translate(connErr, "Event"))
+	assert.Nil(t, translate(nil, "Event"))
+
+	deadlock := &pgconn.PgError{Code: "40P01"}
+	assert.Equal(t, error(deadlock), translate(deadlock, "Event"))
+
+	translated := translate(gorm.ErrRecordNotFound, "Event")
+	assert.Same(t, translated, translate(translated, "Participant"), "errors are translated once")
+}
+
+func TestEntityName(t *testing.T) {
+	assert.Equal(t, "Event invitation", entityName("EventInvitation"))
+	assert.Equal(t, "Participant", entityName("Participant"))
+}
@@ -1,26 +1,93 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/tusharsingune/meeting-scheduler/internal/models"
 )
 
-// Repository defines the interface for data operations
+// Repository defines the interface for data operations. Every operation
+// takes the context of the request or job it is done for, and is cancelled
+// with it.
 type Repository interface {
+	// Transaction runs fn with a Repository whose operations all commit or
+	// roll back together, depending on whether fn returns an error
+	Transaction(ctx context.Context, fn func(Repository) error) error
+
 	// Event operations
-	CreateEvent(*models.Event) error
-	GetEvent(uint) (*models.Event, error)
-	UpdateEvent(*models.Event) error
-	DeleteEvent(uint) error
+	CreateEvent(context.Context, *models.Event) error
+	GetEvent(context.Context, uint) (*models.Event, error)
+	UpdateEvent(context.Context, *models.Event) error
+	TransitionEvent(ctx context.Context, event *models.Event, from ...models.EventStatus) (bool, error)
+	DeleteEvent(context.Context, uint) error
+	GetParticipantEvents(context.Context, uint) ([]models.Event, error)
+	ListEvents(context.Context, EventFilter) ([]models.Event, string, error)
+
+	// Occurrence exception operations
+	UpsertOccurrenceException(context.Context, *models.OccurrenceException) error
+	DeleteOccurrenceException(ctx context.Context, eventID, exceptionID uint) error
 
 	// TimeSlot operations
-	CreateTimeSlot(*models.TimeSlot) error
-	GetTimeSlots(uint) ([]models.TimeSlot, error)
+	CreateTimeSlot(context.Context, *models.TimeSlot) error
+	CreateTimeSlots(context.Context, uint, []models.TimeSlot) ([]models.TimeSlot, error)
+	GetTimeSlots(context.Context, uint) ([]models.TimeSlot, error)
+	ListTimeSlots(ctx context.Context, eventID uint, opts ListOptions) ([]models.TimeSlot, string, error)
+
+	// Invitation operations
+	InviteParticipant(context.Context, *models.EventInvitation) error
+	GetInvitations(context.Context, uint) ([]models.EventInvitation, error)
+	RemoveInvitation(ctx context.Context, eventID, participantID uint) error
+
+	// Invitation link operations
+	CreateInvitationLink(context.Context, *models.InvitationLink) error
+	GetInvitationLinkByHash(context.Context, string) (*models.InvitationLink, error)
+	GetInvitationLinks(context.Context, uint) ([]models.InvitationLink, error)
+	RevokeInvitationLink(ctx context.Context, eventID, linkID uint) error
+	ClaimInvitationLink(context.Context, *models.InvitationLink) (*models.Participant, error)
 
 	// Availability operations
-	CreateAvailability(*models.Availability) error
-	GetTimeSlotRecommendations(uint) ([]models.TimeSlotRecommendation, error)
+	UpsertAvailability(ctx context.Context, eventID uint, availability *models.Availability) error
+	UpsertAvailabilities(context.Context, uint, []models.Availability) error
+	GetEventAvailabilities(context.Context, uint) ([]models.Availability, error)
+	GetAvailabilityHistory(ctx context.Context, eventID, participantID uint) ([]models.AvailabilityHistory, error)
+	GetTimeSlotRecommendations(context.Context, uint) ([]models.TimeSlotRecommendation, error)
 
 	// Participant operations
-	CreateParticipant(*models.Participant) error
-	GetParticipant(uint) (*models.Participant, error)
+	CreateParticipant(context.Context, *models.Participant) error
+	GetParticipant(context.Context, uint) (*models.Participant, error)
+	ListParticipants(context.Context, ParticipantFilter) ([]models.Participant, string, error)
+
+	// Notification operations
+	CreateNotification(context.Context, *models.Notification) error
+	GetEventNotifications(context.Context, uint) ([]models.Notification, error)
+	ClaimNotifications(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.Notification, error)
+	UpdateNotification(context.Context, *models.Notification) error
+
+	// Webhook operations
+	CreateWebhook(context.Context, *models.Webhook) error
+	GetParticipantWebhooks(context.Context, uint) ([]models.Webhook, error)
+	DeleteWebhook(ctx context.Context, participantID, webhookID uint) error
+	GetWebhookDeliveries(ctx context.Context, participantID, webhookID uint) ([]models.WebhookDelivery, error)
+
+	// Outbox operations
+	CreateOutboxEvent(context.Context, *models.OutboxEvent) error
+	DispatchOutboxEvents(ctx context.Context, limit int) (int, error)
+	ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error)
+	UpdateWebhookDelivery(context.Context, *models.WebhookDelivery) error
+
+	// Background job operations
+	ClaimJob(ctx context.Context, name, owner string, now time.Time, lease time.Duration) (*models.Job, error)
+	FinishJob(context.Context, *models.Job) error
+	GetExpiredPolls(ctx context.Context, now time.Time, limit int) ([]models.Event, error)
+	ClosePoll(ctx context.Context, event *models.Event, now time.Time) (bool, error)
+	GetPollsToRemind(ctx context.Context, now, deadlineBefore time.Time, limit int) ([]models.Event, error)
+	MarkReminded(ctx context.Context, eventID uint, at time.Time) (bool, error)
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+
+	// API key operations
+	CreateAPIKey(context.Context, *models.APIKey) error
+	GetAPIKeyByHash(context.Context, string) (*models.APIKey, error)
+	GetParticipantAPIKeys(context.Context, uint) ([]models.APIKey, error)
+	DeleteAPIKey(ctx context.Context, participantID, keyID uint) error
 }
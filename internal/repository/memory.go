@@ -0,0 +1,1459 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"github.com/tusharsingune/meeting-scheduler/internal/scheduling"
+	"gorm.io/gorm"
+)
+
+// MemoryRepository implements the Repository interface in memory, for running
+// the server without a database and for tests. It behaves like
+// PostgresRepository: IDs count up from 1, deletes are soft, unique keys and
+// references are enforced, and operations of several steps, transactions
+// included, leave no trace when they fail. Its data is lost when the process
+// exits.
+type MemoryRepository struct {
+	mu            *sync.Mutex
+	data          *memoryData
+	inTransaction bool // a transaction holds mu
+}
+
+// NewMemoryRepository creates an empty in-memory repository
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		mu: &sync.Mutex{},
+		data: &memoryData{
+			events:         newTable[models.Event](),
+			timeSlots:      newTable[models.TimeSlot](),
+			exceptions:     newTable[models.OccurrenceException](),
+			participants:   newTable[models.Participant](),
+			invitations:    newTable[models.EventInvitation](),
+			links:          newTable[models.InvitationLink](),
+			availabilities: newTable[models.Availability](),
+			history:        newTable[models.AvailabilityHistory](),
+			notifications:  newTable[models.Notification](),
+			webhooks:       newTable[models.Webhook](),
+			outbox:         newTable[models.OutboxEvent](),
+			deliveries:     newTable[models.WebhookDelivery](),
+			apiKeys:        newTable[models.APIKey](),
+			jobs:           make(map[string]models.Job),
+		},
+	}
+}
+
+// table holds the rows of one model by ID
+type table[T any] struct {
+	rows map[uint]T
+	// seq is the last ID assigned. Snapshots share it, so as with a database
+	// sequence, IDs used by an operation that was undone are not reused.
+	seq *uint
+}
+
+func newTable[T any]() table[T] {
+	return table[T]{rows: make(map[uint]T), seq: new(uint)}
+}
+
+// assign gives a new row the next ID unless it has one, which must be free
+func (t table[T]) assign(id *uint, entity string) error {
+	if *id == 0 {
+		*t.seq++
+		*id = *t.seq
+		return nil
+	}
+	if _, taken := t.rows[*id]; taken {
+		return &Error{Kind: ErrConflict, Message: article(entity) + " with this id already exists"}
+	}
+	return nil
+}
+
+// where returns the rows for which keep is true, ordered by ID
+func (t table[T]) where(keep func(T) bool) []T {
+	rows := make([]T, 0)
+	for _, id := range slices.Sorted(maps.Keys(t.rows)) {
+		if row := t.rows[id]; keep(row) {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// deleteWhere removes the rows for which remove is true and returns how many it removed
+func (t table[T]) deleteWhere(remove func(T) bool) int64 {
+	var removed int64
+	for id, row := range t.rows {
+		if remove(row) {
+			delete(t.rows, id)
+			removed++
+		}
+	}
+	return removed
+}
+
+func (t table[T]) clone() table[T] {
+	return table[T]{rows: maps.Clone(t.rows), seq: t.seq}
+}
+
+// memoryData holds the tables of a MemoryRepository. Rows are stored without
+// their associations, which are loaded when they are read.
+type memoryData struct {
+	events         table[models.Event]
+	timeSlots      table[models.TimeSlot]
+	exceptions     table[models.OccurrenceException]
+	participants   table[models.Participant]
+	invitations    table[models.EventInvitation]
+	links          table[models.InvitationLink]
+	availabilities table[models.Availability]
+	history        table[models.AvailabilityHistory]
+	notifications  table[models.Notification]
+	webhooks       table[models.Webhook]
+	outbox         table[models.OutboxEvent]
+	deliveries     table[models.WebhookDelivery]
+	apiKeys        table[models.APIKey]
+	jobs           map[string]models.Job
+}
+
+func (d *memoryData) clone() memoryData {
+	return memoryData{
+		events:         d.events.clone(),
+		timeSlots:      d.timeSlots.clone(),
+		exceptions:     d.exceptions.clone(),
+		participants:   d.participants.clone(),
+		invitations:    d.invitations.clone(),
+		links:          d.links.clone(),
+		availabilities: d.availabilities.clone(),
+		history:        d.history.clone(),
+		notifications:  d.notifications.clone(),
+		webhooks:       d.webhooks.clone(),
+		outbox:         d.outbox.clone(),
+		deliveries:     d.deliveries.clone(),
+		apiKeys:        d.apiKeys.clone(),
+		jobs:           maps.Clone(d.jobs),
+	}
+}
+
+// atomically runs fn and undoes its changes unless it succeeds
+func (d *memoryData) atomically(fn func() error) error {
+	snapshot := d.clone()
+	committed := false
+	defer func() {
+		if !committed {
+			*d = snapshot
+		}
+	}()
+	if err := fn(); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+// lock takes the repository's lock and returns its release. Within a
+// transaction, which holds the lock throughout, it does nothing.
+func (r *MemoryRepository) lock() func() {
+	if r.inTransaction {
+		return func() {}
+	}
+	r.mu.Lock()
+	return r.mu.Unlock
+}
+
+// memoryNow returns the current time with the microsecond precision
+// PostgreSQL stores
+func memoryNow() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// stamp sets the creation and update times of a new row unless it has them
+func stamp(createdAt, updatedAt *time.Time, now time.Time) {
+	if createdAt.IsZero() {
+		*createdAt = now
+	}
+	if updatedAt != nil && updatedAt.IsZero() {
+		*updatedAt = now
+	}
+}
+
+// alive reports whether a row has not been soft-deleted
+func alive(deletedAt gorm.DeletedAt) bool {
+	return !deletedAt.Valid
+}
+
+// softDeleted marks a row soft-deleted at now
+func softDeleted(now time.Time) gorm.DeletedAt {
+	return gorm.DeletedAt{Time: now, Valid: true}
+}
+
+// deletedBefore reports whether a row was soft-deleted before cutoff
+func deletedBefore(deletedAt gorm.DeletedAt, cutoff time.Time) bool {
+	return deletedAt.Valid && deletedAt.Time.Before(cutoff)
+}
+
+// missingReference returns the error of a row referring to a record that does not exist
+func missingReference(entity, column string) error {
+	return &Error{Kind: ErrValidation, Message: fmt.Sprintf("%s has no matching record for its %s", entity, column)}
+}
+
+// limited returns at most limit rows, or all of them when limit is not positive
+func limited[T any](rows []T, limit int) []T {
+	if limit > 0 && len(rows) > limit {
+		return rows[:limit]
+	}
+	return rows
+}
+
+// Transaction runs fn with a repository whose changes are undone if fn
+// fails. The transaction holds the repository's lock, so fn must only use
+// the repository it is given.
+func (r *MemoryRepository) Transaction(ctx context.Context, fn func(Repository) error) error {
+	defer r.lock()()
+	tx := &MemoryRepository{mu: r.mu, data: r.data, inTransaction: true}
+	return r.data.atomically(func() error {
+		return fn(tx)
+	})
+}
+
+// CreateEvent creates a new event with the time slots, invitations and
+// exceptions it is given
+func (r *MemoryRepository) CreateEvent(ctx context.Context, event *models.Event) error {
+	defer r.lock()()
+	d := r.data
+	return d.atomically(func() error {
+		now := memoryNow()
+		if err := d.events.assign(&event.ID, "Event"); err != nil {
+			return err
+		}
+		stamp(&event.CreatedAt, &event.UpdatedAt, now)
+		if event.Status == "" {
+			event.Status = models.EventDraft
+		}
+		if event.DecisionPolicy == "" {
+			event.DecisionPolicy = models.DecisionManual
+		}
+		d.events.rows[event.ID] = storedEvent(*event)
+
+		for i := range event.TimeSlots {
+			event.TimeSlots[i].EventID = event.ID
+			if err := d.createTimeSlot(&event.TimeSlots[i], now); err != nil {
+				return err
+			}
+		}
+		for i := range event.Invitations {
+			event.Invitations[i].EventID = event.ID
+			if err := d.upsertInvitation(&event.Invitations[i], false, now); err != nil {
+				return err
+			}
+		}
+		for i := range event.Exceptions {
+			event.Exceptions[i].EventID = event.ID
+			if err := d.upsertException(&event.Exceptions[i], now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// storedEvent returns an event without its associations and the fields that
+// are not stored
+func storedEvent(event models.Event) models.Event {
+	event.TimeSlots = nil
+	event.Invitations = nil
+	event.Exceptions = nil
+	event.ConfirmedTimeSlot = nil
+	event.Occurrences = nil
+	return event
+}
+
+// GetEvent retrieves an event by ID
+func (r *MemoryRepository) GetEvent(ctx context.Context, id uint) (*models.Event, error) {
+	defer r.lock()()
+	event, ok := r.data.events.rows[id]
+	if !ok || !alive(event.DeletedAt) {
+		return nil, notFound("Event")
+	}
+	event = r.data.loadEvent(event)
+	return &event, nil
+}
+
+// loadEvent returns an event with its time slots, its invitations with their
+// participants and its exceptions in the order of their occurrences
+func (d *memoryData) loadEvent(event models.Event) models.Event {
+	event.TimeSlots = d.eventTimeSlots(event.ID)
+	event.Invitations = d.eventInvitations(event.ID)
+	event.Exceptions = d.exceptions.where(func(x models.OccurrenceException) bool {
+		return x.EventID == event.ID && alive(x.DeletedAt)
+	})
+	slices.SortStableFunc(event.Exceptions, func(a, b models.OccurrenceException) int {
+		return a.OccurrenceStart.Compare(b.OccurrenceStart)
+	})
+	setConfirmedTimeSlot(&event)
+	return event
+}
+
+// loadEvents loads the associations of several events
+func (d *memoryData) loadEvents(events []models.Event) []models.Event {
+	for i := range events {
+		events[i] = d.loadEvent(events[i])
+	}
+	return events
+}
+
+// UpdateEvent stores the fields of an event its organizer edits, and
+// forgets that invitees were reminded of a poll deadline that moved
+func (r *MemoryRepository) UpdateEvent(ctx context.Context, event *models.Event) error {
+	defer r.lock()()
+	stored, ok := r.data.events.rows[event.ID]
+	if !ok || !alive(stored.DeletedAt) {
+		return notFound("Event")
+	}
+	if !sameTime(stored.PollDeadline, event.PollDeadline) {
+		stored.RemindedAt = nil
+	}
+	stored.Title = event.Title
+	stored.Description = event.Description
+	stored.OrganizerId = event.OrganizerId
+	stored.Duration = event.Duration
+	stored.Recurrence = event.Recurrence
+	stored.TimeZone = event.TimeZone
+	stored.PollDeadline = event.PollDeadline
+	stored.DecisionPolicy = event.DecisionPolicy
+	stored.UpdatedAt = memoryNow()
+	r.data.events.rows[event.ID] = stored
+	event.UpdatedAt = stored.UpdatedAt
+	return nil
+}
+
+// sameTime reports whether two optional times are both unset or the same instant
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// TransitionEvent stores the lifecycle of an event, its status, confirmed
+// time slot and decision, if its status is still one of from, and reports
+// whether it did
+func (r *MemoryRepository) TransitionEvent(ctx context.Context, event *models.Event, from ...models.EventStatus) (bool, error) {
+	defer r.lock()()
+	stored, ok := r.data.events.rows[event.ID]
+	if !ok || !alive(stored.DeletedAt) || !slices.Contains(from, stored.Status) {
+		return false, nil
+	}
+	setLifecycle(&stored, event, memoryNow())
+	r.data.events.rows[event.ID] = stored
+	event.UpdatedAt = stored.UpdatedAt
+	return true, nil
+}
+
+// setLifecycle copies the lifecycle of an event, its status, confirmed time
+// slot and decision, onto a stored event
+func setLifecycle(stored, event *models.Event, now time.Time) {
+	stored.Status = event.Status
+	stored.ConfirmedTimeSlotID = event.ConfirmedTimeSlotID
+	stored.ConfirmedAt = event.ConfirmedAt
+	stored.DecisionOutcome = event.DecisionOutcome
+	stored.DecisionReason = event.DecisionReason
+	stored.DecidedAt = event.DecidedAt
+	stored.UpdatedAt = now
+}
+
+// DeleteEvent deletes an event by ID, together with its time slots and the
+// answers given for them
+func (r *MemoryRepository) DeleteEvent(ctx context.Context, id uint) error {
+	defer r.lock()()
+	d := r.data
+	deleted := softDeleted(memoryNow())
+	for _, slot := range d.eventTimeSlots(id) {
+		for answerID, answer := range d.availabilities.rows {
+			if answer.TimeSlotID == slot.ID && alive(answer.DeletedAt) {
+				answer.DeletedAt = deleted
+				d.availabilities.rows[answerID] = answer
+			}
+		}
+		slot.DeletedAt = deleted
+		d.timeSlots.rows[slot.ID] = slot
+	}
+	if event, ok := d.events.rows[id]; ok && alive(event.DeletedAt) {
+		event.DeletedAt = deleted
+		d.events.rows[id] = event
+	}
+	return nil
+}
+
+// GetParticipantEvents retrieves the events a participant organizes, is
+// invited to or has answered
+func (r *MemoryRepository) GetParticipantEvents(ctx context.Context, participantID uint) ([]models.Event, error) {
+	defer r.lock()()
+	d := r.data
+	answered := make(map[uint]bool)
+	for _, a := range d.availabilities.rows {
+		if slot, ok := d.timeSlots.rows[a.TimeSlotID]; ok && a.ParticipantID == participantID &&
+			alive(a.DeletedAt) && alive(slot.DeletedAt) {
+			answered[slot.EventID] = true
+		}
+	}
+
+	events := d.events.where(func(e models.Event) bool {
+		return alive(e.DeletedAt) &&
+			(e.OrganizerId == participantID || d.invited(e.ID, participantID) || answered[e.ID])
+	})
+	return d.loadEvents(events), nil
+}
+
+// invited reports whether a participant is invited to an event
+func (d *memoryData) invited(eventID, participantID uint) bool {
+	for _, invitation := range d.invitations.rows {
+		if invitation.EventID == eventID && invitation.ParticipantID == participantID && alive(invitation.DeletedAt) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListEvents returns a page of the events a participant can see, and the
+// cursor of the next page
+func (r *MemoryRepository) ListEvents(ctx context.Context, filter EventFilter) ([]models.Event, string, error) {
+	ks, err := resolveKeyset(filter.ListOptions, eventSorts, defaultEventSort)
+	if err != nil {
+		return nil, "", err
+	}
+
+	defer r.lock()()
+	d := r.data
+	inRange := func(eventID uint) bool {
+		for _, slot := range d.timeSlots.rows {
+			if slot.EventID == eventID && alive(slot.DeletedAt) &&
+				(filter.From == nil || slot.EndTime.After(*filter.From)) &&
+				(filter.To == nil || slot.StartTime.Before(*filter.To)) {
+				return true
+			}
+		}
+		return false
+	}
+	events := d.events.where(func(e models.Event) bool {
+		return alive(e.DeletedAt) &&
+			(e.OrganizerId == filter.VisibleTo || d.invited(e.ID, filter.VisibleTo)) &&
+			(filter.OrganizerID == 0 || e.OrganizerId == filter.OrganizerID) &&
+			(len(filter.Statuses) == 0 || slices.Contains(filter.Statuses, e.Status)) &&
+			(filter.From == nil && filter.To == nil || inRange(e.ID))
+	})
+
+	limit := filter.limit()
+	id := func(e models.Event) uint { return e.ID }
+	events, next := ks.page(ks.seek(events, limit, id), limit, id)
+	return d.loadEvents(events), next, nil
+}
+
+// seek orders records by the keyset and returns those after its cursor, at
+// most one more than limit like apply does for a query
+func (ks *keyset[T]) seek(records []T, limit int, id func(T) uint) []T {
+	compare := func(value any, recordID uint, otherValue any, otherID uint) int {
+		c := compareSortValues(value, otherValue)
+		if c == 0 {
+			c = cmp.Compare(recordID, otherID)
+		}
+		if ks.descending {
+			return -c
+		}
+		return c
+	}
+
+	slices.SortFunc(records, func(a, b T) int {
+		return compare(ks.field.value(a), id(a), ks.field.value(b), id(b))
+	})
+	if ks.after != nil {
+		records = slices.DeleteFunc(records, func(record T) bool {
+			return compare(ks.field.value(record), id(record), ks.afterValue, ks.after.ID) <= 0
+		})
+	}
+	return limited(records, limit+1)
+}
+
+// compareSortValues compares two values of a sort field, both strings or
+// both times. Strings compare byte by byte, like PostgreSQL's C collation.
+func compareSortValues(a, b any) int {
+	switch a := a.(type) {
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	case string:
+		return strings.Compare(a, b.(string))
+	}
+	return 0
+}
+
+// UpsertOccurrenceException creates the exception for an occurrence or replaces the existing one
+func (r *MemoryRepository) UpsertOccurrenceException(ctx context.Context, exception *models.OccurrenceException) error {
+	defer r.lock()()
+	return r.data.upsertException(exception, memoryNow())
+}
+
+// upsertException stores an exception, replacing the one for the same
+// occurrence even if it was deleted
+func (d *memoryData) upsertException(exception *models.OccurrenceException, now time.Time) error {
+	if _, ok := d.events.rows[exception.EventID]; !ok {
+		return missingReference("Occurrence exception", "event id")
+	}
+	stamp(&exception.CreatedAt, &exception.UpdatedAt, now)
+
+	for id, existing := range d.exceptions.rows {
+		if existing.EventID == exception.EventID && existing.OccurrenceStart.Equal(exception.OccurrenceStart) {
+			exception.ID = id
+			existing.Cancelled = exception.Cancelled
+			existing.StartTime = exception.StartTime
+			existing.EndTime = exception.EndTime
+			existing.UpdatedAt = exception.UpdatedAt
+			existing.DeletedAt = exception.DeletedAt
+			d.exceptions.rows[id] = existing
+			return nil
+		}
+	}
+
+	if err := d.exceptions.assign(&exception.ID, "Occurrence exception"); err != nil {
+		return err
+	}
+	d.exceptions.rows[exception.ID] = *exception
+	return nil
+}
+
+// DeleteOccurrenceException restores an occurrence to its scheduled time
+func (r *MemoryRepository) DeleteOccurrenceException(ctx context.Context, eventID, exceptionID uint) error {
+	defer r.lock()()
+	exception, ok := r.data.exceptions.rows[exceptionID]
+	if !ok || exception.EventID != eventID || !alive(exception.DeletedAt) {
+		return notFound("Occurrence exception")
+	}
+	exception.DeletedAt = softDeleted(memoryNow())
+	r.data.exceptions.rows[exceptionID] = exception
+	return nil
+}
+
+// CreateTimeSlot adds a time slot to an event. It fails with ErrConflict if
+// the slot overlaps one the event already has.
+func (r *MemoryRepository) CreateTimeSlot(ctx context.Context, slot *models.TimeSlot) error {
+	defer r.lock()()
+	d := r.data
+	if event, ok := d.events.rows[slot.EventID]; !ok || !alive(event.DeletedAt) {
+		return notFound("Event")
+	}
+	if err := overlapConflict(*slot, d.eventTimeSlots(slot.EventID)); err != nil {
+		return err
+	}
+	return d.createTimeSlot(slot, memoryNow())
+}
+
+// createTimeSlot stores a new time slot of an existing event
+func (d *memoryData) createTimeSlot(slot *models.TimeSlot, now time.Time) error {
+	if _, ok := d.events.rows[slot.EventID]; !ok {
+		return missingReference("Time slot", "event id")
+	}
+	if err := d.timeSlots.assign(&slot.ID, "Time slot"); err != nil {
+		return err
+	}
+	stamp(&slot.CreatedAt, &slot.UpdatedAt, now)
+	stored := *slot
+	stored.Local = nil
+	d.timeSlots.rows[slot.ID] = stored
+	return nil
+}
+
+// CreateTimeSlots adds several time slots to an event, skipping those that
+// overlap a slot the event already has or one added before them. It returns
+// the slots that were created.
+func (r *MemoryRepository) CreateTimeSlots(ctx context.Context, eventID uint, slots []models.TimeSlot) ([]models.TimeSlot, error) {
+	defer r.lock()()
+	d := r.data
+	if event, ok := d.events.rows[eventID]; !ok || !alive(event.DeletedAt) {
+		return nil, notFound("Event")
+	}
+
+	existing := d.eventTimeSlots(eventID)
+	var created []models.TimeSlot
+	for _, slot := range slots {
+		if overlapsAny(slot, existing) {
+			continue
+		}
+		slot.EventID = eventID
+		created = append(created, slot)
+		existing = append(existing, slot)
+	}
+
+	now := memoryNow()
+	err := d.atomically(func() error {
+		for i := range created {
+			if err := d.createTimeSlot(&created[i], now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// eventTimeSlots returns the time slots of an event
+func (d *memoryData) eventTimeSlots(eventID uint) []models.TimeSlot {
+	return d.timeSlots.where(func(s models.TimeSlot) bool {
+		return s.EventID == eventID && alive(s.DeletedAt)
+	})
+}
+
+// GetTimeSlots retrieves all time slots for an event
+func (r *MemoryRepository) GetTimeSlots(ctx context.Context, eventID uint) ([]models.TimeSlot, error) {
+	defer r.lock()()
+	return r.data.eventTimeSlots(eventID), nil
+}
+
+// ListTimeSlots returns a page of the time slots of an event, and the cursor
+// of the next page
+func (r *MemoryRepository) ListTimeSlots(ctx context.Context, eventID uint, opts ListOptions) ([]models.TimeSlot, string, error) {
+	ks, err := resolveKeyset(opts, timeSlotSorts, defaultTimeSlotSort)
+	if err != nil {
+		return nil, "", err
+	}
+
+	defer r.lock()()
+	limit := opts.limit()
+	id := func(s models.TimeSlot) uint { return s.ID }
+	slots, next := ks.page(ks.seek(r.data.eventTimeSlots(eventID), limit, id), limit, id)
+	return slots, next, nil
+}
+
+// InviteParticipant invites a participant to an event. Inviting someone who
+// is already invited updates their role and whether they co-organize it.
+func (r *MemoryRepository) InviteParticipant(ctx context.Context, invitation *models.EventInvitation) error {
+	defer r.lock()()
+	return r.data.upsertInvitation(invitation, false, memoryNow())
+}
+
+// upsertInvitation stores an invitation, restoring the participant's
+// existing invitation to the event if it was withdrawn. The existing
+// invitation takes the new role unless keepRole is set.
+func (d *memoryData) upsertInvitation(invitation *models.EventInvitation, keepRole bool, now time.Time) error {
+	if invitation.Role == "" {
+		invitation.Role = models.AttendeeRequired
+	}
+	if _, ok := d.events.rows[invitation.EventID]; !ok {
+		return missingReference("Event invitation", "event id")
+	}
+	if _, ok := d.participants.rows[invitation.ParticipantID]; !ok {
+		return missingReference("Event invitation", "participant id")
+	}
+	stamp(&invitation.CreatedAt, &invitation.UpdatedAt, now)
+
+	for id, existing := range d.invitations.rows {
+		if existing.EventID == invitation.EventID && existing.ParticipantID == invitation.ParticipantID {
+			invitation.ID = id
+			if !keepRole {
+				existing.Role = invitation.Role
+				existing.CoOrganizer = invitation.CoOrganizer
+				existing.UpdatedAt = invitation.UpdatedAt
+			}
+			existing.DeletedAt = invitation.DeletedAt
+			d.invitations.rows[id] = existing
+			return nil
+		}
+	}
+
+	if err := d.invitations.assign(&invitation.ID, "Event invitation"); err != nil {
+		return err
+	}
+	stored := *invitation
+	stored.Participant = nil
+	d.invitations.rows[invitation.ID] = stored
+	return nil
+}
+
+// eventInvitations returns the invitations of an event with their participants
+func (d *memoryData) eventInvitations(eventID uint) []models.EventInvitation {
+	invitations := d.invitations.where(func(i models.EventInvitation) bool {
+		return i.EventID == eventID && alive(i.DeletedAt)
+	})
+	for i := range invitations {
+		if participant, ok := d.participants.rows[invitations[i].ParticipantID]; ok && alive(participant.DeletedAt) {
+			invitations[i].Participant = &participant
+		}
+	}
+	return invitations
+}
+
+// GetInvitations retrieves the invitations of an event with their participants
+func (r *MemoryRepository) GetInvitations(ctx context.Context, eventID uint) ([]models.EventInvitation, error) {
+	defer r.lock()()
+	return r.data.eventInvitations(eventID), nil
+}
+
+// RemoveInvitation withdraws a participant's invitation to an event
+func (r *MemoryRepository) RemoveInvitation(ctx context.Context, eventID, participantID uint) error {
+	defer r.lock()()
+	now := memoryNow()
+	removed := false
+	for id, invitation := range r.data.invitations.rows {
+		if invitation.EventID == eventID && invitation.ParticipantID == participantID && alive(invitation.DeletedAt) {
+			invitation.DeletedAt = softDeleted(now)
+			r.data.invitations.rows[id] = invitation
+			removed = true
+		}
+	}
+	if !removed {
+		return notFound("Invitation")
+	}
+	return nil
+}
+
+// CreateInvitationLink stores a new invitation link
+func (r *MemoryRepository) CreateInvitationLink(ctx context.Context, link *models.InvitationLink) error {
+	defer r.lock()()
+	for _, existing := range r.data.links.rows {
+		if existing.TokenHash == link.TokenHash {
+			return &Error{Kind: ErrConflict, Message: "An invitation link with this token hash already exists"}
+		}
+	}
+	if err := r.data.links.assign(&link.ID, "Invitation link"); err != nil {
+		return err
+	}
+	if link.Role == "" {
+		link.Role = models.AttendeeRequired
+	}
+	stamp(&link.CreatedAt, &link.UpdatedAt, memoryNow())
+	stored := *link
+	stored.Token = ""
+	r.data.links.rows[link.ID] = stored
+	return nil
+}
+
+// GetInvitationLinkByHash retrieves an invitation link by the hash of its token
+func (r *MemoryRepository) GetInvitationLinkByHash(ctx context.Context, hash string) (*models.InvitationLink, error) {
+	defer r.lock()()
+	links := r.data.links.where(func(l models.InvitationLink) bool { return hash != "" && l.TokenHash == hash })
+	if len(links) == 0 {
+		return nil, notFound("Invitation link")
+	}
+	return &links[0], nil
+}
+
+// GetInvitationLinks retrieves the invitation links of an event, revoked ones included
+func (r *MemoryRepository) GetInvitationLinks(ctx context.Context, eventID uint) ([]models.InvitationLink, error) {
+	defer r.lock()()
+	return r.data.links.where(func(l models.InvitationLink) bool { return l.EventID == eventID }), nil
+}
+
+// RevokeInvitationLink stops an invitation link of an event from being used.
+// Revoking a link twice keeps the time it was first revoked.
+func (r *MemoryRepository) RevokeInvitationLink(ctx context.Context, eventID, linkID uint) error {
+	defer r.lock()()
+	link, ok := r.data.links.rows[linkID]
+	if !ok || link.EventID != eventID {
+		return notFound("Invitation link")
+	}
+	if link.RevokedAt == nil {
+		now := memoryNow()
+		link.RevokedAt = &now
+		link.UpdatedAt = now
+		r.data.links.rows[linkID] = link
+	}
+	return nil
+}
+
+// ClaimInvitationLink creates a guest participant for an invitation link and
+// invites them to the event. Accounts do not prove they own their email, so
+// a link never answers as the account that has its email. A link that is
+// already linked returns its participant unchanged.
+func (r *MemoryRepository) ClaimInvitationLink(ctx context.Context, link *models.InvitationLink) (*models.Participant, error) {
+	defer r.lock()()
+	d := r.data
+	var participant models.Participant
+	err := d.atomically(func() error {
+		stored, ok := d.links.rows[link.ID]
+		if !ok {
+			return notFound("Invitation link")
+		}
+		*link = stored
+		if link.ParticipantID != nil {
+			found, ok := d.participants.rows[*link.ParticipantID]
+			if !ok || !alive(found.DeletedAt) {
+				return notFound("Participant")
+			}
+			participant = found
+			return nil
+		}
+
+		now := memoryNow()
+		participant = models.Participant{Name: link.Name, Email: link.Email, TimeZone: "UTC", Guest: true}
+		if err := d.createParticipant(&participant, now); err != nil {
+			return err
+		}
+		invitation := models.EventInvitation{EventID: link.EventID, ParticipantID: participant.ID, Role: link.Role}
+		if err := d.upsertInvitation(&invitation, true, now); err != nil {
+			return err
+		}
+
+		link.ParticipantID = &participant.ID
+		link.UsedAt = &now
+		link.UpdatedAt = now
+		d.links.rows[link.ID] = *link
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &participant, nil
+}
+
+// UpsertAvailability stores a participant's answer for a time slot of an
+// event, replacing any earlier answer. The replaced answer is kept in the
+// availability history.
+func (r *MemoryRepository) UpsertAvailability(ctx context.Context, eventID uint, availability *models.Availability) error {
+	defer r.lock()()
+	slot, ok := r.data.timeSlots.rows[availability.TimeSlotID]
+	if !ok || slot.EventID != eventID || !alive(slot.DeletedAt) {
+		return notFound("Time slot")
+	}
+	return r.data.atomically(func() error {
+		return r.data.upsertAvailability(availability, memoryNow())
+	})
+}
+
+// UpsertAvailabilities stores several answers for an event, all or none.
+// Every answer must reference a time slot of the event.
+func (r *MemoryRepository) UpsertAvailabilities(ctx context.Context, eventID uint, availabilities []models.Availability) error {
+	defer r.lock()()
+	d := r.data
+	eventSlots := make(map[uint]bool)
+	for _, slot := range d.eventTimeSlots(eventID) {
+		eventSlots[slot.ID] = true
+	}
+
+	now := memoryNow()
+	return d.atomically(func() error {
+		for i := range availabilities {
+			if !eventSlots[availabilities[i].TimeSlotID] {
+				return &Error{Kind: ErrValidation, Message: fmt.Sprintf("Time slot %d does not belong to event %d", availabilities[i].TimeSlotID, eventID)}
+			}
+			if err := d.upsertAvailability(&availabilities[i], now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// upsertAvailability inserts or replaces an answer, recording the previous
+// answer in the history
+func (d *memoryData) upsertAvailability(availability *models.Availability, now time.Time) error {
+	availability.Normalize()
+	stamp(&availability.CreatedAt, &availability.UpdatedAt, now)
+
+	for id, existing := range d.availabilities.rows {
+		if existing.ParticipantID != availability.ParticipantID || existing.TimeSlotID != availability.TimeSlotID {
+			continue
+		}
+		// A deleted answer is revived without being recorded, as it was withdrawn
+		if alive(existing.DeletedAt) {
+			history := models.AvailabilityHistory{
+				AvailabilityID: existing.ID,
+				ParticipantID:  existing.ParticipantID,
+				TimeSlotID:     existing.TimeSlotID,
+				Status:         existing.Status,
+				Weight:         existing.Weight,
+				IsAvailable:    existing.IsAvailable,
+				AnsweredAt:     existing.UpdatedAt,
+				CreatedAt:      now,
+			}
+			if err := d.history.assign(&history.ID, "Availability history"); err != nil {
+				return err
+			}
+			d.history.rows[history.ID] = history
+		}
+
+		availability.ID = id
+		availability.CreatedAt = existing.CreatedAt
+		existing.Status = availability.Status
+		existing.Weight = availability.Weight
+		existing.IsAvailable = availability.IsAvailable
+		existing.UpdatedAt = availability.UpdatedAt
+		existing.DeletedAt = availability.DeletedAt
+		d.availabilities.rows[id] = existing
+		return nil
+	}
+
+	if err := d.availabilities.assign(&availability.ID, "Availability"); err != nil {
+		return err
+	}
+	d.availabilities.rows[availability.ID] = *availability
+	return nil
+}
+
+// eventAvailabilities returns the answers given for the time slots of an event
+func (d *memoryData) eventAvailabilities(eventID uint) []models.Availability {
+	return d.availabilities.where(func(a models.Availability) bool {
+		slot, ok := d.timeSlots.rows[a.TimeSlotID]
+		return ok && slot.EventID == eventID && alive(slot.DeletedAt) && alive(a.DeletedAt)
+	})
+}
+
+// GetEventAvailabilities retrieves all answers given for the time slots of an event
+func (r *MemoryRepository) GetEventAvailabilities(ctx context.Context, eventID uint) ([]models.Availability, error) {
+	defer r.lock()()
+	return r.data.eventAvailabilities(eventID), nil
+}
+
+// GetAvailabilityHistory returns the superseded answers of a participant for
+// the time slots of an event, most recent first
+func (r *MemoryRepository) GetAvailabilityHistory(ctx context.Context, eventID, participantID uint) ([]models.AvailabilityHistory, error) {
+	defer r.lock()()
+	d := r.data
+	history := d.history.where(func(h models.AvailabilityHistory) bool {
+		slot, ok := d.timeSlots.rows[h.TimeSlotID]
+		return ok && slot.EventID == eventID && h.ParticipantID == participantID
+	})
+	slices.SortStableFunc(history, func(a, b models.AvailabilityHistory) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ID, a.ID)
+	})
+	return history, nil
+}
+
+// GetTimeSlotRecommendations returns the time slots of an event ranked by
+// score. Answers of deleted participants are left out.
+func (r *MemoryRepository) GetTimeSlotRecommendations(ctx context.Context, eventID uint) ([]models.TimeSlotRecommendation, error) {
+	defer r.lock()()
+	d := r.data
+	participants := make(map[uint]models.Participant)
+	var answers []models.Availability
+	for _, answer := range d.eventAvailabilities(eventID) {
+		participant, ok := d.participants.rows[answer.ParticipantID]
+		if !ok || !alive(participant.DeletedAt) {
+			continue
+		}
+		answers = append(answers, answer)
+		participants[participant.ID] = participant
+	}
+
+	invitations := d.eventInvitations(eventID)
+	for _, invitation := range invitations {
+		if invitation.Participant != nil {
+			participants[invitation.ParticipantID] = *invitation.Participant
+		}
+	}
+
+	return scheduling.BuildRecommendations(d.eventTimeSlots(eventID), answers, participants, invitations), nil
+}
+
+// CreateParticipant creates a new participant
+func (r *MemoryRepository) CreateParticipant(ctx context.Context, participant *models.Participant) error {
+	defer r.lock()()
+	return r.data.createParticipant(participant, memoryNow())
+}
+
+// createParticipant stores a new participant, whose email must be unused
+// unless one of them is a guest. Deleted participants keep their email, as in
+// the database.
+func (d *memoryData) createParticipant(participant *models.Participant, now time.Time) error {
+	for _, existing := range d.participants.rows {
+		if existing.Email == participant.Email && !existing.Guest && !participant.Guest {
+			return &Error{Kind: ErrConflict, Message: "A participant with this email already exists"}
+		}
+	}
+	if err := d.participants.assign(&participant.ID, "Participant"); err != nil {
+		return err
+	}
+	if participant.TimeZone == "" {
+		participant.TimeZone = "UTC"
+	}
+	stamp(&participant.CreatedAt, &participant.UpdatedAt, now)
+	stored := *participant
+	stored.APIKey = ""
+	d.participants.rows[participant.ID] = stored
+	return nil
+}
+
+// GetParticipant retrieves a participant by ID
+func (r *MemoryRepository) GetParticipant(ctx context.Context, id uint) (*models.Participant, error) {
+	defer r.lock()()
+	participant, ok := r.data.participants.rows[id]
+	if !ok || !alive(participant.DeletedAt) {
+		return nil, notFound("Participant")
+	}
+	return &participant, nil
+}
+
+// ListParticipants returns a page of participants, and the cursor of the
+// next page
+func (r *MemoryRepository) ListParticipants(ctx context.Context, filter ParticipantFilter) ([]models.Participant, string, error) {
+	ks, err := resolveKeyset(filter.ListOptions, participantSorts, defaultParticipantSort)
+	if err != nil {
+		return nil, "", err
+	}
+
+	defer r.lock()()
+	d := r.data
+	visible := map[uint]bool{filter.VisibleTo: true}
+	for _, e := range d.events.rows {
+		if !alive(e.DeletedAt) || e.OrganizerId != filter.VisibleTo && !d.invited(e.ID, filter.VisibleTo) {
+			continue
+		}
+		visible[e.OrganizerId] = true
+		for _, invitation := range d.invitations.rows {
+			if invitation.EventID == e.ID && alive(invitation.DeletedAt) {
+				visible[invitation.ParticipantID] = true
+			}
+		}
+	}
+
+	email := strings.ToLower(filter.EmailPrefix)
+	participants := d.participants.where(func(p models.Participant) bool {
+		return alive(p.DeletedAt) && (filter.ID == 0 || p.ID == filter.ID) &&
+			strings.HasPrefix(strings.ToLower(p.Email), email) &&
+			(visible[p.ID] || strings.ToLower(p.Email) == email)
+	})
+
+	limit := filter.limit()
+	id := func(p models.Participant) uint { return p.ID }
+	participants, next := ks.page(ks.seek(participants, limit, id), limit, id)
+	return participants, next, nil
+}
+
+// CreateNotification records a queued notification or an attempt to deliver one
+func (r *MemoryRepository) CreateNotification(ctx context.Context, notification *models.Notification) error {
+	defer r.lock()()
+	if err := r.data.notifications.assign(&notification.ID, "Notification"); err != nil {
+		return err
+	}
+	stamp(&notification.CreatedAt, nil, memoryNow())
+	r.data.notifications.rows[notification.ID] = *notification
+	return nil
+}
+
+// GetEventNotifications retrieves the notifications sent about an event, oldest first
+func (r *MemoryRepository) GetEventNotifications(ctx context.Context, eventID uint) ([]models.Notification, error) {
+	defer r.lock()()
+	return r.data.notifications.where(func(n models.Notification) bool { return n.EventID == eventID }), nil
+}
+
+// ClaimNotifications retrieves up to limit pending notifications that are due
+// at now and postpones them by lease. A notification whose attempt is never
+// recorded is sent once the lease ends.
+func (r *MemoryRepository) ClaimNotifications(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.Notification, error) {
+	defer r.lock()()
+	d := r.data
+	notifications := d.notifications.where(func(n models.Notification) bool {
+		return n.Status == models.NotificationPending && n.NextAttemptAt != nil && !n.NextAttemptAt.After(now)
+	})
+	slices.SortStableFunc(notifications, func(a, b models.Notification) int {
+		return a.NextAttemptAt.Compare(*b.NextAttemptAt)
+	})
+	notifications = limited(notifications, limit)
+
+	next := now.Add(lease)
+	for _, notification := range notifications {
+		notification.NextAttemptAt = &next
+		d.notifications.rows[notification.ID] = notification
+	}
+	return notifications, nil
+}
+
+// UpdateNotification records the outcome of sending a queued notification
+func (r *MemoryRepository) UpdateNotification(ctx context.Context, notification *models.Notification) error {
+	defer r.lock()()
+	stored, ok := r.data.notifications.rows[notification.ID]
+	if !ok {
+		return nil
+	}
+	stored.Recipient = notification.Recipient
+	stored.Subject = notification.Subject
+	stored.Status = notification.Status
+	stored.Error = notification.Error
+	stored.NextAttemptAt = notification.NextAttemptAt
+	r.data.notifications.rows[stored.ID] = stored
+	return nil
+}
+
+// CreateWebhook registers a webhook
+func (r *MemoryRepository) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	defer r.lock()()
+	if err := r.data.webhooks.assign(&webhook.ID, "Webhook"); err != nil {
+		return err
+	}
+	stamp(&webhook.CreatedAt, nil, memoryNow())
+	stored := *webhook
+	stored.EventTypes = slices.Clone(webhook.EventTypes)
+	r.data.webhooks.rows[webhook.ID] = stored
+	return nil
+}
+
+// GetParticipantWebhooks retrieves the webhooks a participant registered
+func (r *MemoryRepository) GetParticipantWebhooks(ctx context.Context, participantID uint) ([]models.Webhook, error) {
+	defer r.lock()()
+	return r.data.participantWebhooks(participantID), nil
+}
+
+// participantWebhooks returns the webhooks of a participant
+func (d *memoryData) participantWebhooks(participantID uint) []models.Webhook {
+	webhooks := d.webhooks.where(func(w models.Webhook) bool {
+		return w.ParticipantID == participantID && alive(w.DeletedAt)
+	})
+	for i := range webhooks {
+		webhooks[i].EventTypes = slices.Clone(webhooks[i].EventTypes)
+	}
+	return webhooks
+}
+
+// DeleteWebhook removes one of a participant's webhooks. Its pending
+// deliveries fail on their next attempt.
+func (r *MemoryRepository) DeleteWebhook(ctx context.Context, participantID, webhookID uint) error {
+	defer r.lock()()
+	webhook, ok := r.data.webhooks.rows[webhookID]
+	if !ok || webhook.ParticipantID != participantID || !alive(webhook.DeletedAt) {
+		return notFound("Webhook")
+	}
+	webhook.DeletedAt = softDeleted(memoryNow())
+	r.data.webhooks.rows[webhookID] = webhook
+	return nil
+}
+
+// GetWebhookDeliveries retrieves the delivery log of one of a participant's
+// webhooks, newest first
+func (r *MemoryRepository) GetWebhookDeliveries(ctx context.Context, participantID, webhookID uint) ([]models.WebhookDelivery, error) {
+	defer r.lock()()
+	webhook, ok := r.data.webhooks.rows[webhookID]
+	if !ok || webhook.ParticipantID != participantID {
+		return []models.WebhookDelivery{}, nil
+	}
+	deliveries := r.data.deliveries.where(func(d models.WebhookDelivery) bool { return d.WebhookID == webhookID })
+	slices.Reverse(deliveries)
+	return deliveries, nil
+}
+
+// CreateOutboxEvent stores a change for delivery to webhooks. Call it within
+// the transaction making the change.
+func (r *MemoryRepository) CreateOutboxEvent(ctx context.Context, event *models.OutboxEvent) error {
+	defer r.lock()()
+	if err := r.data.outbox.assign(&event.ID, "Outbox event"); err != nil {
+		return err
+	}
+	stamp(&event.CreatedAt, nil, memoryNow())
+	r.data.outbox.rows[event.ID] = *event
+	return nil
+}
+
+// DispatchOutboxEvents creates a pending delivery for every webhook subscribed
+// to up to limit undispatched outbox events, oldest first, and returns how
+// many events were dispatched
+func (r *MemoryRepository) DispatchOutboxEvents(ctx context.Context, limit int) (int, error) {
+	defer r.lock()()
+	d := r.data
+	events := limited(d.outbox.where(func(e models.OutboxEvent) bool { return e.DispatchedAt == nil }), limit)
+
+	now := memoryNow()
+	err := d.atomically(func() error {
+		for _, event := range events {
+			for _, webhook := range d.participantWebhooks(event.OrganizerID) {
+				if !webhook.Subscribes(event.Type) {
+					continue
+				}
+				delivery := models.WebhookDelivery{
+					WebhookID:     webhook.ID,
+					OutboxEventID: event.ID,
+					EventType:     event.Type,
+					Payload:       event.Payload,
+					Status:        models.WebhookDeliveryPending,
+					NextAttemptAt: now,
+					CreatedAt:     now,
+					UpdatedAt:     now,
+				}
+				if err := d.deliveries.assign(&delivery.ID, "Webhook delivery"); err != nil {
+					return err
+				}
+				d.deliveries.rows[delivery.ID] = delivery
+			}
+
+			event.DispatchedAt = &now
+			d.outbox.rows[event.ID] = event
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(events), nil
+}
+
+// ClaimWebhookDeliveries retrieves up to limit pending deliveries that are due
+// at now, with their webhooks, and postpones their next attempt by lease.
+// A delivery whose attempt is never recorded is retried once the lease ends.
+func (r *MemoryRepository) ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	defer r.lock()()
+	d := r.data
+	deliveries := d.deliveries.where(func(delivery models.WebhookDelivery) bool {
+		return delivery.Status == models.WebhookDeliveryPending && !delivery.NextAttemptAt.After(now)
+	})
+	slices.SortStableFunc(deliveries, func(a, b models.WebhookDelivery) int {
+		return a.NextAttemptAt.Compare(b.NextAttemptAt)
+	})
+	deliveries = limited(deliveries, limit)
+
+	updated := memoryNow()
+	for i := range deliveries {
+		stored := deliveries[i]
+		stored.NextAttemptAt = now.Add(lease)
+		stored.UpdatedAt = updated
+		d.deliveries.rows[stored.ID] = stored
+
+		// Deleted webhooks are left out, so their deliveries come back without one
+		if webhook, ok := d.webhooks.rows[deliveries[i].WebhookID]; ok && alive(webhook.DeletedAt) {
+			webhook.EventTypes = slices.Clone(webhook.EventTypes)
+			deliveries[i].Webhook = &webhook
+		}
+	}
+	return deliveries, nil
+}
+
+// UpdateWebhookDelivery records the outcome of a delivery attempt
+func (r *MemoryRepository) UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	defer r.lock()()
+	stored, ok := r.data.deliveries.rows[delivery.ID]
+	if !ok {
+		return nil
+	}
+	delivery.UpdatedAt = memoryNow()
+	stored.Status = delivery.Status
+	stored.Attempts = delivery.Attempts
+	stored.NextAttemptAt = delivery.NextAttemptAt
+	stored.LastAttemptAt = delivery.LastAttemptAt
+	stored.ResponseStatus = delivery.ResponseStatus
+	stored.Error = delivery.Error
+	stored.DeliveredAt = delivery.DeliveredAt
+	stored.UpdatedAt = delivery.UpdatedAt
+	r.data.deliveries.rows[delivery.ID] = stored
+	return nil
+}
+
+// ClaimJob leases the named job to owner until now+lease if it is due and
+// no other server holds it, and returns nil otherwise. A job is due the
+// first time it is claimed.
+func (r *MemoryRepository) ClaimJob(ctx context.Context, name, owner string, now time.Time, lease time.Duration) (*models.Job, error) {
+	defer r.lock()()
+	job, ok := r.data.jobs[name]
+	if !ok {
+		job = models.Job{Name: name, NextRunAt: now, UpdatedAt: memoryNow()}
+	}
+	if job.NextRunAt.After(now) || (job.LockedUntil != nil && job.LockedUntil.After(now)) {
+		r.data.jobs[name] = job
+		return nil, nil
+	}
+
+	lockedUntil := now.Add(lease)
+	job.LockedBy = owner
+	job.LockedUntil = &lockedUntil
+	job.UpdatedAt = now
+	r.data.jobs[name] = job
+	return &job, nil
+}
+
+// FinishJob records the outcome of a run and releases the job's lease,
+// provided the job's lease was not taken over meanwhile
+func (r *MemoryRepository) FinishJob(ctx context.Context, job *models.Job) error {
+	defer r.lock()()
+	stored, ok := r.data.jobs[job.Name]
+	if !ok || stored.LockedBy != job.LockedBy {
+		return nil
+	}
+	stored.NextRunAt = job.NextRunAt
+	stored.LastRunAt = job.LastRunAt
+	stored.LastError = job.LastError
+	stored.LockedBy = ""
+	stored.LockedUntil = nil
+	stored.UpdatedAt = memoryNow()
+	r.data.jobs[job.Name] = stored
+	return nil
+}
+
+// pollsByDeadline returns up to limit events for which keep is true, with
+// their time slots and invitations, earliest poll deadline first
+func (d *memoryData) pollsByDeadline(keep func(models.Event) bool, limit int) []models.Event {
+	events := d.events.where(func(e models.Event) bool {
+		return alive(e.DeletedAt) && e.Status == models.EventPolling && e.PollDeadline != nil && keep(e)
+	})
+	slices.SortStableFunc(events, func(a, b models.Event) int {
+		return a.PollDeadline.Compare(*b.PollDeadline)
+	})
+	events = limited(events, limit)
+	for i := range events {
+		events[i].TimeSlots = d.eventTimeSlots(events[i].ID)
+		events[i].Invitations = d.eventInvitations(events[i].ID)
+	}
+	return events
+}
+
+// GetExpiredPolls retrieves up to limit polling events whose poll deadline
+// has passed at now, oldest deadline first
+func (r *MemoryRepository) GetExpiredPolls(ctx context.Context, now time.Time, limit int) ([]models.Event, error) {
+	defer r.lock()()
+	return r.data.pollsByDeadline(func(e models.Event) bool {
+		return !e.PollDeadline.After(now)
+	}, limit), nil
+}
+
+// ClosePoll stores the outcome of an event's poll, its status, confirmed
+// time slot and decision, if it is still polling and its deadline has passed
+// at now, and reports whether it did
+func (r *MemoryRepository) ClosePoll(ctx context.Context, event *models.Event, now time.Time) (bool, error) {
+	defer r.lock()()
+	stored, ok := r.data.events.rows[event.ID]
+	if !ok || !alive(stored.DeletedAt) || stored.Status != models.EventPolling ||
+		stored.PollDeadline == nil || stored.PollDeadline.After(now) {
+		return false, nil
+	}
+	setLifecycle(&stored, event, now)
+	r.data.events.rows[event.ID] = stored
+	return true, nil
+}
+
+// GetPollsToRemind retrieves up to limit polling events whose invitees have
+// not been reminded yet and whose poll deadline falls between now and
+// deadlineBefore
+func (r *MemoryRepository) GetPollsToRemind(ctx context.Context, now, deadlineBefore time.Time, limit int) ([]models.Event, error) {
+	defer r.lock()()
+	return r.data.pollsByDeadline(func(e models.Event) bool {
+		return e.RemindedAt == nil && e.PollDeadline.After(now) && e.PollDeadline.Before(deadlineBefore)
+	}, limit), nil
+}
+
+// MarkReminded records when an event's invitees were reminded of its
+// deadline and reports whether it did. It does nothing when they already were.
+func (r *MemoryRepository) MarkReminded(ctx context.Context, eventID uint, at time.Time) (bool, error) {
+	defer r.lock()()
+	event, ok := r.data.events.rows[eventID]
+	if !ok || !alive(event.DeletedAt) || event.RemindedAt != nil {
+		return false, nil
+	}
+	event.RemindedAt = &at
+	event.UpdatedAt = memoryNow()
+	r.data.events.rows[eventID] = event
+	return true, nil
+}
+
+// PurgeDeleted permanently removes rows that were soft-deleted before the
+// given time and returns how many rows were removed. Deleted events take
+// their time slots, answers, invitations and notifications with them.
+// Webhook deliveries that were delivered or given up on, and outbox events
+// that were dispatched, before the given time are removed as well.
+func (r *MemoryRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	defer r.lock()()
+	d := r.data
+
+	deadEvents := make(map[uint]bool)
+	for id, event := range d.events.rows {
+		if deletedBefore(event.DeletedAt, before) {
+			deadEvents[id] = true
+		}
+	}
+	deadSlots := make(map[uint]bool)
+	for id, slot := range d.timeSlots.rows {
+		if deletedBefore(slot.DeletedAt, before) || deadEvents[slot.EventID] {
+			deadSlots[id] = true
+		}
+	}
+	deadWebhooks := make(map[uint]bool)
+	for id, webhook := range d.webhooks.rows {
+		if deletedBefore(webhook.DeletedAt, before) {
+			deadWebhooks[id] = true
+		}
+	}
+
+	var purged int64
+	purged += d.availabilities.deleteWhere(func(a models.Availability) bool {
+		return deletedBefore(a.DeletedAt, before) || deadSlots[a.TimeSlotID]
+	})
+	purged += d.history.deleteWhere(func(h models.AvailabilityHistory) bool { return deadSlots[h.TimeSlotID] })
+	purged += d.invitations.deleteWhere(func(i models.EventInvitation) bool {
+		return deletedBefore(i.DeletedAt, before) || deadEvents[i.EventID]
+	})
+	purged += d.exceptions.deleteWhere(func(x models.OccurrenceException) bool {
+		return deletedBefore(x.DeletedAt, before) || deadEvents[x.EventID]
+	})
+	purged += d.links.deleteWhere(func(l models.InvitationLink) bool { return deadEvents[l.EventID] })
+	purged += d.notifications.deleteWhere(func(n models.Notification) bool { return deadEvents[n.EventID] })
+	purged += d.timeSlots.deleteWhere(func(s models.TimeSlot) bool { return deadSlots[s.ID] })
+	purged += d.events.deleteWhere(func(e models.Event) bool { return deadEvents[e.ID] })
+	purged += d.deliveries.deleteWhere(func(w models.WebhookDelivery) bool {
+		return deadWebhooks[w.WebhookID] || (w.Status != models.WebhookDeliveryPending && w.UpdatedAt.Before(before))
+	})
+	purged += d.outbox.deleteWhere(func(e models.OutboxEvent) bool {
+		return e.DispatchedAt != nil && e.DispatchedAt.Before(before)
+	})
+	purged += d.webhooks.deleteWhere(func(w models.Webhook) bool { return deadWebhooks[w.ID] })
+	purged += d.apiKeys.deleteWhere(func(k models.APIKey) bool { return deletedBefore(k.DeletedAt, before) })
+	return purged, nil
+}
+
+// CreateAPIKey stores a new API key
+func (r *MemoryRepository) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	defer r.lock()()
+	d := r.data
+	if _, ok := d.participants.rows[key.ParticipantID]; !ok {
+		return missingReference("API key", "participant id")
+	}
+	for _, existing := range d.apiKeys.rows {
+		if existing.Hash == key.Hash {
+			return &Error{Kind: ErrConflict, Message: "An API key with this hash already exists"}
+		}
+	}
+	if err := d.apiKeys.assign(&key.ID, "API key"); err != nil {
+		return err
+	}
+	stamp(&key.CreatedAt, nil, memoryNow())
+	stored := *key
+	stored.Key = ""
+	stored.Participant = nil
+	d.apiKeys.rows[key.ID] = stored
+	return nil
+}
+
+// GetAPIKeyByHash retrieves an API key with its participant by the hash of the key
+func (r *MemoryRepository) GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	defer r.lock()()
+	keys := r.data.apiKeys.where(func(k models.APIKey) bool { return k.Hash == hash && alive(k.DeletedAt) })
+	if len(keys) == 0 {
+		return nil, notFound("API key")
+	}
+	key := keys[0]
+	if participant, ok := r.data.participants.rows[key.ParticipantID]; ok && alive(participant.DeletedAt) {
+		key.Participant = &participant
+	}
+	return &key, nil
+}
+
+// GetParticipantAPIKeys retrieves the API keys of a participant
+func (r *MemoryRepository) GetParticipantAPIKeys(ctx context.Context, participantID uint) ([]models.APIKey, error) {
+	defer r.lock()()
+	return r.data.apiKeys.where(func(k models.APIKey) bool {
+		return k.ParticipantID == participantID && alive(k.DeletedAt)
+	}), nil
+}
+
+// DeleteAPIKey revokes one of a participant's API keys
+func (r *MemoryRepository) DeleteAPIKey(ctx context.Context, participantID, keyID uint) error {
+	defer r.lock()()
+	key, ok := r.data.apiKeys.rows[keyID]
+	if !ok || key.ParticipantID != participantID || !alive(key.DeletedAt) {
+		return notFound("API key")
+	}
+	key.DeletedAt = softDeleted(memoryNow())
+	r.data.apiKeys.rows[keyID] = key
+	return nil
+}
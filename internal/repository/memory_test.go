@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+)
+
+func TestMemoryTransactionRollsBack(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	failed := errors.New("failed")
+
+	var rolledBack models.Participant
+	err := repo.Transaction(ctx, func(tx Repository) error {
+		rolledBack = models.Participant{Name: "Ada", Email: "ada@example.com"}
+		if err := tx.CreateParticipant(ctx, &rolledBack); err != nil {
+			return err
+		}
+		return failed
+	})
+	require.ErrorIs(t, err, failed)
+
+	_, err = repo.GetParticipant(ctx, rolledBack.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// The email is free again, and like a sequence the ID is not reused
+	participant := models.Participant{Name: "Ada", Email: "ada@example.com"}
+	require.NoError(t, repo.CreateParticipant(ctx, &participant))
+	assert.Greater(t, participant.ID, rolledBack.ID)
+}
+
+func TestMemoryTransactionRollsBackOnPanic(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+
+	assert.Panics(t, func() {
+		repo.Transaction(ctx, func(tx Repository) error {
+			tx.CreateParticipant(ctx, &models.Participant{Name: "Ada", Email: "ada@example.com"})
+			panic("boom")
+		})
+	})
+
+	// The lock was released and the participant not kept
+	participants, _, err := repo.ListParticipants(ctx, ParticipantFilter{EmailPrefix: "ada@example.com"})
+	require.NoError(t, err)
+	assert.Empty(t, participants)
+}
+
+func TestMemoryConcurrentWrites(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	event := models.Event{Title: "Planning", OrganizerId: 1, Duration: 60}
+	require.NoError(t, repo.CreateEvent(ctx, &event))
+
+	const writers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			participant := models.Participant{Name: "P", Email: fmt.Sprintf("p%d@example.com", i)}
+			assert.NoError(t, repo.CreateParticipant(ctx, &participant))
+			assert.NoError(t, repo.Transaction(ctx, func(tx Repository) error {
+				return tx.InviteParticipant(ctx, &models.EventInvitation{EventID: event.ID, ParticipantID: participant.ID})
+			}))
+		}(i)
+	}
+	wg.Wait()
+
+	invitations, err := repo.GetInvitations(ctx, event.ID)
+	require.NoError(t, err)
+	assert.Len(t, invitations, writers)
+}
+
+func TestMemoryReturnsCopies(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	start := time.Date(2030, 6, 3, 9, 0, 0, 0, time.UTC)
+	event := models.Event{
+		Title:       "Planning",
+		OrganizerId: 1,
+		Duration:    60,
+		TimeSlots:   []models.TimeSlot{{StartTime: start, EndTime: start.Add(time.Hour)}},
+	}
+	require.NoError(t, repo.CreateEvent(ctx, &event))
+	assert.Equal(t, models.EventDraft, event.Status, "defaults are filled in like the database's")
+	require.NotZero(t, event.TimeSlots[0].ID)
+
+	loaded, err := repo.GetEvent(ctx, event.ID)
+	require.NoError(t, err)
+	loaded.Title = "Changed"
+	loaded.TimeSlots[0].EndTime = start
+
+	again, err := repo.GetEvent(ctx, event.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Planning", again.Title, "only UpdateEvent changes the stored event")
+	assert.Equal(t, start.Add(time.Hour), again.TimeSlots[0].EndTime)
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"gorm.io/gorm"
+)
+
+// Page sizes of listings
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 100
+)
+
+// ListOptions selects a page of a listing. Listings are ordered by a sort
+// field and then by ID, so records with the same value keep a stable order.
+type ListOptions struct {
+	Limit  int    // at most this many records, DefaultPageLimit when 0
+	Cursor string // continues after the last record of the page that returned it
+	Sort   string // field to order by, with a "-" prefix for descending order
+}
+
+// limit returns the page size asked for, within MaxPageLimit
+func (o ListOptions) limit() int {
+	if o.Limit <= 0 {
+		return DefaultPageLimit
+	}
+	if o.Limit > MaxPageLimit {
+		return MaxPageLimit
+	}
+	return o.Limit
+}
+
+// EventFilter selects the events of a listing
+type EventFilter struct {
+	ListOptions
+	VisibleTo   uint                 // only events this participant organizes or is invited to
+	OrganizerID uint                 // only events this participant organizes, unless 0
+	Statuses    []models.EventStatus // only events in one of these statuses, unless empty
+	From        *time.Time           // only events with a time slot ending after From
+	To          *time.Time           // only events with a time slot starting before To
+}
+
+// ParticipantFilter selects the participants of a listing. Participants
+// sharing no event with VisibleTo are only listed when their email is
+// EmailPrefix in full, so the directory cannot be browsed.
+type ParticipantFilter struct {
+	ListOptions
+	VisibleTo   uint   // only this participant and those organizing or invited to an event with them
+	ID          uint   // only the participant with this ID, unless 0
+	EmailPrefix string // only participants whose email starts with it, ignoring case
+}
+
+// sortField is a field a listing of T can be sorted by
+type sortField[T any] struct {
+	column string
+	value  func(T) any // the field of a record, a string or a time.Time
+}
+
+// Fields the listings can be sorted by
+var (
+	eventSorts = map[string]sortField[models.Event]{
+		"created_at": {"created_at", func(e models.Event) any { return e.CreatedAt }},
+		"title":      {"title", func(e models.Event) any { return e.Title }},
+	}
+	participantSorts = map[string]sortField[models.Participant]{
+		"created_at": {"created_at", func(p models.Participant) any { return p.CreatedAt }},
+		"name":       {"name", func(p models.Participant) any { return p.Name }},
+		"email":      {"email", func(p models.Participant) any { return p.Email }},
+	}
+	timeSlotSorts = map[string]sortField[models.TimeSlot]{
+		"start_time": {"start_time", func(s models.TimeSlot) any { return s.StartTime }},
+		"end_time":   {"end_time", func(s models.TimeSlot) any { return s.EndTime }},
+		"created_at": {"created_at", func(s models.TimeSlot) any { return s.CreatedAt }},
+	}
+)
+
+// Default sort orders of the listings
+const (
+	defaultEventSort       = "created_at"
+	defaultParticipantSort = "created_at"
+	defaultTimeSlotSort    = "start_time"
+)
+
+// cursor is the position of the last record of a page: its sort order, its
+// value of the sort field and its ID
+type cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    uint   `json:"id"`
+}
+
+// encodeCursor returns the opaque cursor of the next page after record
+func encodeCursor[T any](sortOrder string, field sortField[T], record T, id uint) string {
+	c := cursor{Sort: sortOrder, ID: id}
+	switch v := field.value(record).(type) {
+	case time.Time:
+		c.Value = v.UTC().Format(time.RFC3339Nano)
+	case string:
+		c.Value = v
+	}
+	encoded, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+// keyset is a sort order resolved for a listing and the position after which
+// its page starts
+type keyset[T any] struct {
+	order      string // the sort order, such as "-created_at"
+	field      sortField[T]
+	descending bool
+	after      *cursor
+	afterValue any // the cursor's value of the sort field, typed like the field
+}
+
+// resolveKeyset checks the sort order and cursor of a listing against the
+// fields it can be sorted by
+func resolveKeyset[T any](opts ListOptions, fields map[string]sortField[T], defaultSort string) (*keyset[T], error) {
+	order := opts.Sort
+	if order == "" {
+		order = defaultSort
+	}
+	name := strings.TrimPrefix(order, "-")
+	field, ok := fields[name]
+	if !ok {
+		return nil, &Error{Kind: ErrValidation, Message: fmt.Sprintf("Cannot sort by %q; sort by %s", name, sortNames(fields))}
+	}
+	ks := &keyset[T]{order: order, field: field, descending: strings.HasPrefix(order, "-")}
+	if opts.Cursor == "" {
+		return ks, nil
+	}
+
+	invalid := &Error{Kind: ErrValidation, Message: "The cursor is invalid or was issued for another sort order"}
+	decoded, err := base64.RawURLEncoding.DecodeString(opts.Cursor)
+	if err != nil {
+		return nil, invalid
+	}
+	var c cursor
+	if err := json.Unmarshal(decoded, &c); err != nil || c.Sort != order {
+		return nil, invalid
+	}
+	ks.after = &c
+	ks.afterValue = c.Value
+	if _, isTime := field.value(*new(T)).(time.Time); isTime {
+		t, err := time.Parse(time.RFC3339Nano, c.Value)
+		if err != nil {
+			return nil, invalid
+		}
+		ks.afterValue = t
+	}
+	return ks, nil
+}
+
+// sortNames lists the fields of a listing for error messages
+func sortNames[T any](fields map[string]sortField[T]) string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// apply orders a query by the keyset and starts it after the cursor. table
+// qualifies the columns, for queries that join other tables. Text is sorted
+// and compared in the C collation, byte by byte, whatever the database's
+// default, so pages follow the same order in every backend.
+func (ks *keyset[T]) apply(query *gorm.DB, table string, limit int) *gorm.DB {
+	column := table + "." + ks.field.column
+	if _, isString := ks.field.value(*new(T)).(string); isString {
+		column += ` COLLATE "C"`
+	}
+	id := table + ".id"
+	direction, compare := "ASC", ">"
+	if ks.descending {
+		direction, compare = "DESC", "<"
+	}
+	if ks.after != nil {
+		query = query.Where(fmt.Sprintf("(%s, %s) %s (?, ?)", column, id, compare), ks.afterValue, ks.after.ID)
+	}
+	// One record more than asked for tells whether there is a next page
+	return query.Order(fmt.Sprintf("%s %s, %s %s", column, direction, id, direction)).Limit(limit + 1)
+}
+
+// page trims the extra record apply asked for and returns the cursor of the
+// page after it, or "" on the last page
+func (ks *keyset[T]) page(records []T, limit int, id func(T) uint) ([]T, string) {
+	if len(records) <= limit {
+		return records, ""
+	}
+	records = records[:limit]
+	last := records[limit-1]
+	return records, encodeCursor(ks.order, ks.field, last, id(last))
+}
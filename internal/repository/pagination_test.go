@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestKeysetCursorRoundTrip(t *testing.T) {
+	created := time.Date(2030, 6, 1, 9, 30, 0, 123456000, time.UTC)
+	events := []models.Event{{ID: 4, CreatedAt: created.Add(time.Hour)}, {ID: 3, CreatedAt: created}, {ID: 2, CreatedAt: created}}
+
+	ks, err := resolveKeyset(ListOptions{Sort: "-created_at"}, eventSorts, defaultEventSort)
+	require.NoError(t, err)
+	assert.True(t, ks.descending)
+
+	page, next := ks.page(events, 2, func(e models.Event) uint { return e.ID })
+	assert.Len(t, page, 2)
+	require.NotEmpty(t, next)
+
+	after, err := resolveKeyset(ListOptions{Sort: "-created_at", Cursor: next}, eventSorts, defaultEventSort)
+	require.NoError(t, err)
+	assert.Equal(t, uint(3), after.after.ID)
+	assert.Equal(t, created, after.afterValue, "time cursors keep their precision")
+
+	_, last := ks.page(events[2:], 2, func(e models.Event) uint { return e.ID })
+	assert.Empty(t, last, "the last page has no cursor")
+}
+
+func TestResolveKeysetRejects(t *testing.T) {
+	ks, err := resolveKeyset(ListOptions{Sort: "name"}, participantSorts, defaultParticipantSort)
+	require.NoError(t, err)
+	nameCursor := encodeCursor(ks.order, ks.field, models.Participant{Name: "Ada"}, 1)
+
+	tests := []struct {
+		name string
+		opts ListOptions
+	}{
+		{"unknown field", ListOptions{Sort: "-password"}},
+		{"malformed cursor", ListOptions{Cursor: "%%%"}},
+		{"cursor of another sort order", ListOptions{Sort: "-name", Cursor: nameCursor}},
+		{"cursor of another field", ListOptions{Sort: "created_at", Cursor: nameCursor}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := resolveKeyset(tt.opts, participantSorts, defaultParticipantSort)
+			assert.ErrorIs(t, err, ErrValidation)
+		})
+	}
+}
+
+func TestListOptionsLimit(t *testing.T) {
+	assert.Equal(t, DefaultPageLimit, ListOptions{}.limit())
+	assert.Equal(t, 10, ListOptions{Limit: 10}.limit())
+	assert.Equal(t, MaxPageLimit, ListOptions{Limit: 1000}.limit())
+}
+
+func TestEscapeLike(t *testing.T) {
+	assert.Equal(t, `ada\_lovelace\%`, escapeLike("ada_lovelace%"))
+	assert.Equal(t, `back\\slash`, escapeLike(`back\slash`))
+}
+
+func TestKeysetApplyCollatesText(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+	after := encodeCursor("name", participantSorts["name"], models.Participant{Name: "Ada"}, 1)
+
+	tests := []struct {
+		name string
+		opts ListOptions
+		want []string
+	}{
+		{"text", ListOptions{Sort: "name", Cursor: after}, []string{
+			`(participants.name COLLATE "C", participants.id) > ($1, $2)`,
+			`ORDER BY participants.name COLLATE "C" ASC, participants.id ASC`,
+		}},
+		{"time", ListOptions{Sort: "-created_at"}, []string{
+			`ORDER BY participants.created_at DESC, participants.id DESC`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ks, err := resolveKeyset(tt.opts, participantSorts, defaultParticipantSort)
+			require.NoError(t, err)
+			stmt := ks.apply(db.Model(&models.Participant{}), "participants", 10).Find(&[]models.Participant{}).Statement
+			for _, want := range tt.want {
+				assert.Contains(t, stmt.SQL.String(), want)
+			}
+		})
+	}
+}
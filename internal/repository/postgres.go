@@ -1,14 +1,20 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/tusharsingune/meeting-scheduler/internal/config"
+	"github.com/tusharsingune/meeting-scheduler/internal/logger"
+	"github.com/tusharsingune/meeting-scheduler/internal/migrations"
 	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"github.com/tusharsingune/meeting-scheduler/internal/scheduling"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	"gorm.io/gorm/clause"
 )
 
 // PostgresRepository implements the Repository interface
@@ -18,11 +24,8 @@ type PostgresRepository struct {
 
 // NewPostgresDB creates a new PostgreSQL repository
 func NewPostgresDB(cfg config.DatabaseConfig) (Repository, error) {
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode)
-
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+	db, err := gorm.Open(postgres.Open(cfg.DSN()), &gorm.Config{
+		Logger: logger.NewGormLogger(logger.GetLogger()),
 		NowFunc: func() time.Time {
 			return time.Now().UTC()
 		},
@@ -41,15 +44,19 @@ func NewPostgresDB(cfg config.DatabaseConfig) (Repository, error) {
 	sqlDB.SetMaxOpenConns(100)
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
-	// Auto-migrate the schema
-	err = db.AutoMigrate(
-		&models.Event{},
-		&models.TimeSlot{},
-		&models.Participant{},
-		&models.Availability{},
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	if err := registerErrorTranslation(db); err != nil {
+		return nil, fmt.Errorf("failed to register error translation: %w", err)
+	}
+
+	// Bring the schema up to date unless deployments migrate separately
+	if cfg.MigrateOnStart {
+		migrator, err := migrations.New(sqlDB, logger.GetLogger())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load migrations: %w", err)
+		}
+		if _, err := migrator.Up(context.Background()); err != nil {
+			return nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
 	}
 
 	repo := &PostgresRepository{db: db}
@@ -57,104 +64,985 @@ func NewPostgresDB(cfg config.DatabaseConfig) (Repository, error) {
 	return repo, nil
 }
 
+// Transaction runs fn with a repository bound to a database transaction
+func (r *PostgresRepository) Transaction(ctx context.Context, fn func(Repository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&PostgresRepository{db: tx})
+	})
+}
+
 // CreateEvent creates a new event
-func (r *PostgresRepository) CreateEvent(event *models.Event) error {
-	return r.db.Create(event).Error
+func (r *PostgresRepository) CreateEvent(ctx context.Context, event *models.Event) error {
+	return r.db.WithContext(ctx).Create(event).Error
 }
 
 // GetEvent retrieves an event by ID
-func (r *PostgresRepository) GetEvent(id uint) (*models.Event, error) {
+func (r *PostgresRepository) GetEvent(ctx context.Context, id uint) (*models.Event, error) {
 	var event models.Event
-	if err := r.db.Preload("TimeSlots").First(&event, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("TimeSlots").Preload("Invitations.Participant").
+		Preload("Exceptions", orderExceptions).First(&event, id).Error; err != nil {
 		return nil, err
 	}
+	setConfirmedTimeSlot(&event)
 	return &event, nil
 }
 
-// UpdateEvent updates an existing event
-func (r *PostgresRepository) UpdateEvent(event *models.Event) error {
-	return r.db.Save(event).Error
+// setConfirmedTimeSlot points ConfirmedTimeSlot at the confirmed one of the
+// event's loaded time slots
+func setConfirmedTimeSlot(event *models.Event) {
+	if event.ConfirmedTimeSlotID == nil {
+		return
+	}
+	for i := range event.TimeSlots {
+		if event.TimeSlots[i].ID == *event.ConfirmedTimeSlotID {
+			event.ConfirmedTimeSlot = &event.TimeSlots[i]
+		}
+	}
+}
+
+// UpdateEvent stores the fields of an event its organizer edits: title,
+// description, organizer, duration, recurrence, time zone, poll deadline and
+// decision policy. Moving the poll deadline forgets that invitees were
+// reminded of it. The lifecycle is changed by TransitionEvent and ClosePoll,
+// and time slots and invitations are managed separately.
+func (r *PostgresRepository) UpdateEvent(ctx context.Context, event *models.Event) error {
+	now := time.Now().UTC()
+	result := r.db.WithContext(ctx).Model(&models.Event{}).Where("id = ?", event.ID).
+		Updates(map[string]interface{}{
+			"title":           event.Title,
+			"description":     event.Description,
+			"organizer_id":    event.OrganizerId,
+			"duration":        event.Duration,
+			"recurrence":      event.Recurrence,
+			"time_zone":       event.TimeZone,
+			"poll_deadline":   event.PollDeadline,
+			"decision_policy": event.DecisionPolicy,
+			"reminded_at":     gorm.Expr("CASE WHEN poll_deadline IS NOT DISTINCT FROM ? THEN reminded_at END", event.PollDeadline),
+			"updated_at":      now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return notFound("Event")
+	}
+	event.UpdatedAt = now
+	return nil
+}
+
+// TransitionEvent stores the lifecycle of an event, its status, confirmed
+// time slot and decision, if its status is still one of from, and reports
+// whether it did. Checking in the same statement keeps two requests from both
+// moving the event on from the status they loaded.
+func (r *PostgresRepository) TransitionEvent(ctx context.Context, event *models.Event, from ...models.EventStatus) (bool, error) {
+	now := time.Now().UTC()
+	result := r.db.WithContext(ctx).Model(&models.Event{}).
+		Where("id = ? AND status IN ?", event.ID, from).
+		Updates(lifecycleColumns(event, now))
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	event.UpdatedAt = now
+	return true, nil
+}
+
+// lifecycleColumns returns the columns of an event that its lifecycle
+// changes, with their values
+func lifecycleColumns(event *models.Event, now time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"status":                 event.Status,
+		"confirmed_time_slot_id": event.ConfirmedTimeSlotID,
+		"confirmed_at":           event.ConfirmedAt,
+		"decision_outcome":       event.DecisionOutcome,
+		"decision_reason":        event.DecisionReason,
+		"decided_at":             event.DecidedAt,
+		"updated_at":             now,
+	}
+}
+
+// DeleteEvent deletes an event by ID, together with its time slots and the
+// answers given for them
+func (r *PostgresRepository) DeleteEvent(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		slots := tx.Model(&models.TimeSlot{}).Select("id").Where("event_id = ?", id)
+		if err := tx.Where("time_slot_id IN (?)", slots).Delete(&models.Availability{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("event_id = ?", id).Delete(&models.TimeSlot{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Event{}, id).Error
+	})
+}
+
+// GetParticipantEvents retrieves the events a participant organizes, is
+// invited to or has answered
+func (r *PostgresRepository) GetParticipantEvents(ctx context.Context, participantID uint) ([]models.Event, error) {
+	invited := r.db.WithContext(ctx).Model(&models.EventInvitation{}).
+		Select("event_id").
+		Where("participant_id = ?", participantID)
+	answered := r.db.WithContext(ctx).Model(&models.TimeSlot{}).
+		Select("time_slots.event_id").
+		Joins("JOIN availabilities ON availabilities.time_slot_id = time_slots.id AND availabilities.deleted_at IS NULL").
+		Where("availabilities.participant_id = ?", participantID)
+
+	var events []models.Event
+	err := r.db.WithContext(ctx).Preload("TimeSlots").Preload("Invitations.Participant").Preload("Exceptions", orderExceptions).
+		Where("organizer_id = ? OR id IN (?) OR id IN (?)", participantID, invited, answered).
+		Order("id").
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range events {
+		setConfirmedTimeSlot(&events[i])
+	}
+	return events, nil
+}
+
+// ListEvents returns a page of the events a participant can see, and the
+// cursor of the next page
+func (r *PostgresRepository) ListEvents(ctx context.Context, filter EventFilter) ([]models.Event, string, error) {
+	ks, err := resolveKeyset(filter.ListOptions, eventSorts, defaultEventSort)
+	if err != nil {
+		return nil, "", err
+	}
+
+	invited := r.db.WithContext(ctx).Model(&models.EventInvitation{}).
+		Select("event_id").
+		Where("participant_id = ?", filter.VisibleTo)
+	query := r.db.WithContext(ctx).Preload("TimeSlots").Preload("Invitations.Participant").Preload("Exceptions", orderExceptions).
+		Where("events.organizer_id = ? OR events.id IN (?)", filter.VisibleTo, invited)
+	if filter.OrganizerID != 0 {
+		query = query.Where("events.organizer_id = ?", filter.OrganizerID)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("events.status IN ?", filter.Statuses)
+	}
+	if filter.From != nil || filter.To != nil {
+		slots := r.db.WithContext(ctx).Model(&models.TimeSlot{}).Select("event_id")
+		if filter.From != nil {
+			slots = slots.Where("end_time > ?", *filter.From)
+		}
+		if filter.To != nil {
+			slots = slots.Where("start_time < ?", *filter.To)
+		}
+		query = query.Where("events.id IN (?)", slots)
+	}
+
+	limit := filter.limit()
+	var events []models.Event
+	if err := ks.apply(query, "events", limit).Find(&events).Error; err != nil {
+		return nil, "", err
+	}
+	for i := range events {
+		setConfirmedTimeSlot(&events[i])
+	}
+	events, next := ks.page(events, limit, func(e models.Event) uint { return e.ID })
+	return events, next, nil
+}
+
+// orderExceptions preloads the exceptions of an event in the order of their occurrences
+func orderExceptions(db *gorm.DB) *gorm.DB {
+	return db.Order("occurrence_start")
+}
+
+// UpsertOccurrenceException creates the exception for an occurrence or replaces the existing one
+func (r *PostgresRepository) UpsertOccurrenceException(ctx context.Context, exception *models.OccurrenceException) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "event_id"}, {Name: "occurrence_start"}},
+		DoUpdates: clause.AssignmentColumns([]string{"cancelled", "start_time", "end_time", "updated_at", "deleted_at"}),
+	}).Create(exception).Error
+}
+
+// DeleteOccurrenceException restores an occurrence to its scheduled time
+func (r *PostgresRepository) DeleteOccurrenceException(ctx context.Context, eventID, exceptionID uint) error {
+	result := r.db.WithContext(ctx).Where("event_id = ?", eventID).Delete(&models.OccurrenceException{}, exceptionID)
+	return deleted(result, "Occurrence exception")
+}
+
+// CreateTimeSlot adds a time slot to an event. It fails with ErrConflict if
+// the slot overlaps one the event already has.
+func (r *PostgresRepository) CreateTimeSlot(ctx context.Context, slot *models.TimeSlot) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		existing, err := lockTimeSlots(tx, slot.EventID)
+		if err != nil {
+			return err
+		}
+		if err := overlapConflict(*slot, existing); err != nil {
+			return err
+		}
+		return tx.Create(slot).Error
+	})
+}
+
+// CreateTimeSlots adds several time slots to an event in a single transaction,
+// skipping those that overlap a slot the event already has or one added before
+// them. It returns the slots that were created.
+func (r *PostgresRepository) CreateTimeSlots(ctx context.Context, eventID uint, slots []models.TimeSlot) ([]models.TimeSlot, error) {
+	var created []models.TimeSlot
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		existing, err := lockTimeSlots(tx, eventID)
+		if err != nil {
+			return err
+		}
+
+		created = nil
+		for _, slot := range slots {
+			if overlapsAny(slot, existing) {
+				continue
+			}
+			slot.EventID = eventID
+			created = append(created, slot)
+			existing = append(existing, slot)
+		}
+
+		if len(created) == 0 {
+			return nil
+		}
+		return tx.Create(&created).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// lockTimeSlots locks an event, so that concurrent requests cannot add
+// overlapping slots to it, and returns the time slots it has
+func lockTimeSlots(tx *gorm.DB, eventID uint) ([]models.TimeSlot, error) {
+	var event models.Event
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, eventID).Error; err != nil {
+		return nil, err
+	}
+	var slots []models.TimeSlot
+	if err := tx.Where("event_id = ?", eventID).Find(&slots).Error; err != nil {
+		return nil, err
+	}
+	return slots, nil
 }
 
-// DeleteEvent deletes an event by ID
-func (r *PostgresRepository) DeleteEvent(id uint) error {
-	return r.db.Delete(&models.Event{}, id).Error
+// overlapConflict returns an ErrConflict naming the first of the others that
+// a time slot overlaps, or nil if it overlaps none
+func overlapConflict(slot models.TimeSlot, others []models.TimeSlot) error {
+	for _, other := range others {
+		if slot.Overlaps(other) {
+			return &Error{Kind: ErrConflict, Message: fmt.Sprintf("Time slot overlaps time slot %d (%s to %s)", other.ID,
+				other.StartTime.UTC().Format(time.RFC3339), other.EndTime.UTC().Format(time.RFC3339))}
+		}
+	}
+	return nil
 }
 
-// CreateTimeSlot creates a new time slot
-func (r *PostgresRepository) CreateTimeSlot(slot *models.TimeSlot) error {
-	return r.db.Create(slot).Error
+// overlapsAny reports whether a time slot overlaps any of the others
+func overlapsAny(slot models.TimeSlot, others []models.TimeSlot) bool {
+	for _, other := range others {
+		if slot.Overlaps(other) {
+			return true
+		}
+	}
+	return false
 }
 
 // GetTimeSlots retrieves all time slots for an event
-func (r *PostgresRepository) GetTimeSlots(eventID uint) ([]models.TimeSlot, error) {
+func (r *PostgresRepository) GetTimeSlots(ctx context.Context, eventID uint) ([]models.TimeSlot, error) {
 	var slots []models.TimeSlot
-	if err := r.db.Where("event_id = ?", eventID).Find(&slots).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("event_id = ?", eventID).Find(&slots).Error; err != nil {
 		return nil, err
 	}
 	return slots, nil
 }
 
-// CreateAvailability creates a new availability record
-func (r *PostgresRepository) CreateAvailability(availability *models.Availability) error {
-	return r.db.Create(availability).Error
+// ListTimeSlots returns a page of the time slots of an event, and the cursor
+// of the next page
+func (r *PostgresRepository) ListTimeSlots(ctx context.Context, eventID uint, opts ListOptions) ([]models.TimeSlot, string, error) {
+	ks, err := resolveKeyset(opts, timeSlotSorts, defaultTimeSlotSort)
+	if err != nil {
+		return nil, "", err
+	}
+
+	limit := opts.limit()
+	var slots []models.TimeSlot
+	if err := ks.apply(r.db.WithContext(ctx).Where("event_id = ?", eventID), "time_slots", limit).Find(&slots).Error; err != nil {
+		return nil, "", err
+	}
+	slots, next := ks.page(slots, limit, func(s models.TimeSlot) uint { return s.ID })
+	return slots, next, nil
+}
+
+// InviteParticipant invites a participant to an event. Inviting someone who
+// is already invited updates their role and whether they co-organize it.
+func (r *PostgresRepository) InviteParticipant(ctx context.Context, invitation *models.EventInvitation) error {
+	if invitation.Role == "" {
+		invitation.Role = models.AttendeeRequired
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "event_id"}, {Name: "participant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "co_organizer", "updated_at", "deleted_at"}),
+	}).Create(invitation).Error
 }
 
-// GetTimeSlotRecommendations returns recommended time slots for an event
-func (r *PostgresRepository) GetTimeSlotRecommendations(eventID uint) ([]models.TimeSlotRecommendation, error) {
-	var recommendations []models.TimeSlotRecommendation
+// GetInvitations retrieves the invitations of an event with their participants
+func (r *PostgresRepository) GetInvitations(ctx context.Context, eventID uint) ([]models.EventInvitation, error) {
+	var invitations []models.EventInvitation
+	if err := r.db.WithContext(ctx).Preload("Participant").Where("event_id = ?", eventID).Order("id").Find(&invitations).Error; err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
 
-	// Get all time slots for the event
-	var timeSlots []models.TimeSlot
-	if err := r.db.Where("event_id = ?", eventID).Find(&timeSlots).Error; err != nil {
+// RemoveInvitation withdraws a participant's invitation to an event
+func (r *PostgresRepository) RemoveInvitation(ctx context.Context, eventID, participantID uint) error {
+	result := r.db.WithContext(ctx).Where("event_id = ? AND participant_id = ?", eventID, participantID).Delete(&models.EventInvitation{})
+	return deleted(result, "Invitation")
+}
+
+// CreateInvitationLink stores a new invitation link
+func (r *PostgresRepository) CreateInvitationLink(ctx context.Context, link *models.InvitationLink) error {
+	return r.db.WithContext(ctx).Create(link).Error
+}
+
+// GetInvitationLinkByHash retrieves an invitation link by the hash of its token
+func (r *PostgresRepository) GetInvitationLinkByHash(ctx context.Context, hash string) (*models.InvitationLink, error) {
+	var link models.InvitationLink
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", hash).First(&link).Error; err != nil {
 		return nil, err
 	}
+	return &link, nil
+}
 
-	for _, slot := range timeSlots {
-		var recommendation models.TimeSlotRecommendation
-		recommendation.TimeSlot = slot
+// GetInvitationLinks retrieves the invitation links of an event, revoked ones included
+func (r *PostgresRepository) GetInvitationLinks(ctx context.Context, eventID uint) ([]models.InvitationLink, error) {
+	var links []models.InvitationLink
+	if err := r.db.WithContext(ctx).Where("event_id = ?", eventID).Order("id").Find(&links).Error; err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// RevokeInvitationLink stops an invitation link of an event from being used.
+// Revoking a link twice keeps the time it was first revoked.
+func (r *PostgresRepository) RevokeInvitationLink(ctx context.Context, eventID, linkID uint) error {
+	result := r.db.WithContext(ctx).Model(&models.InvitationLink{}).
+		Where("id = ? AND event_id = ?", linkID, eventID).
+		Update("revoked_at", gorm.Expr("COALESCE(revoked_at, ?)", time.Now()))
+	return deleted(result, "Invitation link")
+}
 
-		// Get all availabilities for this time slot
-		var availabilities []models.Availability
-		if err := r.db.Where("time_slot_id = ?", slot.ID).Find(&availabilities).Error; err != nil {
-			return nil, err
+// ClaimInvitationLink creates a guest participant for an invitation link and
+// invites them to the event. Accounts do not prove they own their email, so
+// a link never answers as the account that has its email. A link that is
+// already linked returns its participant unchanged.
+func (r *PostgresRepository) ClaimInvitationLink(ctx context.Context, link *models.InvitationLink) (*models.Participant, error) {
+	var participant models.Participant
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the link so concurrent first uses agree on one participant
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(link, link.ID).Error; err != nil {
+			return err
+		}
+		if link.ParticipantID != nil {
+			return tx.First(&participant, *link.ParticipantID).Error
 		}
 
-		// Count available and unavailable participants
-		for _, availability := range availabilities {
-			if availability.IsAvailable {
-				recommendation.AvailableCount++
-				var participant models.Participant
-				if err := r.db.First(&participant, availability.ParticipantID).Error; err != nil {
-					return nil, err
-				}
-				recommendation.AvailableUsers = append(recommendation.AvailableUsers, participant)
-			} else {
-				recommendation.UnavailableCount++
-				var participant models.Participant
-				if err := r.db.First(&participant, availability.ParticipantID).Error; err != nil {
-					return nil, err
-				}
-				recommendation.UnavailableUsers = append(recommendation.UnavailableUsers, participant)
+		participant = models.Participant{Name: link.Name, Email: link.Email, TimeZone: "UTC", Guest: true}
+		if err := tx.Create(&participant).Error; err != nil {
+			return err
+		}
+		invitation := models.EventInvitation{EventID: link.EventID, ParticipantID: participant.ID, Role: link.Role}
+		if err := tx.Create(&invitation).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		link.ParticipantID = &participant.ID
+		link.UsedAt = &now
+		return tx.Model(link).Updates(map[string]interface{}{"participant_id": participant.ID, "used_at": now}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &participant, nil
+}
+
+// UpsertAvailability stores a participant's answer for a time slot of an
+// event, replacing any earlier answer. The replaced answer is kept in the
+// availability history.
+func (r *PostgresRepository) UpsertAvailability(ctx context.Context, eventID uint, availability *models.Availability) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var slot models.TimeSlot
+		if err := tx.Where("event_id = ?", eventID).First(&slot, availability.TimeSlotID).Error; err != nil {
+			return err
+		}
+		return upsertAvailability(tx, availability)
+	})
+}
+
+// UpsertAvailabilities stores several answers for an event in a single
+// transaction. Every answer must reference a time slot of the event.
+func (r *PostgresRepository) UpsertAvailabilities(ctx context.Context, eventID uint, availabilities []models.Availability) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var slotIDs []uint
+		if err := tx.Model(&models.TimeSlot{}).Where("event_id = ?", eventID).Pluck("id", &slotIDs).Error; err != nil {
+			return err
+		}
+
+		eventSlots := make(map[uint]bool, len(slotIDs))
+		for _, id := range slotIDs {
+			eventSlots[id] = true
+		}
+
+		for i := range availabilities {
+			if !eventSlots[availabilities[i].TimeSlotID] {
+				return &Error{Kind: ErrValidation, Message: fmt.Sprintf("Time slot %d does not belong to event %d", availabilities[i].TimeSlotID, eventID)}
 			}
+			if err := upsertAvailability(tx, &availabilities[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetEventAvailabilities retrieves all answers given for the time slots of an event
+func (r *PostgresRepository) GetEventAvailabilities(ctx context.Context, eventID uint) ([]models.Availability, error) {
+	var availabilities []models.Availability
+	err := r.db.WithContext(ctx).
+		Joins("JOIN time_slots ON time_slots.id = availabilities.time_slot_id AND time_slots.deleted_at IS NULL").
+		Where("time_slots.event_id = ?", eventID).
+		Order("availabilities.id").
+		Find(&availabilities).Error
+	if err != nil {
+		return nil, err
+	}
+	return availabilities, nil
+}
+
+// upsertAvailability inserts or replaces an answer within a transaction,
+// recording the previous answer in the history table
+func upsertAvailability(tx *gorm.DB, availability *models.Availability) error {
+	availability.Normalize()
+
+	// Inserting first waits for a concurrent first answer to commit, so
+	// exactly one of them is stored as new and the other replaces it below
+	result := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "participant_id"}, {Name: "time_slot_id"}},
+		DoNothing: true,
+	}).Create(availability)
+	if result.Error != nil || result.RowsAffected == 1 {
+		return result.Error
+	}
+
+	// A soft-deleted answer still holds the unique key, so it is revived
+	// without being recorded as superseded
+	var existing models.Availability
+	err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("participant_id = ? AND time_slot_id = ?", availability.ParticipantID, availability.TimeSlotID).
+		First(&existing).Error
+	if err != nil {
+		return err
+	}
+	if !existing.DeletedAt.Valid {
+		history := models.AvailabilityHistory{
+			AvailabilityID: existing.ID,
+			ParticipantID:  existing.ParticipantID,
+			TimeSlotID:     existing.TimeSlotID,
+			Status:         existing.Status,
+			Weight:         existing.Weight,
+			IsAvailable:    existing.IsAvailable,
+			AnsweredAt:     existing.UpdatedAt,
+		}
+		if err := tx.Create(&history).Error; err != nil {
+			return err
 		}
+	}
+
+	availability.ID = existing.ID
+	availability.CreatedAt = existing.CreatedAt
+	return tx.Unscoped().Model(&existing).Updates(map[string]interface{}{
+		"status":       availability.Status,
+		"weight":       availability.Weight,
+		"is_available": availability.IsAvailable,
+		"updated_at":   availability.UpdatedAt,
+		"deleted_at":   nil,
+	}).Error
+}
+
+// GetAvailabilityHistory returns the superseded answers of a participant for
+// the time slots of an event, most recent first
+func (r *PostgresRepository) GetAvailabilityHistory(ctx context.Context, eventID, participantID uint) ([]models.AvailabilityHistory, error) {
+	var history []models.AvailabilityHistory
+	err := r.db.WithContext(ctx).
+		Joins("JOIN time_slots ON time_slots.id = availability_histories.time_slot_id").
+		Where("time_slots.event_id = ? AND availability_histories.participant_id = ?", eventID, participantID).
+		Order("availability_histories.created_at DESC, availability_histories.id DESC").
+		Find(&history).Error
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// GetTimeSlotRecommendations returns the time slots of an event ranked by
+// score. It makes the same few queries however many slots and answers the
+// event has: its slots, their answers joined with the participants who gave
+// them, and its invitations.
+func (r *PostgresRepository) GetTimeSlotRecommendations(ctx context.Context, eventID uint) ([]models.TimeSlotRecommendation, error) {
+	var timeSlots []models.TimeSlot
+	if err := r.db.WithContext(ctx).Where("event_id = ?", eventID).Find(&timeSlots).Error; err != nil {
+		return nil, err
+	}
+
+	var rows []answerRow
+	err := r.db.WithContext(ctx).Table("availabilities").
+		Select(`availabilities.*,
+			participants.id AS answerer_id, participants.name AS answerer_name,
+			participants.email AS answerer_email, participants.time_zone AS answerer_time_zone,
+			participants.created_at AS answerer_created_at, participants.updated_at AS answerer_updated_at`).
+		Joins("JOIN time_slots ON time_slots.id = availabilities.time_slot_id AND time_slots.deleted_at IS NULL").
+		Joins("JOIN participants ON participants.id = availabilities.participant_id AND participants.deleted_at IS NULL").
+		Where("time_slots.event_id = ? AND availabilities.deleted_at IS NULL", eventID).
+		Order("availabilities.id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
 
-		recommendations = append(recommendations, recommendation)
+	answers := make([]models.Availability, len(rows))
+	participants := make(map[uint]models.Participant)
+	for i, row := range rows {
+		answers[i] = row.Availability
+		participants[row.Answerer.ID] = row.Answerer
 	}
 
-	return recommendations, nil
+	invitations, err := r.GetInvitations(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	for _, invitation := range invitations {
+		if invitation.Participant != nil {
+			participants[invitation.ParticipantID] = *invitation.Participant
+		}
+	}
+
+	return scheduling.BuildRecommendations(timeSlots, answers, participants, invitations), nil
+}
+
+// answerRow is an answer loaded together with the participant who gave it
+type answerRow struct {
+	models.Availability
+	Answerer models.Participant `gorm:"embedded;embeddedPrefix:answerer_"`
 }
 
 // CreateParticipant creates a new participant
-func (r *PostgresRepository) CreateParticipant(participant *models.Participant) error {
-	return r.db.Create(participant).Error
+func (r *PostgresRepository) CreateParticipant(ctx context.Context, participant *models.Participant) error {
+	return r.db.WithContext(ctx).Create(participant).Error
 }
 
 // GetParticipant retrieves a participant by ID
-func (r *PostgresRepository) GetParticipant(id uint) (*models.Participant, error) {
+func (r *PostgresRepository) GetParticipant(ctx context.Context, id uint) (*models.Participant, error) {
 	var participant models.Participant
-	if err := r.db.First(&participant, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).First(&participant, id).Error; err != nil {
 		return nil, err
 	}
 	return &participant, nil
 }
+
+// ListParticipants returns a page of participants, and the cursor of the
+// next page
+func (r *PostgresRepository) ListParticipants(ctx context.Context, filter ParticipantFilter) ([]models.Participant, string, error) {
+	ks, err := resolveKeyset(filter.ListOptions, participantSorts, defaultParticipantSort)
+	if err != nil {
+		return nil, "", err
+	}
+
+	invited := r.db.WithContext(ctx).Model(&models.EventInvitation{}).
+		Select("event_id").
+		Where("participant_id = ?", filter.VisibleTo)
+	shared := r.db.WithContext(ctx).Model(&models.Event{}).
+		Select("id").
+		Where("organizer_id = ? OR id IN (?)", filter.VisibleTo, invited)
+	organizers := r.db.WithContext(ctx).Model(&models.Event{}).Select("organizer_id").Where("id IN (?)", shared)
+	invitees := r.db.WithContext(ctx).Model(&models.EventInvitation{}).Select("participant_id").Where("event_id IN (?)", shared)
+	email := strings.ToLower(filter.EmailPrefix)
+	query := r.db.WithContext(ctx).
+		Where("participants.id = ? OR participants.id IN (?) OR participants.id IN (?) OR lower(email) = ?",
+			filter.VisibleTo, organizers, invitees, email)
+	if filter.ID != 0 {
+		query = query.Where("participants.id = ?", filter.ID)
+	}
+	if filter.EmailPrefix != "" {
+		query = query.Where("lower(email) LIKE ?", escapeLike(email)+"%")
+	}
+
+	limit := filter.limit()
+	var participants []models.Participant
+	if err := ks.apply(query, "participants", limit).Find(&participants).Error; err != nil {
+		return nil, "", err
+	}
+	participants, next := ks.page(participants, limit, func(p models.Participant) uint { return p.ID })
+	return participants, next, nil
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// CreateNotification records a queued notification or an attempt to deliver one
+func (r *PostgresRepository) CreateNotification(ctx context.Context, notification *models.Notification) error {
+	return r.db.WithContext(ctx).Create(notification).Error
+}
+
+// GetEventNotifications retrieves the notifications sent about an event, oldest first
+func (r *PostgresRepository) GetEventNotifications(ctx context.Context, eventID uint) ([]models.Notification, error) {
+	var notifications []models.Notification
+	if err := r.db.WithContext(ctx).Where("event_id = ?", eventID).Order("id").Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// ClaimNotifications retrieves up to limit pending notifications that are due
+// at now and postpones them by lease so no other server sends them meanwhile.
+// A notification whose attempt is never recorded is sent once the lease ends.
+func (r *PostgresRepository) ClaimNotifications(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.Notification, error) {
+	var notifications []models.Notification
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.NotificationPending, now).
+			Order("next_attempt_at").Limit(limit).Find(&notifications).Error; err != nil {
+			return err
+		}
+		if len(notifications) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(notifications))
+		for i := range notifications {
+			ids[i] = notifications[i].ID
+		}
+		return tx.Model(&models.Notification{}).Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// UpdateNotification records the outcome of sending a queued notification
+func (r *PostgresRepository) UpdateNotification(ctx context.Context, notification *models.Notification) error {
+	return r.db.WithContext(ctx).Model(notification).
+		Select("recipient", "subject", "status", "error", "next_attempt_at").Updates(notification).Error
+}
+
+// CreateWebhook registers a webhook
+func (r *PostgresRepository) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	return r.db.WithContext(ctx).Create(webhook).Error
+}
+
+// GetParticipantWebhooks retrieves the webhooks a participant registered
+func (r *PostgresRepository) GetParticipantWebhooks(ctx context.Context, participantID uint) ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	if err := r.db.WithContext(ctx).Where("participant_id = ?", participantID).Order("id").Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook removes one of a participant's webhooks. Its pending
+// deliveries fail on their next attempt.
+func (r *PostgresRepository) DeleteWebhook(ctx context.Context, participantID, webhookID uint) error {
+	result := r.db.WithContext(ctx).Where("participant_id = ?", participantID).Delete(&models.Webhook{}, webhookID)
+	return deleted(result, "Webhook")
+}
+
+// GetWebhookDeliveries retrieves the delivery log of one of a participant's
+// webhooks, newest first
+func (r *PostgresRepository) GetWebhookDeliveries(ctx context.Context, participantID, webhookID uint) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.WithContext(ctx).
+		Joins("JOIN webhooks ON webhooks.id = webhook_deliveries.webhook_id").
+		Where("webhooks.participant_id = ? AND webhooks.id = ?", participantID, webhookID).
+		Order("webhook_deliveries.id DESC").
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// CreateOutboxEvent stores a change for delivery to webhooks. Call it within
+// the transaction making the change.
+func (r *PostgresRepository) CreateOutboxEvent(ctx context.Context, event *models.OutboxEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+// DispatchOutboxEvents creates a pending delivery for every webhook subscribed
+// to up to limit undispatched outbox events, oldest first, and returns how
+// many events were dispatched. Servers running it concurrently skip each
+// other's events.
+func (r *PostgresRepository) DispatchOutboxEvents(ctx context.Context, limit int) (int, error) {
+	var dispatched int
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var events []models.OutboxEvent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("dispatched_at IS NULL").Order("id").Limit(limit).Find(&events).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		webhooks := make(map[uint][]models.Webhook)
+		for _, event := range events {
+			if _, ok := webhooks[event.OrganizerID]; !ok {
+				var found []models.Webhook
+				if err := tx.Where("participant_id = ?", event.OrganizerID).Find(&found).Error; err != nil {
+					return err
+				}
+				webhooks[event.OrganizerID] = found
+			}
+
+			for _, webhook := range webhooks[event.OrganizerID] {
+				if !webhook.Subscribes(event.Type) {
+					continue
+				}
+				delivery := models.WebhookDelivery{
+					WebhookID:     webhook.ID,
+					OutboxEventID: event.ID,
+					EventType:     event.Type,
+					Payload:       event.Payload,
+					Status:        models.WebhookDeliveryPending,
+					NextAttemptAt: now,
+				}
+				if err := tx.Create(&delivery).Error; err != nil {
+					return err
+				}
+			}
+
+			if err := tx.Model(&event).Update("dispatched_at", now).Error; err != nil {
+				return err
+			}
+		}
+		dispatched = len(events)
+		return nil
+	})
+	return dispatched, err
+}
+
+// ClaimWebhookDeliveries retrieves up to limit pending deliveries that are due
+// at now, with their webhooks, and postpones their next attempt by lease so
+// no other server picks them up meanwhile. A delivery whose attempt is never
+// recorded, say because the server crashed, is retried once the lease ends.
+func (r *PostgresRepository) ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, now).
+			Order("next_attempt_at").Limit(limit).Find(&deliveries).Error; err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(deliveries))
+		webhookIDs := make([]uint, len(deliveries))
+		for i := range deliveries {
+			ids[i] = deliveries[i].ID
+			webhookIDs[i] = deliveries[i].WebhookID
+		}
+		if err := tx.Model(&models.WebhookDelivery{}).Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error; err != nil {
+			return err
+		}
+
+		// Deleted webhooks are left out, so their deliveries come back without one
+		var webhooks []models.Webhook
+		if err := tx.Where("id IN ?", webhookIDs).Find(&webhooks).Error; err != nil {
+			return err
+		}
+		byID := make(map[uint]*models.Webhook, len(webhooks))
+		for i := range webhooks {
+			byID[webhooks[i].ID] = &webhooks[i]
+		}
+		for i := range deliveries {
+			deliveries[i].Webhook = byID[deliveries[i].WebhookID]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// UpdateWebhookDelivery records the outcome of a delivery attempt
+func (r *PostgresRepository) UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return r.db.WithContext(ctx).Model(delivery).Select("status", "attempts", "next_attempt_at", "last_attempt_at",
+		"response_status", "error", "delivered_at", "updated_at").Updates(delivery).Error
+}
+
+// ClaimJob leases the named job to owner until now+lease if it is due and
+// no other server holds it, and returns nil otherwise. A job is due the
+// first time it is claimed.
+func (r *PostgresRepository) ClaimJob(ctx context.Context, name, owner string, now time.Time, lease time.Duration) (*models.Job, error) {
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.Job{Name: name, NextRunAt: now}).Error; err != nil {
+		return nil, err
+	}
+
+	var jobs []models.Job
+	err := r.db.WithContext(ctx).Raw(`UPDATE jobs SET locked_by = ?, locked_until = ?, updated_at = ?
+		WHERE name = ? AND next_run_at <= ? AND (locked_until IS NULL OR locked_until <= ?)
+		RETURNING *`, owner, now.Add(lease), now, name, now, now).Scan(&jobs).Error
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+	return &jobs[0], nil
+}
+
+// FinishJob records the outcome of a run and releases the job's lease,
+// provided the job's lease was not taken over meanwhile
+func (r *PostgresRepository) FinishJob(ctx context.Context, job *models.Job) error {
+	return r.db.WithContext(ctx).Model(&models.Job{}).
+		Where("name = ? AND locked_by = ?", job.Name, job.LockedBy).
+		Updates(map[string]interface{}{
+			"next_run_at":  job.NextRunAt,
+			"last_run_at":  job.LastRunAt,
+			"last_error":   job.LastError,
+			"locked_by":    "",
+			"locked_until": nil,
+			"updated_at":   time.Now(),
+		}).Error
+}
+
+// GetExpiredPolls retrieves up to limit polling events whose poll deadline
+// has passed at now, oldest deadline first
+func (r *PostgresRepository) GetExpiredPolls(ctx context.Context, now time.Time, limit int) ([]models.Event, error) {
+	var events []models.Event
+	err := r.db.WithContext(ctx).Preload("TimeSlots").Preload("Invitations.Participant").
+		Where("status = ? AND poll_deadline <= ?", models.EventPolling, now).
+		Order("poll_deadline").Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+// ClosePoll stores the outcome of an event's poll, its status, confirmed
+// time slot and decision, if it is still polling and its deadline has passed
+// at now, and reports whether it did. Checking in the same statement keeps it
+// from overriding a confirmation made meanwhile.
+func (r *PostgresRepository) ClosePoll(ctx context.Context, event *models.Event, now time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Event{}).
+		Where("id = ? AND status = ? AND poll_deadline <= ?", event.ID, models.EventPolling, now).
+		Updates(lifecycleColumns(event, now))
+	return result.RowsAffected == 1, result.Error
+}
+
+// GetPollsToRemind retrieves up to limit polling events whose invitees have
+// not been reminded yet and whose poll deadline falls between now and
+// deadlineBefore
+func (r *PostgresRepository) GetPollsToRemind(ctx context.Context, now, deadlineBefore time.Time, limit int) ([]models.Event, error) {
+	var events []models.Event
+	err := r.db.WithContext(ctx).Preload("TimeSlots").Preload("Invitations.Participant").
+		Where("status = ? AND reminded_at IS NULL AND poll_deadline > ? AND poll_deadline < ?",
+			models.EventPolling, now, deadlineBefore).
+		Order("poll_deadline").Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+// MarkReminded records when an event's invitees were reminded of its
+// deadline and reports whether it did. It does nothing when they already
+// were, so the server that marks an event is the only one to remind.
+func (r *PostgresRepository) MarkReminded(ctx context.Context, eventID uint, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Event{}).
+		Where("id = ? AND reminded_at IS NULL", eventID).Update("reminded_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+// purgeStatements hard-delete rows soft-deleted before @cutoff, along
+// with what belonged to them, and webhook deliveries and outbox events that
+// were done with by then. Rows are deleted before those they refer to.
+var purgeStatements = []string{
+	`DELETE FROM availabilities WHERE deleted_at < @cutoff OR time_slot_id IN (
+		SELECT id FROM time_slots WHERE deleted_at < @cutoff
+			OR event_id IN (SELECT id FROM events WHERE deleted_at < @cutoff))`,
+	`DELETE FROM availability_histories WHERE time_slot_id IN (
+		SELECT id FROM time_slots WHERE deleted_at < @cutoff
+			OR event_id IN (SELECT id FROM events WHERE deleted_at < @cutoff))`,
+	`DELETE FROM event_invitations WHERE deleted_at < @cutoff
+		OR event_id IN (SELECT id FROM events WHERE deleted_at < @cutoff)`,
+	`DELETE FROM occurrence_exceptions WHERE deleted_at < @cutoff
+		OR event_id IN (SELECT id FROM events WHERE deleted_at < @cutoff)`,
+	`DELETE FROM invitation_links WHERE event_id IN (SELECT id FROM events WHERE deleted_at < @cutoff)`,
+	`DELETE FROM notifications WHERE event_id IN (SELECT id FROM events WHERE deleted_at < @cutoff)`,
+	`DELETE FROM time_slots WHERE deleted_at < @cutoff
+		OR event_id IN (SELECT id FROM events WHERE deleted_at < @cutoff)`,
+	`DELETE FROM events WHERE deleted_at < @cutoff`,
+	`DELETE FROM webhook_deliveries WHERE webhook_id IN (SELECT id FROM webhooks WHERE deleted_at < @cutoff)
+		OR (status <> 'pending' AND updated_at < @cutoff)`,
+	`DELETE FROM outbox_events WHERE dispatched_at < @cutoff`,
+	`DELETE FROM webhooks WHERE deleted_at < @cutoff`,
+	`DELETE FROM api_keys WHERE deleted_at < @cutoff`,
+}
+
+// PurgeDeleted permanently removes rows that were soft-deleted before the
+// given time and returns how many rows were removed. Deleted events take
+// their time slots, answers, invitations and notifications with them.
+// Webhook deliveries that were delivered or given up on, and outbox events
+// that were dispatched, before the given time are removed as well.
+func (r *PostgresRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, statement := range purgeStatements {
+			result := tx.Exec(statement, sql.Named("cutoff", before))
+			if result.Error != nil {
+				return result.Error
+			}
+			purged += result.RowsAffected
+		}
+		return nil
+	})
+	return purged, err
+}
+
+// CreateAPIKey stores a new API key
+func (r *PostgresRepository) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+// GetAPIKeyByHash retrieves an API key with its participant by the hash of the key
+func (r *PostgresRepository) GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.WithContext(ctx).Joins("Participant").Where("api_keys.hash = ?", hash).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetParticipantAPIKeys retrieves the API keys of a participant
+func (r *PostgresRepository) GetParticipantAPIKeys(ctx context.Context, participantID uint) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	if err := r.db.WithContext(ctx).Where("participant_id = ?", participantID).Order("id").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// DeleteAPIKey revokes one of a participant's API keys
+func (r *PostgresRepository) DeleteAPIKey(ctx context.Context, participantID, keyID uint) error {
+	result := r.db.WithContext(ctx).Where("participant_id = ?", participantID).Delete(&models.APIKey{}, keyID)
+	return deleted(result, "API key")
+}
+
+// deleted returns the error of a delete, or ErrNotFound when it matched no rows
+func deleted(result *gorm.DB, entity string) error {
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return notFound(entity)
+	}
+	return nil
+}
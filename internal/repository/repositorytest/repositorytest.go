@@ -0,0 +1,731 @@
+// Package repositorytest checks that a Repository implementation behaves the
+// way the API relies on. Every backend runs the same suite, so they cannot
+// drift apart unnoticed.
+package repositorytest
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"github.com/tusharsingune/meeting-scheduler/internal/repository"
+)
+
+// Factory returns an empty repository for one test of the suite
+type Factory func(t *testing.T) repository.Repository
+
+// Run runs the conformance suite against the repositories newRepo returns.
+// Each test gets a repository of its own and may assume it holds no records.
+func Run(t *testing.T, newRepo Factory) {
+	tests := []struct {
+		name string
+		run  func(*testing.T, repository.Repository)
+	}{
+		{"ParticipantRoundTrip", testParticipantRoundTrip},
+		{"ListParticipantsSharingEvents", testListParticipantsSharingEvents},
+		{"ListParticipantsByName", testListParticipantsByName},
+		{"EventRoundTrip", testEventRoundTrip},
+		{"CreateTimeSlotsSkipsOverlaps", testCreateTimeSlotsSkipsOverlaps},
+		{"CreateTimeSlotRejectsOverlaps", testCreateTimeSlotRejectsOverlaps},
+		{"TransitionEvent", testTransitionEvent},
+		{"UpdateEventKeepsLifecycle", testUpdateEventKeepsLifecycle},
+		{"InvitationRoundTrip", testInvitationRoundTrip},
+		{"ClaimInvitationLink", testClaimInvitationLink},
+		{"ClaimNotifications", testClaimNotifications},
+		{"AvailabilityRoundTrip", testAvailabilityRoundTrip},
+		{"ConcurrentAnswersKeepHistory", testConcurrentAnswersKeepHistory},
+		{"NotFound", testNotFound},
+		{"DeleteEventCascades", testDeleteEventCascades},
+		{"PurgeFinishedDeliveries", testPurgeFinishedDeliveries},
+		{"UniqueConstraints", testUniqueConstraints},
+		{"RecommendationCounts", testRecommendationCounts},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepo(t))
+		})
+	}
+}
+
+// start is the start of the first time slot the tests create
+var start = time.Date(2030, 6, 3, 9, 0, 0, 0, time.UTC)
+
+func participant(t *testing.T, repo repository.Repository, name, email string) models.Participant {
+	t.Helper()
+	p := models.Participant{Name: name, Email: email}
+	require.NoError(t, repo.CreateParticipant(context.Background(), &p))
+	return p
+}
+
+// event creates an event organized by organizer with hour-long time slots
+// on consecutive days
+func event(t *testing.T, repo repository.Repository, organizer models.Participant, slots int) models.Event {
+	t.Helper()
+	e := models.Event{Title: "Planning", OrganizerId: organizer.ID, Duration: 60}
+	for i := 0; i < slots; i++ {
+		slotStart := start.AddDate(0, 0, i)
+		e.TimeSlots = append(e.TimeSlots, models.TimeSlot{StartTime: slotStart, EndTime: slotStart.Add(time.Hour)})
+	}
+	require.NoError(t, repo.CreateEvent(context.Background(), &e))
+	return e
+}
+
+func invite(t *testing.T, repo repository.Repository, e models.Event, p models.Participant) {
+	t.Helper()
+	require.NoError(t, repo.InviteParticipant(context.Background(), &models.EventInvitation{EventID: e.ID, ParticipantID: p.ID}))
+}
+
+func answer(t *testing.T, repo repository.Repository, e models.Event, slot models.TimeSlot, p models.Participant, status models.AvailabilityStatus) {
+	t.Helper()
+	availability := models.Availability{ParticipantID: p.ID, TimeSlotID: slot.ID, Status: status}
+	require.NoError(t, repo.UpsertAvailability(context.Background(), e.ID, &availability))
+}
+
+func testParticipantRoundTrip(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	created := models.Participant{Name: "Ada", Email: "ada@example.com", TimeZone: "Europe/London"}
+	require.NoError(t, repo.CreateParticipant(ctx, &created))
+	require.NotZero(t, created.ID)
+	assert.False(t, created.CreatedAt.IsZero())
+
+	loaded, err := repo.GetParticipant(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, loaded.ID)
+	assert.Equal(t, "Ada", loaded.Name)
+	assert.Equal(t, "ada@example.com", loaded.Email)
+	assert.Equal(t, "Europe/London", loaded.TimeZone)
+
+	other := participant(t, repo, "Bob", "bob@example.com")
+	assert.Greater(t, other.ID, created.ID)
+	loaded, err = repo.GetParticipant(ctx, other.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "UTC", loaded.TimeZone, "the time zone defaults to UTC")
+}
+
+func testListParticipantsSharingEvents(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	ada := participant(t, repo, "Ada", "ada@example.com")
+	bob := participant(t, repo, "Bob", "bob@example.com")
+	carol := participant(t, repo, "Carol", "carol@example.com")
+	dan := participant(t, repo, "Dan", "dan@example.com")
+	stranger := participant(t, repo, "Eve", "eve@example.com")
+	planning := event(t, repo, ada, 1)
+	invite(t, repo, planning, bob)
+	invite(t, repo, planning, carol)
+	deleted := event(t, repo, dan, 1)
+	invite(t, repo, deleted, bob)
+	require.NoError(t, repo.DeleteEvent(ctx, deleted.ID))
+
+	list := func(visibleTo uint, email string) []uint {
+		t.Helper()
+		participants, _, err := repo.ListParticipants(ctx, repository.ParticipantFilter{VisibleTo: visibleTo, EmailPrefix: email})
+		require.NoError(t, err)
+		return ids(participants)
+	}
+	assert.Equal(t, []uint{ada.ID, bob.ID, carol.ID}, list(ada.ID, ""), "the organizer sees the invitees")
+	assert.Equal(t, []uint{ada.ID, bob.ID, carol.ID}, list(carol.ID, ""), "invitees see each other and the organizer")
+	assert.Equal(t, []uint{ada.ID, bob.ID, carol.ID}, list(bob.ID, ""), "deleted events are not shared")
+	assert.Equal(t, []uint{stranger.ID}, list(stranger.ID, ""))
+	assert.Equal(t, []uint{bob.ID}, list(ada.ID, "b"))
+	assert.Empty(t, list(ada.ID, "eve"), "others are not found by a prefix")
+	assert.Equal(t, []uint{stranger.ID}, list(ada.ID, "Eve@Example.com"), "others are found by their exact email")
+}
+
+// testListParticipantsByName pages through names whose order differs between
+// the C collation and linguistic ones, which ignore case and punctuation
+func testListParticipantsByName(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	ada := participant(t, repo, "ada", "ada@example.com")
+	planning := event(t, repo, ada, 1)
+	for _, name := range []string{"Bob", "a-b", "ab", "Zoe"} {
+		invite(t, repo, planning, participant(t, repo, name, strings.ToLower(name)+"@example.com"))
+	}
+
+	for _, order := range []struct {
+		sort string
+		want []string
+	}{
+		{"name", []string{"Bob", "Zoe", "a-b", "ab", "ada"}},
+		{"-name", []string{"ada", "ab", "a-b", "Zoe", "Bob"}},
+	} {
+		var names []string
+		filter := repository.ParticipantFilter{ListOptions: repository.ListOptions{Sort: order.sort, Limit: 2}, VisibleTo: ada.ID}
+		for {
+			participants, next, err := repo.ListParticipants(ctx, filter)
+			require.NoError(t, err)
+			for _, p := range participants {
+				names = append(names, p.Name)
+			}
+			if next == "" {
+				break
+			}
+			filter.Cursor = next
+		}
+		assert.Equal(t, order.want, names, "sorted by %s byte by byte", order.sort)
+	}
+}
+
+func testEventRoundTrip(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	ada := participant(t, repo, "Ada", "ada@example.com")
+	created := event(t, repo, ada, 2)
+	require.NotZero(t, created.ID)
+	require.Len(t, created.TimeSlots, 2)
+	for _, slot := range created.TimeSlots {
+		assert.NotZero(t, slot.ID)
+		assert.Equal(t, created.ID, slot.EventID)
+	}
+
+	loaded, err := repo.GetEvent(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Planning", loaded.Title)
+	assert.Equal(t, ada.ID, loaded.OrganizerId)
+	assert.Equal(t, 60, loaded.Duration)
+	assert.Equal(t, models.EventDraft, loaded.Status, "the status defaults to draft")
+	require.Len(t, loaded.TimeSlots, 2)
+	for _, slot := range loaded.TimeSlots {
+		if slot.ID == created.TimeSlots[0].ID {
+			assert.WithinDuration(t, start, slot.StartTime, 0)
+			assert.WithinDuration(t, start.Add(time.Hour), slot.EndTime, 0)
+		}
+	}
+
+	loaded.Title = "Retrospective"
+	loaded.DecisionPolicy = models.DecisionAllRequiredAvailable
+	loaded.TimeSlots = nil
+	require.NoError(t, repo.UpdateEvent(ctx, loaded))
+
+	updated, err := repo.GetEvent(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Retrospective", updated.Title)
+	assert.Equal(t, models.DecisionAllRequiredAvailable, updated.DecisionPolicy)
+	assert.Len(t, updated.TimeSlots, 2, "updating an event leaves its time slots alone")
+
+	slot := models.TimeSlot{EventID: created.ID, StartTime: start.AddDate(0, 0, 7), EndTime: start.AddDate(0, 0, 7).Add(time.Hour)}
+	require.NoError(t, repo.CreateTimeSlot(ctx, &slot))
+	slots, err := repo.GetTimeSlots(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Len(t, slots, 3)
+
+	events, err := repo.GetParticipantEvents(ctx, ada.ID)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, created.ID, events[0].ID)
+}
+
+func testCreateTimeSlotsSkipsOverlaps(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	ada := participant(t, repo, "Ada", "ada@example.com")
+	e := event(t, repo, ada, 1)
+
+	// Hour-long slots every half hour overlap the existing slot and each other
+	var candidates []models.TimeSlot
+	for i := 0; i < 6; i++ {
+		slotStart := start.Add(time.Duration(i) * 30 * time.Minute)
+		candidates = append(candidates, models.TimeSlot{StartTime: slotStart, EndTime: slotStart.Add(time.Hour)})
+	}
+	created, err := repo.CreateTimeSlots(ctx, e.ID, candidates)
+	require.NoError(t, err)
+	require.Len(t, created, 2)
+	assert.WithinDuration(t, start.Add(time.Hour), created[0].StartTime, 0)
+	assert.WithinDuration(t, start.Add(2*time.Hour), created[1].StartTime, 0)
+
+	slots, err := repo.GetTimeSlots(ctx, e.ID)
+	require.NoError(t, err)
+	assert.Len(t, slots, 3)
+
+	_, err = repo.CreateTimeSlots(ctx, 999999, candidates)
+	assert.ErrorIs(t, err, repository.ErrNotFound, "a missing event")
+}
+
+func testCreateTimeSlotRejectsOverlaps(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	ada := participant(t, repo, "Ada", "ada@example.com")
+	e := event(t, repo, ada, 1)
+
+	overlapping := models.TimeSlot{EventID: e.ID, StartTime: start.Add(30 * time.Minute), EndTime: start.Add(90 * time.Minute)}
+	err := repo.CreateTimeSlot(ctx, &overlapping)
+	assert.ErrorIs(t, err, repository.ErrConflict)
+
+	adjacent := models.TimeSlot{EventID: e.ID, StartTime: start.Add(time.Hour), EndTime: start.Add(2 * time.Hour)}
+	require.NoError(t, repo.CreateTimeSlot(ctx, &adjacent))
+	slots, err := repo.GetTimeSlots(ctx, e.ID)
+	require.NoError(t, err)
+	assert.Len(t, slots, 2)
+}
+
+func testTransitionEvent(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	ada := participant(t, repo, "Ada", "ada@example.com")
+	e := event(t, repo, ada, 2)
+	undecided := []models.EventStatus{models.EventDraft, models.EventPolling, models.EventClosed}
+
+	first := e
+	first.Confirm(e.TimeSlots[0], time.Now().UTC())
+	changed, err := repo.TransitionEvent(ctx, &first, undecided...)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	// A second decision based on the same stale status loses
+	second := e
+	second.Confirm(e.TimeSlots[1], time.Now().UTC())
+	changed, err = repo.TransitionEvent(ctx, &second, undecided...)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	loaded, err := repo.GetEvent(ctx, e.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.EventConfirmed, loaded.Status)
+	require.NotNil(t, loaded.ConfirmedTimeSlotID)
+	assert.Equal(t, e.TimeSlots[0].ID, *loaded.ConfirmedTimeSlotID)
+	assert.Equal(t, "Planning", loaded.Title)
+
+	missing := models.Event{ID: 999999, Status: models.EventCancelled}
+	changed, err = repo.TransitionEvent(ctx, &missing, undecided...)
+	require.NoError(t, err)
+	assert.False(t, changed, "a missing event")
+}
+
+func testUpdateEventKeepsLifecycle(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	ada := participant(t, repo, "Ada", "ada@example.com")
+	e := event(t, repo, ada, 1)
+	deadline := start.Add(-24 * time.Hour)
+	e.PollDeadline = &deadline
+	require.NoError(t, repo.UpdateEvent(ctx, &e))
+	marked, err := repo.MarkReminded(ctx, e.ID, time.Now().UTC())
+	require.NoError(t, err)
+	require.True(t, marked)
+	marked, err = repo.MarkReminded(ctx, e.ID, time.Now().UTC())
+	require.NoError(t, err)
+	require.False(t, marked, "an event is reminded of once")
+
+	// An edit based on a copy loaded before the event was confirmed leaves
+	// the decision alone
+	stale := e
+	confirmed := e
+	confirmed.Confirm(e.TimeSlots[0], time.Now().UTC())
+	changed, err := repo.TransitionEvent(ctx, &confirmed, models.EventDraft)
+	require.NoError(t, err)
+	require.True(t, changed)
+
+	stale.Title = "Retrospective"
+	require.NoError(t, repo.UpdateEvent(ctx, &stale))
+	loaded, err := repo.GetEvent(ctx, e.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Retrospective", loaded.Title)
+	assert.Equal(t, models.EventConfirmed, loaded.Status)
+	require.NotNil(t, loaded.ConfirmedTimeSlotID)
+	assert.NotNil(t, loaded.RemindedAt, "keeping the deadline keeps the reminder")
+
+	moved := deadline.Add(time.Hour)
+	stale.PollDeadline = &moved
+	require.NoError(t, repo.UpdateEvent(ctx, &stale))
+	loaded, err = repo.GetEvent(ctx, e.ID)
+	require.NoError(t, err)
+	assert.Nil(t, loaded.RemindedAt, "moving the deadline earns a new reminder")
+
+	missing := models.Event{ID: 999999, Title: "Missing"}
+	assert.ErrorIs(t, repo.UpdateEvent(ctx, &missing), repository.ErrNotFound, "a missing event")
+}
+
+func testInvitationRoundTrip(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	ada := participant(t, repo, "Ada", "ada@example.com")
+	bob := participant(t, repo, "Bob", "bob@example.com")
+	e := event(t, repo, ada, 1)
+
+	invite(t, repo, e, bob)
+	invitations, err := repo.GetInvitations(ctx, e.ID)
+	require.NoError(t, err)
+	require.Len(t, invitations, 1)
+	assert.Equal(t, bob.ID, invitations[0].ParticipantID)
+	assert.Equal(t, models.AttendeeRequired, invitations[0].Role, "the role defaults to required")
+	require.NotNil(t, invitations[0].Participant)
+	assert.Equal(t, "bob@example.com", invitations[0].Participant.Email)
+
+	// Inviting again changes the role instead of adding an invitation
+	again := models.EventInvitation{EventID: e.ID, ParticipantID: bob.ID, Role: models.AttendeeOptional}
+	require.NoError(t, repo.InviteParticipant(ctx, &again))
+	invitations, err = repo.GetInvitations(ctx, e.ID)
+	require.NoError(t, err)
+	require.Len(t, invitations, 1)
+	assert.Equal(t, models.AttendeeOptional, invitations[0].Role)
+
+	events, err := repo.GetParticipantEvents(ctx, bob.ID)
+	require.NoError(t, err)
+	assert.Len(t, events, 1, "invitees see the event")
+
+	require.NoError(t, repo.RemoveInvitation(ctx, e.ID, bob.ID))
+	invitations, err = repo.GetInvitations(ctx, e.ID)
+	require.NoError(t, err)
+	assert.Empty(t, invitations)
+	assert.ErrorIs(t, repo.RemoveInvitation(ctx, e.ID, bob.ID), repository.ErrNotFound, "a withdrawn invitation")
+
+	// A withdrawn invitation is restored by inviting again
+	invite(t, repo, e, bob)
+	invitations, err = repo.GetInvitations(ctx, e.ID)
+	require.NoError(t, err)
+	assert.Len(t, invitations, 1)
+}
+
+func testClaimInvitationLink(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	ada := participant(t, repo, "Ada", "ada@example.com")
+	grace := participant(t, repo, "Grace", "grace@example.com")
+	e := event(t, repo, ada, 1)
+
+	link := models.InvitationLink{EventID: e.ID, Email: "grace@example.com", Name: "Grace", ExpiresAt: start,
+		TokenHash: "9b74c9897bac770ffc029102a200c5de3b4b2b2c1e6e4e0b5c1c6e5d1b0e8a61"}
+	require.NoError(t, repo.CreateInvitationLink(ctx, &link))
+	loaded, err := repo.GetInvitationLinkByHash(ctx, link.TokenHash)
+	require.NoError(t, err)
+	assert.Equal(t, link.ID, loaded.ID, "links are found by the hash of their token")
+	guest, err := repo.ClaimInvitationLink(ctx, &link)
+	require.NoError(t, err)
+	assert.NotEqual(t, grace.ID, guest.ID, "a link does not answer as the account with its email")
+	assert.True(t, guest.Guest)
+	assert.Equal(t, "grace@example.com", guest.Email)
+	require.NotNil(t, link.ParticipantID)
+	assert.Equal(t, guest.ID, *link.ParticipantID)
+
+	again, err := repo.ClaimInvitationLink(ctx, &link)
+	require.NoError(t, err)
+	assert.Equal(t, guest.ID, again.ID, "a claimed link keeps its participant")
+
+	// Every link gets a guest of its own, even for the same email
+	other := models.InvitationLink{EventID: e.ID, Email: "grace@example.com", Name: "Grace", ExpiresAt: start,
+		TokenHash: "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"}
+	require.NoError(t, repo.CreateInvitationLink(ctx, &other))
+	second, err := repo.ClaimInvitationLink(ctx, &other)
+	require.NoError(t, err)
+	assert.NotEqual(t, guest.ID, second.ID)
+
+	invitations, err := repo.GetInvitations(ctx, e.ID)
+	require.NoError(t, err)
+	assert.Len(t, invitations, 2)
+}
+
+func testClaimNotifications(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	organizer := participant(t, repo, "Ada", "ada@example.com")
+	e := event(t, repo, organizer, 1)
+	now := time.Date(2030, 6, 1, 12, 0, 0, 0, time.UTC)
+	queue := func(due time.Time) models.Notification {
+		n := models.Notification{EventID: e.ID, ParticipantID: organizer.ID, Kind: "invitation", Channel: "email",
+			Recipient: organizer.Email, Status: models.NotificationPending, NextAttemptAt: &due}
+		require.NoError(t, repo.CreateNotification(ctx, &n))
+		return n
+	}
+	due := queue(now.Add(-time.Minute))
+	queue(now.Add(time.Minute))
+	require.NoError(t, repo.CreateNotification(ctx, &models.Notification{EventID: e.ID, ParticipantID: organizer.ID,
+		Kind: "reminder", Channel: "email", Recipient: organizer.Email, Status: models.NotificationSent}))
+
+	claimed, err := repo.ClaimNotifications(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1, "only pending notifications that are due are claimed")
+	assert.Equal(t, due.ID, claimed[0].ID)
+
+	claimed, err = repo.ClaimNotifications(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed, "a claimed notification is hidden until its lease ends")
+
+	due.Status = models.NotificationSent
+	due.Subject = "Invitation: Planning"
+	due.NextAttemptAt = nil
+	require.NoError(t, repo.UpdateNotification(ctx, &due))
+	claimed, err = repo.ClaimNotifications(ctx, now.Add(time.Hour), time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1, "a sent notification is not claimed again")
+	assert.NotEqual(t, due.ID, claimed[0].ID)
+
+	notifications, err := repo.GetEventNotifications(ctx, e.ID)
+	require.NoError(t, err)
+	require.Len(t, notifications, 3)
+	assert.Equal(t, models.NotificationSent, notifications[0].Status)
+	assert.Equal(t, "Invitation: Planning", notifications[0].Subject)
+}
+
+func testAvailabilityRoundTrip(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	ada := participant(t, repo, "Ada", "ada@example.com")
+	e := event(t, repo, ada, 2)
+	slot := e.TimeSlots[0]
+
+	answer(t, repo, e, slot, ada, models.AvailabilityYes)
+	answers, err := repo.GetEventAvailabilities(ctx, e.ID)
+	require.NoError(t, err)
+	require.Len(t, answers, 1)
+	assert.Equal(t, models.AvailabilityYes, answers[0].Status)
+	assert.True(t, answers[0].IsAvailable)
+
+	// A later answer replaces the first, which moves to the history
+	answer(t, repo, e, slot, ada, models.AvailabilityNo)
+	answers, err = repo.GetEventAvailabilities(ctx, e.ID)
+	require.NoError(t, err)
+	require.Len(t, answers, 1)
+	assert.Equal(t, models.AvailabilityNo, answers[0].Status)
+	assert.False(t, answers[0].IsAvailable)
+
+	history, err := repo.GetAvailabilityHistory(ctx, e.ID, ada.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, models.AvailabilityYes, history[0].Status)
+
+	require.NoError(t, repo.UpsertAvailabilities(ctx, e.ID, []models.Availability{
+		{ParticipantID: ada.ID, TimeSlotID: e.TimeSlots[0].ID, Status: models.AvailabilityIfNeedBe},
+		{ParticipantID: ada.ID, TimeSlotID: e.TimeSlots[1].ID, Status: models.AvailabilityYes},
+	}))
+	answers, err = repo.GetEventAvailabilities(ctx, e.ID)
+	require.NoError(t, err)
+	assert.Len(t, answers, 2)
+}
+
+func testConcurrentAnswersKeepHistory(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	ada := participant(t, repo, "Ada", "ada@example.com")
+	e := event(t, repo, ada, 1)
+
+	// Answers racing to be the first are all kept, one current and the
+	// others superseded
+	const answers = 8
+	statuses := []models.AvailabilityStatus{models.AvailabilityYes, models.AvailabilityNo}
+	var wg sync.WaitGroup
+	errs := make([]error, answers)
+	for i := 0; i < answers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			availability := models.Availability{ParticipantID: ada.ID, TimeSlotID: e.TimeSlots[0].ID, Status: statuses[i%2]}
+			errs[i] = repo.UpsertAvailability(ctx, e.ID, &availability)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	current, err := repo.GetEventAvailabilities(ctx, e.ID)
+	require.NoError(t, err)
+	assert.Len(t, current, 1)
+	history, err := repo.GetAvailabilityHistory(ctx, e.ID, ada.ID)
+	require.NoError(t, err)
+	assert.Len(t, history, answers-1)
+}
+
+func testNotFound(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	const missing = 999999
+
+	_, err := repo.GetEvent(ctx, missing)
+	assert.ErrorIs(t, err, repository.ErrNotFound, "event")
+	_, err = repo.GetParticipant(ctx, missing)
+	assert.ErrorIs(t, err, repository.ErrNotFound, "participant")
+	_, err = repo.GetInvitationLinkByHash(ctx, "unknown")
+	assert.ErrorIs(t, err, repository.ErrNotFound, "invitation link")
+	_, err = repo.GetAPIKeyByHash(ctx, "unknown")
+	assert.ErrorIs(t, err, repository.ErrNotFound, "API key")
+
+	ada := participant(t, repo, "Ada", "ada@example.com")
+	bob := participant(t, repo, "Bob", "bob@example.com")
+	assert.ErrorIs(t, repo.DeleteWebhook(ctx, ada.ID, missing), repository.ErrNotFound, "webhook")
+	assert.ErrorIs(t, repo.DeleteAPIKey(ctx, ada.ID, missing), repository.ErrNotFound, "API key")
+
+	// Records of someone else are not found either
+	webhook := models.Webhook{ParticipantID: ada.ID, URL: "https://example.com/hook", Secret: "secret"}
+	require.NoError(t, repo.CreateWebhook(ctx, &webhook))
+	assert.ErrorIs(t, repo.DeleteWebhook(ctx, bob.ID, webhook.ID), repository.ErrNotFound, "another participant's webhook")
+	require.NoError(t, repo.DeleteWebhook(ctx, ada.ID, webhook.ID))
+	assert.ErrorIs(t, repo.DeleteWebhook(ctx, ada.ID, webhook.ID), repository.ErrNotFound, "a deleted webhook")
+
+	e := event(t, repo, ada, 1)
+	other := event(t, repo, ada, 1)
+	err = repo.CreateTimeSlot(ctx, &models.TimeSlot{EventID: missing, StartTime: start, EndTime: start.Add(time.Hour)})
+	assert.ErrorIs(t, err, repository.ErrNotFound, "time slot of a missing event")
+	assert.ErrorIs(t, repo.DeleteOccurrenceException(ctx, e.ID, missing), repository.ErrNotFound, "occurrence exception")
+
+	link := models.InvitationLink{EventID: e.ID, Email: "grace@example.com", Name: "Grace", ExpiresAt: start,
+		TokenHash: "fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9"}
+	require.NoError(t, repo.CreateInvitationLink(ctx, &link))
+	assert.ErrorIs(t, repo.RevokeInvitationLink(ctx, e.ID, missing), repository.ErrNotFound, "invitation link")
+	assert.ErrorIs(t, repo.RevokeInvitationLink(ctx, other.ID, link.ID), repository.ErrNotFound, "a link of another event")
+	require.NoError(t, repo.RevokeInvitationLink(ctx, e.ID, link.ID))
+	assert.NoError(t, repo.RevokeInvitationLink(ctx, e.ID, link.ID), "revoking twice is not an error")
+
+	availability := models.Availability{ParticipantID: ada.ID, TimeSlotID: other.TimeSlots[0].ID, Status: models.AvailabilityYes}
+	err = repo.UpsertAvailability(ctx, e.ID, &availability)
+	assert.ErrorIs(t, err, repository.ErrNotFound, "a time slot of another event")
+	err = repo.UpsertAvailabilities(ctx, e.ID, []models.Availability{availability})
+	assert.ErrorIs(t, err, repository.ErrValidation, "time slots of another event in bulk")
+}
+
+func testDeleteEventCascades(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	ada := participant(t, repo, "Ada", "ada@example.com")
+	bob := participant(t, repo, "Bob", "bob@example.com")
+	deleted := event(t, repo, ada, 2)
+	kept := event(t, repo, ada, 1)
+	for _, e := range []models.Event{deleted, kept} {
+		invite(t, repo, e, bob)
+		for _, slot := range e.TimeSlots {
+			answer(t, repo, e, slot, bob, models.AvailabilityYes)
+		}
+	}
+
+	require.NoError(t, repo.DeleteEvent(ctx, deleted.ID))
+
+	_, err := repo.GetEvent(ctx, deleted.ID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	slots, err := repo.GetTimeSlots(ctx, deleted.ID)
+	require.NoError(t, err)
+	assert.Empty(t, slots, "the event's time slots are deleted with it")
+	slots, _, err = repo.ListTimeSlots(ctx, deleted.ID, repository.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, slots)
+	answers, err := repo.GetEventAvailabilities(ctx, deleted.ID)
+	require.NoError(t, err)
+	assert.Empty(t, answers, "the answers for its time slots are deleted with it")
+	recommendations, err := repo.GetTimeSlotRecommendations(ctx, deleted.ID)
+	require.NoError(t, err)
+	assert.Empty(t, recommendations)
+
+	events, err := repo.GetParticipantEvents(ctx, bob.ID)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, kept.ID, events[0].ID)
+
+	// Other events keep their slots and answers
+	slots, err = repo.GetTimeSlots(ctx, kept.ID)
+	require.NoError(t, err)
+	assert.Len(t, slots, 1)
+	answers, err = repo.GetEventAvailabilities(ctx, kept.ID)
+	require.NoError(t, err)
+	assert.Len(t, answers, 1)
+
+	assert.NoError(t, repo.DeleteEvent(ctx, deleted.ID), "deleting twice is not an error")
+}
+
+func testPurgeFinishedDeliveries(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	ada := participant(t, repo, "Ada", "ada@example.com")
+	hook := models.Webhook{ParticipantID: ada.ID, URL: "https://example.com/hook", Secret: "0123456789abcdef"}
+	require.NoError(t, repo.CreateWebhook(ctx, &hook))
+	for i := 0; i < 2; i++ {
+		require.NoError(t, repo.CreateOutboxEvent(ctx, &models.OutboxEvent{Type: "event.created", OrganizerID: ada.ID, Payload: "{}"}))
+	}
+	dispatched, err := repo.DispatchOutboxEvents(ctx, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, dispatched)
+	deliveries, err := repo.GetWebhookDeliveries(ctx, ada.ID, hook.ID)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	delivered := deliveries[0]
+	delivered.Status = models.WebhookDeliveryDelivered
+	require.NoError(t, repo.UpdateWebhookDelivery(ctx, &delivered))
+
+	purged, err := repo.PurgeDeleted(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, purged, "recent deliveries are kept")
+
+	purged, err = repo.PurgeDeleted(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), purged, "the delivered delivery and both dispatched outbox events")
+	deliveries, err = repo.GetWebhookDeliveries(ctx, ada.ID, hook.ID)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1, "pending deliveries are kept")
+	assert.Equal(t, models.WebhookDeliveryPending, deliveries[0].Status)
+}
+
+func testUniqueConstraints(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	ada := participant(t, repo, "Ada", "ada@example.com")
+
+	duplicate := models.Participant{Name: "Another Ada", Email: "ada@example.com"}
+	err := repo.CreateParticipant(ctx, &duplicate)
+	assert.ErrorIs(t, err, repository.ErrConflict, "email")
+
+	hash := "a3f1c9d2e4b5a6c7d8e9f0a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1"
+	key := models.APIKey{ParticipantID: ada.ID, Name: "laptop", Prefix: "ms_a3f1", Hash: hash}
+	require.NoError(t, repo.CreateAPIKey(ctx, &key))
+	again := models.APIKey{ParticipantID: ada.ID, Name: "phone", Prefix: "ms_a3f1", Hash: hash}
+	err = repo.CreateAPIKey(ctx, &again)
+	assert.ErrorIs(t, err, repository.ErrConflict, "API key hash")
+
+	keys, err := repo.GetParticipantAPIKeys(ctx, ada.ID)
+	require.NoError(t, err)
+	assert.Len(t, keys, 1, "the rejected key is not stored")
+
+	// References to records that do not exist are rejected too
+	e := event(t, repo, ada, 1)
+	err = repo.InviteParticipant(ctx, &models.EventInvitation{EventID: e.ID, ParticipantID: 999999})
+	assert.ErrorIs(t, err, repository.ErrValidation, "invitation of a missing participant")
+}
+
+func testRecommendationCounts(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	ada := participant(t, repo, "Ada", "ada@example.com")
+	bob := participant(t, repo, "Bob", "bob@example.com")
+	cleo := participant(t, repo, "Cleo", "cleo@example.com")
+	dan := participant(t, repo, "Dan", "dan@example.com")
+	e := event(t, repo, ada, 3)
+	invite(t, repo, e, bob)
+	invite(t, repo, e, dan)
+	optional := models.EventInvitation{EventID: e.ID, ParticipantID: cleo.ID, Role: models.AttendeeOptional}
+	require.NoError(t, repo.InviteParticipant(ctx, &optional))
+	monday, tuesday, wednesday := e.TimeSlots[0], e.TimeSlots[1], e.TimeSlots[2]
+
+	answer(t, repo, e, monday, ada, models.AvailabilityYes)
+	answer(t, repo, e, monday, bob, models.AvailabilityYes)
+	answer(t, repo, e, monday, cleo, models.AvailabilityIfNeedBe)
+	answer(t, repo, e, tuesday, bob, models.AvailabilityYes)
+	answer(t, repo, e, tuesday, bob, models.AvailabilityIfNeedBe) // replaces Bob's first answer
+	answer(t, repo, e, tuesday, cleo, models.AvailabilityNo)
+	answer(t, repo, e, wednesday, ada, models.AvailabilityYes)
+	answer(t, repo, e, wednesday, dan, models.AvailabilityNo)
+
+	recommendations, err := repo.GetTimeSlotRecommendations(ctx, e.ID)
+	require.NoError(t, err)
+	require.Len(t, recommendations, 2, "a slot a required invitee cannot make is dropped")
+	assert.Equal(t, monday.ID, recommendations[0].TimeSlot.ID, "the slot most can make ranks first")
+	bySlot := make(map[uint]models.TimeSlotRecommendation)
+	for _, recommendation := range recommendations {
+		bySlot[recommendation.TimeSlot.ID] = recommendation
+	}
+
+	tests := []struct {
+		name                             string
+		slot                             models.TimeSlot
+		available, ifNeedBe, unavailable []models.Participant
+		pending                          []models.Participant
+	}{
+		{"monday", monday, []models.Participant{ada, bob}, []models.Participant{cleo}, nil, []models.Participant{dan}},
+		{"tuesday", tuesday, nil, []models.Participant{bob}, []models.Participant{cleo}, []models.Participant{dan}},
+	}
+	for _, tt := range tests {
+		recommendation, ok := bySlot[tt.slot.ID]
+		require.True(t, ok, tt.name)
+		assert.Equal(t, len(tt.available), recommendation.AvailableCount, tt.name)
+		assert.Equal(t, len(tt.ifNeedBe), recommendation.IfNeedBeCount, tt.name)
+		assert.Equal(t, len(tt.unavailable), recommendation.UnavailableCount, tt.name)
+		assert.ElementsMatch(t, ids(tt.available), ids(recommendation.AvailableUsers), tt.name)
+		assert.ElementsMatch(t, ids(tt.ifNeedBe), ids(recommendation.IfNeedBeUsers), tt.name)
+		assert.ElementsMatch(t, ids(tt.unavailable), ids(recommendation.UnavailableUsers), tt.name)
+		assert.ElementsMatch(t, ids(tt.pending), ids(recommendation.PendingUsers), tt.name)
+	}
+}
+
+// ids returns the IDs of participants, so lists compare regardless of which
+// fields a backend loads
+func ids(participants []models.Participant) []uint {
+	var result []uint
+	for _, p := range participants {
+		result = append(result, p.ID)
+	}
+	return result
+}
@@ -0,0 +1,69 @@
+package scheduling
+
+import (
+	"fmt"
+
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+)
+
+// Decide applies an event's decision policy to its ranked recommendations.
+// It returns the time slot to confirm, or nil when the organizer has to
+// decide, with the reason either way. The event's time slots and
+// invitations must be loaded.
+func Decide(event models.Event, recommendations []models.TimeSlotRecommendation) (*models.TimeSlot, string) {
+	switch event.DecisionPolicy {
+	case models.DecisionTopRecommendation:
+		if len(recommendations) == 0 {
+			return nil, noCandidates(event)
+		}
+		best := recommendations[0]
+		return &best.TimeSlot, "Confirmed the top recommendation: " + describe(best)
+
+	case models.DecisionAllRequiredAvailable:
+		if len(recommendations) == 0 {
+			return nil, noCandidates(event)
+		}
+		required := make(map[uint]bool, len(event.Invitations))
+		for _, invitation := range event.Invitations {
+			if invitation.Role != models.AttendeeOptional {
+				required[invitation.ParticipantID] = true
+			}
+		}
+		for _, rec := range recommendations {
+			if !anyPending(rec, required) {
+				return &rec.TimeSlot, "Confirmed the best time slot every required attendee can make: " + describe(rec)
+			}
+		}
+		return nil, "No time slot has been accepted by every required attendee; some have not answered"
+
+	default:
+		return nil, "The decision policy leaves the choice to the organizer"
+	}
+}
+
+// noCandidates explains why there is no recommendation to choose from
+func noCandidates(event models.Event) string {
+	if len(event.TimeSlots) == 0 {
+		return "The event has no time slots"
+	}
+	return "Every time slot was declined by a required attendee"
+}
+
+// anyPending reports whether any of the given participants has not answered
+// for a recommended slot. Slots declined by required attendees are not
+// recommended at all.
+func anyPending(rec models.TimeSlotRecommendation, participants map[uint]bool) bool {
+	for _, pending := range rec.PendingUsers {
+		if participants[pending.ID] {
+			return true
+		}
+	}
+	return false
+}
+
+// describe summarizes a recommendation for a decision's reason
+func describe(rec models.TimeSlotRecommendation) string {
+	return fmt.Sprintf("time slot %d at %s, scoring %g with %d yes, %d if need be and %d no",
+		rec.TimeSlot.ID, rec.TimeSlot.StartTime.UTC().Format("2006-01-02 15:04 MST"),
+		rec.Score, rec.AvailableCount, rec.IfNeedBeCount, rec.UnavailableCount)
+}
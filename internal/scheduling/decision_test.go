@@ -0,0 +1,69 @@
+package scheduling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+)
+
+func TestDecide(t *testing.T) {
+	start := time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC)
+	slots := []models.TimeSlot{
+		{ID: 1, StartTime: start, EndTime: start.Add(time.Hour)},
+		{ID: 2, StartTime: start.Add(2 * time.Hour), EndTime: start.Add(3 * time.Hour)},
+	}
+	participants := map[uint]models.Participant{
+		1: {ID: 1, Name: "Ada"},
+		2: {ID: 2, Name: "Grace"},
+		3: {ID: 3, Name: "Alan"},
+	}
+	invitations := []models.EventInvitation{
+		{ParticipantID: 1, Role: models.AttendeeRequired},
+		{ParticipantID: 2, Role: models.AttendeeRequired},
+		{ParticipantID: 3, Role: models.AttendeeOptional},
+	}
+	// Slot 1 scores best on the optional attendee's answer, but Grace has not
+	// answered for it; slot 2 suits both required attendees
+	answers := []models.Availability{
+		{ParticipantID: 1, TimeSlotID: 1, Status: models.AvailabilityYes},
+		{ParticipantID: 3, TimeSlotID: 1, Status: models.AvailabilityYes},
+		{ParticipantID: 1, TimeSlotID: 2, Status: models.AvailabilityYes},
+		{ParticipantID: 2, TimeSlotID: 2, Status: models.AvailabilityIfNeedBe},
+	}
+	recs := BuildRecommendations(slots, answers, participants, invitations)
+	require.Equal(t, uint(1), recs[0].TimeSlot.ID)
+
+	tests := []struct {
+		name     string
+		policy   models.DecisionPolicy
+		recs     []models.TimeSlotRecommendation
+		wantSlot uint
+		reason   string
+	}{
+		{"manual", models.DecisionManual, recs, 0, "organizer"},
+		{"unset", "", recs, 0, "organizer"},
+		{"top recommendation", models.DecisionTopRecommendation, recs, 1, "time slot 1"},
+		{"all required available", models.DecisionAllRequiredAvailable, recs, 2, "time slot 2"},
+		{"all required available without a candidate", models.DecisionAllRequiredAvailable, recs[:1], 0, "not answered"},
+		{"every slot declined", models.DecisionTopRecommendation, nil, 0, "declined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := models.Event{DecisionPolicy: tt.policy, TimeSlots: slots, Invitations: invitations}
+
+			slot, reason := Decide(event, tt.recs)
+
+			if tt.wantSlot == 0 {
+				assert.Nil(t, slot)
+			} else {
+				require.NotNil(t, slot)
+				assert.Equal(t, tt.wantSlot, slot.ID)
+			}
+			assert.Contains(t, reason, tt.reason)
+		})
+	}
+}
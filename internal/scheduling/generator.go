@@ -0,0 +1,144 @@
+package scheduling
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+)
+
+// Limits on a single generation request, which bound the work it takes even
+// when it produces no slots
+const (
+	MaxGeneratedSlots    = 500 // candidate slots produced
+	MaxGenerationDays    = 366 // days in the date range
+	MaxGenerationWindows = 10  // working-hours windows
+)
+
+// window is a parsed working-hours window
+type window struct {
+	loc   *time.Location
+	hours WorkingHours
+}
+
+// GenerateSlots returns the candidate time slots described by the request for
+// an event of the given duration, in chronological order. The slots are not
+// bound to an event yet.
+func GenerateSlots(req models.TimeSlotGenerationRequest, duration time.Duration) ([]models.TimeSlot, error) {
+	if duration <= 0 {
+		return nil, fmt.Errorf("event duration must be positive")
+	}
+
+	if len(req.Windows) > MaxGenerationWindows {
+		return nil, fmt.Errorf("at most %d working-hours windows are allowed", MaxGenerationWindows)
+	}
+	windows := make([]window, 0, len(req.Windows))
+	for _, w := range req.Windows {
+		loc, err := LoadLocation(w.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q", w.TimeZone)
+		}
+		hours, err := ParseWorkingHours(w.Start, w.End)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, window{loc: loc, hours: hours})
+	}
+	if len(windows) == 0 {
+		return nil, fmt.Errorf("at least one working-hours window is required")
+	}
+
+	ref := windows[0].loc
+	if req.TimeZone != "" {
+		loc, err := LoadLocation(req.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q", req.TimeZone)
+		}
+		ref = loc
+	}
+
+	from, err := time.ParseInLocation("2006-01-02", req.StartDate, ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date %q", req.StartDate)
+	}
+	to, err := time.ParseInLocation("2006-01-02", req.EndDate, ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date %q", req.EndDate)
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("end date must not be before start date")
+	}
+	if to.After(from.AddDate(0, 0, MaxGenerationDays-1)) {
+		return nil, fmt.Errorf("date range must not be longer than %d days", MaxGenerationDays)
+	}
+
+	excludedWeekdays, excludedDates, err := parseExcludedDays(req.ExcludeDays)
+	if err != nil {
+		return nil, err
+	}
+
+	step := time.Duration(req.StepMinutes) * time.Minute
+	if step <= 0 {
+		step = duration
+	}
+
+	var slots []models.TimeSlot
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if excludedWeekdays[day.Weekday()] || excludedDates[day.Format("2006-01-02")] {
+			continue
+		}
+
+		next := day.AddDate(0, 0, 1)
+		for start := day; start.Before(next); start = start.Add(step) {
+			end := start.Add(duration)
+			if !fitsAll(windows, start, end) {
+				continue
+			}
+			if len(slots) == MaxGeneratedSlots {
+				return nil, fmt.Errorf("request would generate more than %d time slots", MaxGeneratedSlots)
+			}
+			slots = append(slots, models.TimeSlot{StartTime: start.UTC(), EndTime: end.UTC()})
+		}
+	}
+
+	return slots, nil
+}
+
+// fitsAll reports whether a period falls within every working-hours window
+func fitsAll(windows []window, start, end time.Time) bool {
+	for _, w := range windows {
+		if !w.hours.Contains(start, end, w.loc) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseExcludedDays splits excluded days into weekdays and specific dates
+func parseExcludedDays(days []string) (map[time.Weekday]bool, map[string]bool, error) {
+	weekdays := make(map[time.Weekday]bool)
+	dates := make(map[string]bool)
+
+	for _, day := range days {
+		if _, err := time.Parse("2006-01-02", day); err == nil {
+			dates[day] = true
+			continue
+		}
+
+		found := false
+		for wd := time.Sunday; wd <= time.Saturday; wd++ {
+			name := strings.ToLower(wd.String())
+			if d := strings.ToLower(day); d == name || d == name[:3] {
+				weekdays[wd] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, nil, fmt.Errorf("invalid excluded day %q, expected a weekday or YYYY-MM-DD", day)
+		}
+	}
+
+	return weekdays, dates, nil
+}
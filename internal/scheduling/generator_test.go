@@ -0,0 +1,67 @@
+package scheduling
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+)
+
+func TestGenerateSlotsIntersectsWindows(t *testing.T) {
+	req := models.TimeSlotGenerationRequest{
+		StartDate: "2030-01-07",
+		EndDate:   "2030-01-07",
+		Windows: []models.WorkingWindow{
+			{TimeZone: "Europe/Berlin", Start: "09:00", End: "18:00"},
+			{TimeZone: "America/New_York", Start: "08:00", End: "17:00"},
+		},
+		StepMinutes: 30,
+	}
+
+	slots, err := GenerateSlots(req, time.Hour)
+	assert.NoError(t, err)
+
+	// Berlin 14:00-18:00 overlaps New York 08:00-12:00
+	assert.Len(t, slots, 7)
+	assert.Equal(t, time.Date(2030, 1, 7, 13, 0, 0, 0, time.UTC), slots[0].StartTime)
+	assert.Equal(t, time.Date(2030, 1, 7, 17, 0, 0, 0, time.UTC), slots[6].EndTime)
+}
+
+func TestGenerateSlotsExcludesDays(t *testing.T) {
+	req := models.TimeSlotGenerationRequest{
+		StartDate:   "2030-01-04",
+		EndDate:     "2030-01-08",
+		Windows:     []models.WorkingWindow{{TimeZone: "UTC", Start: "09:00", End: "10:00"}},
+		ExcludeDays: []string{"Saturday", "sunday", "2030-01-08"},
+	}
+
+	slots, err := GenerateSlots(req, time.Hour)
+	assert.NoError(t, err)
+	assert.Len(t, slots, 2)
+	assert.Equal(t, time.Friday, slots[0].StartTime.Weekday())
+	assert.Equal(t, time.Monday, slots[1].StartTime.Weekday())
+}
+
+func TestGenerateSlotsRejectsInvalidRequests(t *testing.T) {
+	window := []models.WorkingWindow{{TimeZone: "UTC", Start: "00:00", End: "23:59"}}
+
+	tests := []struct {
+		name string
+		req  models.TimeSlotGenerationRequest
+	}{
+		{"reversed range", models.TimeSlotGenerationRequest{StartDate: "2030-01-08", EndDate: "2030-01-07", Windows: window}},
+		{"unknown excluded day", models.TimeSlotGenerationRequest{StartDate: "2030-01-07", EndDate: "2030-01-07", Windows: window, ExcludeDays: []string{"someday"}}},
+		{"too many slots", models.TimeSlotGenerationRequest{StartDate: "2030-01-01", EndDate: "2030-12-31", Windows: window, StepMinutes: 5}},
+		{"range too long", models.TimeSlotGenerationRequest{StartDate: "0001-01-01", EndDate: "9999-12-31", Windows: window, StepMinutes: 5}},
+		{"too many windows", models.TimeSlotGenerationRequest{StartDate: "2030-01-07", EndDate: "2030-01-07", Windows: slices.Repeat(window, MaxGenerationWindows+1)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GenerateSlots(tt.req, time.Hour)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package scheduling
+
+import (
+	"math"
+	"sort"
+
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+)
+
+// Points awarded for an answer before the participant's preference weight is applied
+const (
+	YesPoints      = 1.0
+	IfNeedBePoints = 0.5
+)
+
+// BuildRecommendations tallies the answers given for each time slot and
+// returns the slots ranked by score. Answers for slots that are not in the
+// list are ignored; participants are looked up by ID for the response.
+//
+// When the event has invitations, a slot that any required attendee answered
+// "no" to is dropped, and the remaining slots are scored by the answers of
+// everyone else. Invitees without an answer for a slot are listed as pending.
+func BuildRecommendations(slots []models.TimeSlot, answers []models.Availability, participants map[uint]models.Participant, invitations []models.EventInvitation) []models.TimeSlotRecommendation {
+	roles := make(map[uint]models.AttendeeRole, len(invitations))
+	for _, invitation := range invitations {
+		role := invitation.Role
+		if role == "" {
+			role = models.AttendeeRequired
+		}
+		roles[invitation.ParticipantID] = role
+	}
+
+	index := make(map[uint]int, len(slots))
+	recommendations := make([]models.TimeSlotRecommendation, len(slots))
+	answered := make([]map[uint]bool, len(slots))
+	dropped := make([]bool, len(slots))
+	for i, slot := range slots {
+		index[slot.ID] = i
+		recommendations[i].TimeSlot = slot
+		answered[i] = make(map[uint]bool)
+	}
+
+	for _, answer := range answers {
+		i, ok := index[answer.TimeSlotID]
+		if !ok {
+			continue
+		}
+		answer.Normalize()
+		role := roles[answer.ParticipantID]
+		if role == models.AttendeeRequired && answer.Status == models.AvailabilityNo {
+			dropped[i] = true
+		}
+		answered[i][answer.ParticipantID] = true
+		addAnswer(&recommendations[i], answer, participants[answer.ParticipantID], role)
+	}
+
+	ranked := make([]models.TimeSlotRecommendation, 0, len(slots))
+	for i := range recommendations {
+		if dropped[i] {
+			continue
+		}
+		for _, invitation := range invitations {
+			if !answered[i][invitation.ParticipantID] {
+				recommendations[i].PendingUsers = append(recommendations[i].PendingUsers, participants[invitation.ParticipantID])
+			}
+		}
+		recommendations[i].Score = math.Round(recommendations[i].Score*1000) / 1000
+		ranked = append(ranked, recommendations[i])
+	}
+
+	Rank(ranked)
+	return ranked
+}
+
+// addAnswer adds a single answer to a recommendation's counts and score
+func addAnswer(rec *models.TimeSlotRecommendation, answer models.Availability, participant models.Participant, role models.AttendeeRole) {
+	contribution := models.ScoreContribution{
+		ParticipantID: answer.ParticipantID,
+		Name:          participant.Name,
+		Role:          role,
+		Status:        answer.Status,
+		Weight:        answer.EffectiveWeight(),
+	}
+
+	switch answer.Status {
+	case models.AvailabilityYes:
+		rec.AvailableCount++
+		rec.AvailableUsers = append(rec.AvailableUsers, participant)
+		contribution.Points = YesPoints * contribution.Weight
+	case models.AvailabilityIfNeedBe:
+		rec.IfNeedBeCount++
+		rec.IfNeedBeUsers = append(rec.IfNeedBeUsers, participant)
+		contribution.Points = IfNeedBePoints * contribution.Weight
+	default:
+		rec.UnavailableCount++
+		rec.UnavailableUsers = append(rec.UnavailableUsers, participant)
+	}
+
+	if role == models.AttendeeRequired {
+		contribution.Points = 0
+	}
+
+	rec.Score += contribution.Points
+	rec.Breakdown = append(rec.Breakdown, contribution)
+}
+
+// Rank sorts recommendations by score, breaking ties by the number of "yes"
+// answers and then by start time so the order is stable between requests
+func Rank(recommendations []models.TimeSlotRecommendation) {
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		a, b := recommendations[i], recommendations[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.AvailableCount != b.AvailableCount {
+			return a.AvailableCount > b.AvailableCount
+		}
+		return a.TimeSlot.StartTime.Before(b.TimeSlot.StartTime)
+	})
+}
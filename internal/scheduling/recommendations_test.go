@@ -0,0 +1,103 @@
+package scheduling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+)
+
+func weight(w float64) *float64 {
+	return &w
+}
+
+func TestBuildRecommendationsRanksByScore(t *testing.T) {
+	start := time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC)
+	slots := []models.TimeSlot{
+		{ID: 1, StartTime: start, EndTime: start.Add(time.Hour)},
+		{ID: 2, StartTime: start.Add(2 * time.Hour), EndTime: start.Add(3 * time.Hour)},
+		{ID: 3, StartTime: start.Add(4 * time.Hour), EndTime: start.Add(5 * time.Hour)},
+	}
+	participants := map[uint]models.Participant{
+		1: {ID: 1, Name: "Ada"},
+		2: {ID: 2, Name: "Grace"},
+	}
+	answers := []models.Availability{
+		{ParticipantID: 1, TimeSlotID: 1, Status: models.AvailabilityIfNeedBe},
+		{ParticipantID: 2, TimeSlotID: 1, Status: models.AvailabilityNo},
+		{ParticipantID: 1, TimeSlotID: 2, Status: models.AvailabilityYes},
+		{ParticipantID: 2, TimeSlotID: 2, Status: models.AvailabilityYes, Weight: weight(0.5)},
+		{ParticipantID: 1, TimeSlotID: 3, IsAvailable: true},
+		{ParticipantID: 2, TimeSlotID: 3, Status: models.AvailabilityIfNeedBe},
+	}
+
+	recs := BuildRecommendations(slots, answers, participants, nil)
+
+	assert.Len(t, recs, 3)
+	assert.Equal(t, uint(2), recs[0].TimeSlot.ID)
+	assert.Equal(t, 1.5, recs[0].Score)
+	assert.Equal(t, 2, recs[0].AvailableCount)
+
+	assert.Equal(t, uint(3), recs[1].TimeSlot.ID)
+	assert.Equal(t, 1.5, recs[1].Score)
+	assert.Equal(t, 1, recs[1].IfNeedBeCount)
+
+	assert.Equal(t, uint(1), recs[2].TimeSlot.ID)
+	assert.Equal(t, 0.5, recs[2].Score)
+	assert.Equal(t, 1, recs[2].UnavailableCount)
+	assert.Len(t, recs[2].Breakdown, 2)
+	assert.Equal(t, "Grace", recs[2].UnavailableUsers[0].Name)
+}
+
+func TestRankBreaksTiesByStartTime(t *testing.T) {
+	start := time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC)
+	recs := []models.TimeSlotRecommendation{
+		{TimeSlot: models.TimeSlot{ID: 1, StartTime: start.Add(time.Hour)}, Score: 1, AvailableCount: 1},
+		{TimeSlot: models.TimeSlot{ID: 2, StartTime: start}, Score: 1, AvailableCount: 1},
+	}
+
+	Rank(recs)
+
+	assert.Equal(t, uint(2), recs[0].TimeSlot.ID)
+}
+
+func TestBuildRecommendationsHonorsRequiredAttendees(t *testing.T) {
+	start := time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC)
+	slots := []models.TimeSlot{
+		{ID: 1, StartTime: start, EndTime: start.Add(time.Hour)},
+		{ID: 2, StartTime: start.Add(2 * time.Hour), EndTime: start.Add(3 * time.Hour)},
+		{ID: 3, StartTime: start.Add(4 * time.Hour), EndTime: start.Add(5 * time.Hour)},
+	}
+	participants := map[uint]models.Participant{
+		1: {ID: 1, Name: "Ada"},
+		2: {ID: 2, Name: "Grace"},
+		3: {ID: 3, Name: "Linus"},
+	}
+	invitations := []models.EventInvitation{
+		{ParticipantID: 1, Role: models.AttendeeRequired},
+		{ParticipantID: 2, Role: models.AttendeeOptional},
+		{ParticipantID: 3, Role: models.AttendeeOptional},
+	}
+	answers := []models.Availability{
+		{ParticipantID: 1, TimeSlotID: 1, Status: models.AvailabilityNo},
+		{ParticipantID: 2, TimeSlotID: 1, Status: models.AvailabilityYes},
+		{ParticipantID: 3, TimeSlotID: 1, Status: models.AvailabilityYes},
+		{ParticipantID: 1, TimeSlotID: 2, Status: models.AvailabilityYes},
+		{ParticipantID: 2, TimeSlotID: 2, Status: models.AvailabilityNo},
+		{ParticipantID: 1, TimeSlotID: 3, Status: models.AvailabilityIfNeedBe},
+		{ParticipantID: 2, TimeSlotID: 3, Status: models.AvailabilityYes},
+	}
+
+	recs := BuildRecommendations(slots, answers, participants, invitations)
+
+	assert.Len(t, recs, 2)
+	assert.Equal(t, uint(3), recs[0].TimeSlot.ID)
+	assert.Equal(t, 1.0, recs[0].Score)
+	assert.Equal(t, []models.Participant{participants[3]}, recs[0].PendingUsers)
+
+	assert.Equal(t, uint(2), recs[1].TimeSlot.ID)
+	assert.Equal(t, 0.0, recs[1].Score)
+	assert.Equal(t, "Grace", recs[1].UnavailableUsers[0].Name)
+	assert.Equal(t, "Linus", recs[1].PendingUsers[0].Name)
+}
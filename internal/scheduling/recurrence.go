@@ -0,0 +1,116 @@
+package scheduling
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tusharsingune/meeting-scheduler/internal/calendar"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+)
+
+// SeriesLocation returns the time zone whose wall clock a recurring event
+// follows, UTC when the event has none
+func SeriesLocation(event models.Event) (*time.Location, error) {
+	loc, err := LoadLocation(event.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q", event.TimeZone)
+	}
+	return loc, nil
+}
+
+// Occurrences returns the occurrences of a confirmed recurring event that
+// overlap the period from from to to, with its exceptions applied. The series
+// starts with the confirmed time slot. Cancelled occurrences are included and
+// marked; moved occurrences are included when either their scheduled or
+// their new time overlaps the period.
+func Occurrences(event models.Event, from, to time.Time) ([]models.Occurrence, error) {
+	if !event.IsRecurring() || event.ConfirmedTimeSlot == nil {
+		return nil, nil
+	}
+	rule, err := calendar.ParseRecurrence(event.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := SeriesLocation(event)
+	if err != nil {
+		return nil, err
+	}
+
+	exceptions := make(map[int64]models.OccurrenceException, len(event.Exceptions))
+	for _, exception := range event.Exceptions {
+		exceptions[exception.OccurrenceStart.Unix()] = exception
+	}
+
+	slot := event.ConfirmedTimeSlot
+	duration := slot.EndTime.Sub(slot.StartTime)
+
+	// Moved occurrences may lie far from their scheduled time, so the series
+	// is expanded over a wider period when any exist
+	expandFrom, expandTo := from.Add(-duration), to
+	for _, exception := range event.Exceptions {
+		if exception.StartTime != nil && exception.StartTime.Before(to) && exception.OccurrenceStart.Before(expandFrom) {
+			expandFrom = exception.OccurrenceStart
+		}
+		if exception.EndTime != nil && exception.EndTime.After(from) && !exception.OccurrenceStart.Before(expandTo) {
+			expandTo = exception.OccurrenceStart.Add(time.Second)
+		}
+	}
+
+	var occurrences []models.Occurrence
+	for _, start := range rule.Between(slot.StartTime.In(loc), expandFrom, expandTo) {
+		occurrence := models.Occurrence{
+			OccurrenceStart: start.UTC(),
+			StartTime:       start.UTC(),
+			EndTime:         start.Add(duration).UTC(),
+		}
+		scheduled := models.TimeSlot{StartTime: occurrence.StartTime, EndTime: occurrence.EndTime}
+
+		if exception, ok := exceptions[start.Unix()]; ok {
+			switch {
+			case exception.Cancelled:
+				occurrence.Cancelled = true
+			case exception.StartTime != nil && exception.EndTime != nil:
+				occurrence.Moved = true
+				occurrence.StartTime = exception.StartTime.UTC()
+				occurrence.EndTime = exception.EndTime.UTC()
+			}
+		}
+
+		current := models.TimeSlot{StartTime: occurrence.StartTime, EndTime: occurrence.EndTime}
+		window := models.TimeSlot{StartTime: from, EndTime: to}
+		if window.Overlaps(current) || window.Overlaps(scheduled) {
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+	return occurrences, nil
+}
+
+// IsOccurrence reports whether a recurring event has an occurrence scheduled
+// to start at the given time
+func IsOccurrence(event models.Event, start time.Time) (bool, error) {
+	occurrences, err := Occurrences(models.Event{
+		Recurrence:        event.Recurrence,
+		TimeZone:          event.TimeZone,
+		ConfirmedTimeSlot: event.ConfirmedTimeSlot,
+	}, start, start.Add(time.Second))
+	if err != nil {
+		return false, err
+	}
+	for _, occurrence := range occurrences {
+		if occurrence.OccurrenceStart.Equal(start) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// LocalizeOccurrences sets the local view of each occurrence for the given location
+func LocalizeOccurrences(occurrences []models.Occurrence, loc *time.Location) {
+	for i := range occurrences {
+		occurrences[i].Local = &models.LocalTimeRange{
+			TimeZone:  loc.String(),
+			StartTime: occurrences[i].StartTime.In(loc),
+			EndTime:   occurrences[i].EndTime.In(loc),
+		}
+	}
+}
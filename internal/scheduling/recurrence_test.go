@@ -0,0 +1,86 @@
+package scheduling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+)
+
+// weeklySeries is confirmed for Tuesdays at 10:00 in Berlin, starting on
+// 26 March 2030, the week before daylight saving time begins
+func weeklySeries() models.Event {
+	slot := models.TimeSlot{
+		ID:        1,
+		StartTime: time.Date(2030, 3, 26, 9, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2030, 3, 26, 10, 0, 0, 0, time.UTC),
+	}
+	event := models.Event{ID: 1, Recurrence: "FREQ=WEEKLY;BYDAY=TU", TimeZone: "Europe/Berlin", TimeSlots: []models.TimeSlot{slot}}
+	event.Confirm(slot, slot.StartTime.Add(-time.Hour))
+	return event
+}
+
+func TestOccurrences(t *testing.T) {
+	event := weeklySeries()
+	moved := time.Date(2030, 4, 10, 14, 0, 0, 0, time.UTC)
+	movedEnd := moved.Add(time.Hour)
+	event.Exceptions = []models.OccurrenceException{
+		{OccurrenceStart: time.Date(2030, 4, 2, 8, 0, 0, 0, time.UTC), Cancelled: true},
+		{OccurrenceStart: time.Date(2030, 4, 9, 8, 0, 0, 0, time.UTC), StartTime: &moved, EndTime: &movedEnd},
+	}
+
+	occurrences, err := Occurrences(event, time.Date(2030, 3, 25, 0, 0, 0, 0, time.UTC), time.Date(2030, 4, 17, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	require.Len(t, occurrences, 4)
+	assert.Equal(t, time.Date(2030, 3, 26, 9, 0, 0, 0, time.UTC), occurrences[0].StartTime)
+
+	// 10:00 in Berlin is 08:00 UTC once daylight saving time has begun
+	assert.Equal(t, time.Date(2030, 4, 2, 8, 0, 0, 0, time.UTC), occurrences[1].StartTime)
+	assert.True(t, occurrences[1].Cancelled)
+
+	assert.True(t, occurrences[2].Moved)
+	assert.Equal(t, time.Date(2030, 4, 9, 8, 0, 0, 0, time.UTC), occurrences[2].OccurrenceStart)
+	assert.Equal(t, moved, occurrences[2].StartTime)
+
+	assert.Equal(t, time.Date(2030, 4, 16, 8, 0, 0, 0, time.UTC), occurrences[3].StartTime)
+}
+
+func TestOccurrencesIncludesOccurrencesMovedIntoPeriod(t *testing.T) {
+	event := weeklySeries()
+	moved := time.Date(2030, 5, 1, 8, 0, 0, 0, time.UTC)
+	movedEnd := moved.Add(time.Hour)
+	event.Exceptions = []models.OccurrenceException{
+		{OccurrenceStart: time.Date(2030, 4, 16, 8, 0, 0, 0, time.UTC), StartTime: &moved, EndTime: &movedEnd},
+	}
+
+	occurrences, err := Occurrences(event, time.Date(2030, 4, 30, 12, 0, 0, 0, time.UTC), time.Date(2030, 5, 2, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	require.Len(t, occurrences, 1)
+	assert.True(t, occurrences[0].Moved)
+	assert.Equal(t, moved, occurrences[0].StartTime)
+}
+
+func TestOccurrencesOfUnconfirmedEvent(t *testing.T) {
+	event := models.Event{Recurrence: "FREQ=DAILY"}
+
+	occurrences, err := Occurrences(event, time.Now(), time.Now().AddDate(0, 1, 0))
+
+	assert.NoError(t, err)
+	assert.Empty(t, occurrences)
+}
+
+func TestIsOccurrence(t *testing.T) {
+	event := weeklySeries()
+
+	ok, err := IsOccurrence(event, time.Date(2030, 4, 2, 8, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = IsOccurrence(event, time.Date(2030, 4, 2, 9, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,109 @@
+package scheduling
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+)
+
+// WorkingHours is the part of a local day in which meetings are acceptable,
+// in minutes after local midnight
+type WorkingHours struct {
+	Start int
+	End   int
+}
+
+// DefaultWorkingHours is 08:00 to 18:00
+var DefaultWorkingHours = WorkingHours{Start: 8 * 60, End: 18 * 60}
+
+// ParseWorkingHours parses a working hours window given as "HH:MM" clock times
+func ParseWorkingHours(start, end string) (WorkingHours, error) {
+	s, err := parseClock(start)
+	if err != nil {
+		return WorkingHours{}, err
+	}
+	e, err := parseClock(end)
+	if err != nil {
+		return WorkingHours{}, err
+	}
+	if e <= s {
+		return WorkingHours{}, fmt.Errorf("working hours end %q must be after start %q", end, start)
+	}
+	return WorkingHours{Start: s, End: e}, nil
+}
+
+// parseClock converts an "HH:MM" clock time to minutes after midnight
+func parseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid clock time %q, expected HH:MM", clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Contains reports whether the period from start to end falls within the
+// working hours of a single day in the given location
+func (wh WorkingHours) Contains(start, end time.Time, loc *time.Location) bool {
+	localStart, localEnd := start.In(loc), end.In(loc)
+	y, m, d := localStart.Date()
+	open := time.Date(y, m, d, wh.Start/60, wh.Start%60, 0, 0, loc)
+	closing := time.Date(y, m, d, wh.End/60, wh.End%60, 0, 0, loc)
+	return !localStart.Before(open) && !localEnd.After(closing)
+}
+
+// LoadLocation resolves an IANA time zone name, treating an empty name as UTC
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(name)
+}
+
+// Localize sets the local view of each time slot for the given location
+func Localize(slots []models.TimeSlot, loc *time.Location) {
+	for i := range slots {
+		LocalizeSlot(&slots[i], loc)
+	}
+}
+
+// LocalizeSlot sets the local view of a time slot for the given location
+func LocalizeSlot(slot *models.TimeSlot, loc *time.Location) {
+	slot.Local = &models.LocalTimeRange{
+		TimeZone:  loc.String(),
+		StartTime: slot.StartTime.In(loc),
+		EndTime:   slot.EndTime.In(loc),
+	}
+}
+
+// FlagOutsideWorkingHours records, for every recommendation, the attendees
+// for whom the slot falls outside their local working hours. Attendees are
+// those who answered yes or if-need-be and invitees who have not answered.
+func FlagOutsideWorkingHours(recommendations []models.TimeSlotRecommendation, hours WorkingHours) {
+	for i := range recommendations {
+		rec := &recommendations[i]
+		rec.OutsideWorkingHours = nil
+
+		var attendees []models.Participant
+		attendees = append(attendees, rec.AvailableUsers...)
+		attendees = append(attendees, rec.IfNeedBeUsers...)
+		attendees = append(attendees, rec.PendingUsers...)
+
+		for _, participant := range attendees {
+			loc, err := LoadLocation(participant.TimeZone)
+			if err != nil {
+				loc = time.UTC
+			}
+			if hours.Contains(rec.TimeSlot.StartTime, rec.TimeSlot.EndTime, loc) {
+				continue
+			}
+			rec.OutsideWorkingHours = append(rec.OutsideWorkingHours, models.WorkingHoursConflict{
+				ParticipantID: participant.ID,
+				Name:          participant.Name,
+				TimeZone:      loc.String(),
+				StartTime:     rec.TimeSlot.StartTime.In(loc),
+				EndTime:       rec.TimeSlot.EndTime.In(loc),
+			})
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package scheduling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWorkingHours(t *testing.T) {
+	hours, err := ParseWorkingHours("09:30", "17:00")
+	assert.NoError(t, err)
+	assert.Equal(t, WorkingHours{Start: 570, End: 1020}, hours)
+
+	_, err = ParseWorkingHours("18:00", "08:00")
+	assert.Error(t, err)
+
+	_, err = ParseWorkingHours("8am", "18:00")
+	assert.Error(t, err)
+}
+
+func TestWorkingHoursContains(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		start time.Time
+		want  bool
+	}{
+		{"morning", time.Date(2030, 1, 7, 8, 0, 0, 0, time.UTC), true},
+		{"ends at closing", time.Date(2030, 1, 7, 16, 0, 0, 0, time.UTC), true},
+		{"ends after closing", time.Date(2030, 1, 7, 16, 30, 0, 0, time.UTC), false},
+		{"before opening", time.Date(2030, 1, 7, 6, 30, 0, 0, time.UTC), false},
+		{"summer time", time.Date(2030, 7, 8, 6, 0, 0, 0, time.UTC), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DefaultWorkingHours.Contains(tt.start, tt.start.Add(time.Hour), berlin)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
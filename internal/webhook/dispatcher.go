@@ -0,0 +1,183 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"go.uber.org/zap"
+)
+
+// Store is the part of the repository the Dispatcher works with
+type Store interface {
+	DispatchOutboxEvents(ctx context.Context, limit int) (int, error)
+	ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error)
+	UpdateWebhookDelivery(context.Context, *models.WebhookDelivery) error
+}
+
+// Dispatcher delivers outbox events to webhooks, retrying failed deliveries
+// with exponential backoff
+type Dispatcher struct {
+	Store       Store
+	Client      *http.Client
+	Log         *zap.Logger
+	Interval    time.Duration // how often to look for work
+	BatchSize   int           // events and deliveries handled per round
+	Lease       time.Duration // how long a claimed delivery is hidden from other servers
+	MaxAttempts int           // attempts before a delivery is given up
+	BaseDelay   time.Duration // wait after the first failed attempt, doubled after every further one
+	MaxDelay    time.Duration // longest wait between attempts
+	Now         func() time.Time
+}
+
+// NewDispatcher creates a Dispatcher with the default schedule: up to 10
+// attempts over about eight hours, delivering only to public addresses
+func NewDispatcher(store Store, log *zap.Logger) *Dispatcher {
+	return &Dispatcher{
+		Store:       store,
+		Client:      NewClient(10 * time.Second),
+		Log:         log,
+		Interval:    5 * time.Second,
+		BatchSize:   20,
+		Lease:       time.Minute,
+		MaxAttempts: 10,
+		BaseDelay:   30 * time.Second,
+		MaxDelay:    6 * time.Hour,
+		Now:         time.Now,
+	}
+}
+
+// Run delivers webhooks until ctx is done
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+	for {
+		if err := d.RunOnce(ctx); err != nil {
+			d.Log.Error("Failed to dispatch webhooks", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce turns pending outbox events into deliveries and makes one attempt
+// at every delivery that is due
+func (d *Dispatcher) RunOnce(ctx context.Context) error {
+	if _, err := d.Store.DispatchOutboxEvents(ctx, d.BatchSize); err != nil {
+		return err
+	}
+
+	deliveries, err := d.Store.ClaimWebhookDeliveries(ctx, d.Now(), d.Lease, d.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		wg.Add(1)
+		go func(delivery *models.WebhookDelivery) {
+			defer wg.Done()
+			d.deliver(ctx, delivery)
+		}(&deliveries[i])
+	}
+	wg.Wait()
+	return nil
+}
+
+// Backoff returns how long to wait after the given number of failed attempts
+func (d *Dispatcher) Backoff(attempts int) time.Duration {
+	delay := d.BaseDelay
+	for i := 1; i < attempts && delay < d.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > d.MaxDelay {
+		delay = d.MaxDelay
+	}
+	return delay
+}
+
+// deliver makes one attempt at a delivery and records its outcome
+func (d *Dispatcher) deliver(ctx context.Context, delivery *models.WebhookDelivery) {
+	now := d.Now()
+	var status int
+	var err error
+	if delivery.Webhook == nil {
+		err = errors.New("webhook was deleted")
+	} else {
+		status, err = d.post(ctx, delivery, now)
+	}
+
+	// An attempt cut short by shutting down is not the endpoint's fault; the
+	// delivery is retried once its lease ends
+	if ctx.Err() != nil {
+		return
+	}
+
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = status
+	switch {
+	case err == nil:
+		delivery.Status = models.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.Error = ""
+	case delivery.Webhook == nil || delivery.Attempts >= d.MaxAttempts:
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.Error = err.Error()
+	default:
+		delivery.Status = models.WebhookDeliveryPending
+		delivery.NextAttemptAt = now.Add(d.Backoff(delivery.Attempts))
+		delivery.Error = err.Error()
+	}
+
+	if err != nil {
+		d.Log.Warn("Webhook delivery failed",
+			zap.Error(err),
+			zap.Uint("delivery_id", delivery.ID),
+			zap.Int("attempts", delivery.Attempts),
+			zap.String("status", string(delivery.Status)))
+	}
+	if err := d.Store.UpdateWebhookDelivery(ctx, delivery); err != nil {
+		d.Log.Error("Failed to record webhook delivery", zap.Error(err), zap.Uint("delivery_id", delivery.ID))
+	}
+}
+
+// post sends a delivery's payload to its webhook and returns the response status
+func (d *Dispatcher) post(ctx context.Context, delivery *models.WebhookDelivery, now time.Time) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "meeting-scheduler-webhooks")
+	req.Header.Set(SignatureHeader, Sign(delivery.Webhook.Secret, now, body))
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		// A refused redirect comes with the response that asked for it
+		if resp != nil {
+			return resp.StatusCode, err
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,117 @@
+// Package webhook publishes changes to events to the webhooks their
+// organizers register. Changes are written to an outbox in the same
+// transaction as the change, and a Dispatcher delivers them from there.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+)
+
+// Types of change payloads are sent for
+const (
+	EventCreated          = "event.created"
+	EventUpdated          = "event.updated"
+	EventDeleted          = "event.deleted"
+	EventClosed           = "event.closed"
+	EventConfirmed        = "event.confirmed"
+	EventCancelled        = "event.cancelled"
+	AvailabilitySubmitted = "availability.submitted"
+)
+
+// Headers sent with every payload
+const (
+	SignatureHeader = "X-Webhook-Signature" // see Sign
+	EventHeader     = "X-Webhook-Event"     // the payload's type
+	DeliveryHeader  = "X-Webhook-Delivery"  // the delivery's ID, the same on every retry
+)
+
+// Payload is the JSON body POSTed to webhooks
+type Payload struct {
+	ID        string      `json:"id"` // identifies the change; receivers can use it to skip duplicates
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"` // the event, or an Availability
+}
+
+// Availability is the data of availability.submitted payloads
+type Availability struct {
+	EventID        uint                  `json:"event_id"`
+	ParticipantID  uint                  `json:"participant_id"`
+	Availabilities []models.Availability `json:"availabilities"`
+}
+
+// NewOutboxEvent builds the outbox entry for a change to an event organized
+// by organizerID
+func NewOutboxEvent(eventType string, organizerID uint, data interface{}, now time.Time) (*models.OutboxEvent, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(Payload{
+		ID:        hex.EncodeToString(id),
+		Type:      eventType,
+		CreatedAt: now.UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.OutboxEvent{Type: eventType, OrganizerID: organizerID, Payload: string(body)}, nil
+}
+
+// ErrInvalidSignature is returned by Verify for bodies the signature does not match
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Sign returns the signature header value for a body sent at the given time,
+// "t=<unix seconds>,v1=<hex>", where the hex part is the HMAC-SHA256 of
+// "<unix seconds>.<body>" keyed with the webhook's secret. Signing the time
+// lets receivers reject replayed payloads.
+func Sign(secret string, at time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac(secret, timestamp, body))
+}
+
+// Verify checks a signature header value against a body and that it was
+// signed no longer than tolerance before now
+func Verify(secret, signature string, body []byte, now time.Time, tolerance time.Duration) error {
+	var timestamp, sum string
+	for _, part := range strings.Split(signature, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			sum = value
+		}
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	expected, err := hex.DecodeString(sum)
+	if err != nil || !hmac.Equal(expected, mac(secret, timestamp, body)) {
+		return ErrInvalidSignature
+	}
+	if now.Sub(time.Unix(seconds, 0)) > tolerance {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func mac(secret, timestamp string, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(timestamp + "."))
+	h.Write(body)
+	return h.Sum(nil)
+}
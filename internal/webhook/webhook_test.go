@@ -0,0 +1,228 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tusharsingune/meeting-scheduler/internal/models"
+	"go.uber.org/zap"
+)
+
+// memoryStore hands out a fixed set of deliveries and records updates
+type memoryStore struct {
+	mu         sync.Mutex
+	deliveries []models.WebhookDelivery
+	updated    []models.WebhookDelivery
+}
+
+func (s *memoryStore) DispatchOutboxEvents(ctx context.Context, limit int) (int, error) {
+	return 0, nil
+}
+
+func (s *memoryStore) ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	claimed := s.deliveries
+	s.deliveries = nil
+	return claimed, nil
+}
+
+func (s *memoryStore) UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updated = append(s.updated, *delivery)
+	return nil
+}
+
+func testDispatcher(store Store, now time.Time) *Dispatcher {
+	d := NewDispatcher(store, zap.NewNop())
+	d.Client = &http.Client{Timeout: 10 * time.Second} // test servers listen on loopback
+	d.MaxAttempts = 3
+	d.Now = func() time.Time { return now }
+	return d
+}
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Date(2030, 6, 3, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"type":"event.created"}`)
+	signature := Sign("0123456789abcdef", now, body)
+
+	assert.NoError(t, Verify("0123456789abcdef", signature, body, now.Add(time.Minute), 5*time.Minute))
+	assert.ErrorIs(t, Verify("another secret!!", signature, body, now, 5*time.Minute), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("0123456789abcdef", signature, []byte(`{}`), now, 5*time.Minute), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("0123456789abcdef", signature, body, now.Add(time.Hour), 5*time.Minute), ErrInvalidSignature, "old signatures are replays")
+	assert.ErrorIs(t, Verify("0123456789abcdef", "garbage", body, now, 5*time.Minute), ErrInvalidSignature)
+}
+
+func TestNewOutboxEvent(t *testing.T) {
+	event, err := NewOutboxEvent(EventConfirmed, 1, models.Event{ID: 7, Title: "Planning"}, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, EventConfirmed, event.Type)
+	assert.Equal(t, uint(1), event.OrganizerID)
+
+	var payload struct {
+		ID   string
+		Type string
+		Data models.Event
+	}
+	require.NoError(t, json.Unmarshal([]byte(event.Payload), &payload))
+	assert.Len(t, payload.ID, 32)
+	assert.Equal(t, EventConfirmed, payload.Type)
+	assert.Equal(t, "Planning", payload.Data.Title)
+}
+
+func TestBackoff(t *testing.T) {
+	d := NewDispatcher(nil, zap.NewNop())
+
+	assert.Equal(t, 30*time.Second, d.Backoff(1))
+	assert.Equal(t, time.Minute, d.Backoff(2))
+	assert.Equal(t, 4*time.Minute, d.Backoff(4))
+	assert.Equal(t, 6*time.Hour, d.Backoff(20))
+}
+
+func TestDispatcherDelivers(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	store := &memoryStore{deliveries: []models.WebhookDelivery{{
+		ID:        4,
+		EventType: EventCreated,
+		Payload:   `{"type":"event.created"}`,
+		Webhook:   &models.Webhook{ID: 2, URL: server.URL, Secret: "0123456789abcdef"},
+	}}}
+
+	require.NoError(t, testDispatcher(store, now).RunOnce(context.Background()))
+
+	require.NotNil(t, received)
+	assert.Equal(t, `{"type":"event.created"}`, string(body))
+	assert.Equal(t, EventCreated, received.Header.Get(EventHeader))
+	assert.Equal(t, "4", received.Header.Get(DeliveryHeader))
+	assert.NoError(t, Verify("0123456789abcdef", received.Header.Get(SignatureHeader), body, now, time.Minute))
+
+	require.Len(t, store.updated, 1)
+	assert.Equal(t, models.WebhookDeliveryDelivered, store.updated[0].Status)
+	assert.Equal(t, 1, store.updated[0].Attempts)
+	assert.Equal(t, http.StatusNoContent, store.updated[0].ResponseStatus)
+	assert.NotNil(t, store.updated[0].DeliveredAt)
+}
+
+func TestDispatcherRetriesFailures(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		attempts   int
+		wantStatus models.WebhookDeliveryStatus
+	}{
+		{"first failure is retried", 0, models.WebhookDeliveryPending},
+		{"last attempt gives up", 2, models.WebhookDeliveryFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &memoryStore{deliveries: []models.WebhookDelivery{{
+				ID:       4,
+				Attempts: tt.attempts,
+				Status:   models.WebhookDeliveryPending,
+				Payload:  `{}`,
+				Webhook:  &models.Webhook{URL: server.URL, Secret: "0123456789abcdef"},
+			}}}
+			d := testDispatcher(store, now)
+
+			require.NoError(t, d.RunOnce(context.Background()))
+
+			require.Len(t, store.updated, 1)
+			delivery := store.updated[0]
+			assert.Equal(t, tt.wantStatus, delivery.Status)
+			assert.Equal(t, tt.attempts+1, delivery.Attempts)
+			assert.Equal(t, http.StatusInternalServerError, delivery.ResponseStatus)
+			assert.Contains(t, delivery.Error, "500")
+			if tt.wantStatus == models.WebhookDeliveryPending {
+				assert.Equal(t, now.Add(d.Backoff(1)), delivery.NextAttemptAt)
+			}
+		})
+	}
+}
+
+func TestDispatcherFailsDeletedWebhooks(t *testing.T) {
+	store := &memoryStore{deliveries: []models.WebhookDelivery{{ID: 4, Payload: `{}`}}}
+
+	require.NoError(t, testDispatcher(store, time.Now()).RunOnce(context.Background()))
+
+	require.Len(t, store.updated, 1)
+	assert.Equal(t, models.WebhookDeliveryFailed, store.updated[0].Status)
+	assert.Equal(t, 1, store.updated[0].Attempts)
+}
+
+func TestClientRefusesPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	_, err := NewClient(time.Second).Post(server.URL, "application/json", nil)
+	assert.ErrorContains(t, err, "non-public address 127.0.0.1")
+}
+
+func TestClientRefusesRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest", http.StatusFound)
+	}))
+	defer server.Close()
+	client := NewClient(time.Second)
+	client.Transport = http.DefaultTransport // test servers listen on loopback
+
+	resp, err := client.Post(server.URL, "application/json", nil)
+	assert.ErrorIs(t, err, ErrRedirect)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+}
+
+func TestPublicAddress(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"93.184.216.34":      true,
+		"2606:4700::1111":    true,
+		"127.0.0.1":          false,
+		"10.1.2.3":           false,
+		"172.16.0.1":         false,
+		"192.168.1.1":        false,
+		"169.254.169.254":    false,
+		"0.0.0.0":            false,
+		"::1":                false,
+		"fe80::1":            false,
+		"fd00::1":            false,
+		"::ffff:127.0.0.1":   false,
+		"0.1.2.3":            false,
+		"100.64.0.1":         false,
+		"100.127.255.254":    false,
+		"100.128.0.1":        true,
+		"192.0.0.8":          false,
+		"198.18.0.1":         false,
+		"198.19.255.255":     false,
+		"198.20.0.1":         true,
+		"64:ff9b::a9fe:a9fe": false,
+		"::ffff:100.64.0.1":  false,
+	} {
+		assert.Equal(t, want, PublicAddress(netip.MustParseAddr(addr)), addr)
+	}
+}